@@ -0,0 +1,177 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"multi-inventory/internal/domain"
+	"multi-inventory/internal/infrastructure/storage"
+)
+
+// envOr returns the environment variable or the fallback when it is unset.
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// loadStore reads the store details printed on receipts.
+func loadStore() domain.Store {
+	return domain.Store{
+		Code:    envOr("STORE_CODE", "MAIN"),
+		Name:    envOr("STORE_NAME", "Multi Inventory"),
+		Address: os.Getenv("STORE_ADDRESS"),
+		Phone:   os.Getenv("STORE_PHONE"),
+		TaxID:   os.Getenv("STORE_TAX_ID"),
+	}
+}
+
+// loadTimeZone reads the IANA time zone that reports use for days, weeks and
+// months, such as "Asia/Jakarta".
+func loadTimeZone() (*time.Location, error) {
+	name := envOr("REPORT_TIMEZONE", "UTC")
+	if name == "Local" {
+		return nil, fmt.Errorf("invalid REPORT_TIMEZONE: name a zone such as Asia/Jakarta")
+	}
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPORT_TIMEZONE: %w", err)
+	}
+	return zone, nil
+}
+
+// loadBarcodeGenerator reads the GS1 prefix range used for internal barcodes.
+func loadBarcodeGenerator() (*domain.BarcodeGenerator, error) {
+	return domain.NewBarcodeGenerator(envOr("INTERNAL_BARCODE_PREFIXES", domain.DefaultBarcodePrefixes))
+}
+
+// loadAdjustmentPolicy reads the reason codes and approval thresholds for
+// stock adjustments. A zero threshold disables that check.
+func loadAdjustmentPolicy() (domain.AdjustmentPolicy, error) {
+	policy := domain.AdjustmentPolicy{Reasons: domain.DefaultAdjustmentReasons}
+	if v := os.Getenv("ADJUSTMENT_REASONS"); v != "" {
+		policy.Reasons = nil
+		for _, reason := range strings.Split(v, ",") {
+			if reason = strings.TrimSpace(reason); reason != "" {
+				policy.Reasons = append(policy.Reasons, reason)
+			}
+		}
+	}
+	var err error
+	if policy.ApprovalQuantity, err = strconv.Atoi(envOr("ADJUSTMENT_APPROVAL_QUANTITY", "0")); err != nil {
+		return policy, fmt.Errorf("invalid ADJUSTMENT_APPROVAL_QUANTITY: %w", err)
+	}
+	if policy.ApprovalValue, err = strconv.ParseFloat(envOr("ADJUSTMENT_APPROVAL_VALUE", "0"), 64); err != nil {
+		return policy, fmt.Errorf("invalid ADJUSTMENT_APPROVAL_VALUE: %w", err)
+	}
+	return policy, nil
+}
+
+// loadJWTConfig reads the token signing secret and lifetime. Without a
+// secret a random one is generated, so tokens do not survive a restart.
+func loadJWTConfig() ([]byte, time.Duration, error) {
+	ttl, err := time.ParseDuration(envOr("JWT_TTL", "24h"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid JWT_TTL: %w", err)
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret), ttl, nil
+	}
+	log.Println("JWT_SECRET is not set; using a random secret, tokens will be invalidated on restart")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, 0, err
+	}
+	return secret, ttl, nil
+}
+
+// loadLowStockThreshold reads the quantity at or below which an item counts
+// as low on stock.
+func loadLowStockThreshold() (int, error) {
+	n, err := strconv.Atoi(envOr("LOW_STOCK_THRESHOLD", "5"))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid LOW_STOCK_THRESHOLD %q", os.Getenv("LOW_STOCK_THRESHOLD"))
+	}
+	return n, nil
+}
+
+// loadValuationMethod reads how stock leaving inventory is costed. Each
+// deployment serves one store, so the method is set per store.
+func loadValuationMethod() (domain.ValuationMethod, error) {
+	return domain.ParseValuationMethod(os.Getenv("VALUATION_METHOD"))
+}
+
+// loadPriceSchedulerInterval reads how often scheduled prices are checked.
+func loadPriceSchedulerInterval() (time.Duration, error) {
+	interval, err := time.ParseDuration(envOr("PRICE_SCHEDULER_INTERVAL", "1m"))
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid PRICE_SCHEDULER_INTERVAL %q", os.Getenv("PRICE_SCHEDULER_INTERVAL"))
+	}
+	return interval, nil
+}
+
+// loadWebhookConfig reads how often the webhook dispatcher runs and how
+// many attempts a delivery gets before it is dead.
+func loadWebhookConfig() (time.Duration, int, error) {
+	interval, err := time.ParseDuration(envOr("WEBHOOK_DISPATCH_INTERVAL", "5s"))
+	if err != nil || interval <= 0 {
+		return 0, 0, fmt.Errorf("invalid WEBHOOK_DISPATCH_INTERVAL %q", os.Getenv("WEBHOOK_DISPATCH_INTERVAL"))
+	}
+	attempts, err := strconv.Atoi(envOr("WEBHOOK_MAX_ATTEMPTS", "8"))
+	if err != nil || attempts < 1 {
+		return 0, 0, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %q", os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	}
+	return interval, attempts, nil
+}
+
+// loadWebhookRetention reads how long dispatched events and finished
+// deliveries with their log are kept.
+func loadWebhookRetention() (time.Duration, error) {
+	retention, err := time.ParseDuration(envOr("WEBHOOK_RETENTION", "720h"))
+	if err != nil || retention <= 0 {
+		return 0, fmt.Errorf("invalid WEBHOOK_RETENTION %q", os.Getenv("WEBHOOK_RETENTION"))
+	}
+	return retention, nil
+}
+
+// loadIdempotencyTTL reads how long responses to requests with an
+// Idempotency-Key are kept for replays.
+func loadIdempotencyTTL() (time.Duration, error) {
+	ttl, err := time.ParseDuration(envOr("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL %q", os.Getenv("IDEMPOTENCY_KEY_TTL"))
+	}
+	return ttl, nil
+}
+
+// loadBlobStore reads where attachments are stored: STORAGE_BACKEND=local
+// keeps them under STORAGE_DIR, s3 in an S3-compatible bucket. Path-style
+// addressing is the default with a custom S3_ENDPOINT, as MinIO expects.
+func loadBlobStore() (domain.BlobStore, error) {
+	switch backend := envOr("STORAGE_BACKEND", "local"); backend {
+	case "local":
+		return storage.NewLocalStore(envOr("STORAGE_DIR", "data/attachments"))
+	case "s3":
+		endpoint := os.Getenv("S3_ENDPOINT")
+		pathStyle, err := strconv.ParseBool(envOr("S3_PATH_STYLE", strconv.FormatBool(endpoint != "")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3_PATH_STYLE: %w", err)
+		}
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  endpoint,
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: pathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q, use local or s3", backend)
+	}
+}
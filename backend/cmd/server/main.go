@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"time"
+	_ "time/tzdata" // The runtime image has no zoneinfo for REPORT_TIMEZONE
 
 	"multi-inventory/internal/application"
 	httpHandler "multi-inventory/internal/infrastructure/http"
+	"multi-inventory/internal/infrastructure/imaging"
 	"multi-inventory/internal/infrastructure/postgres"
+	"multi-inventory/internal/infrastructure/webhook"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -21,13 +24,17 @@ func main() {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	port := envOr("PORT", "8080")
+
+	// Dates are turned into days in Go (query parameters, the dashboard)
+	// and in SQL (report buckets); both must use the same zone.
+	zone, err := loadTimeZone()
+	if err != nil {
+		log.Fatalf("Invalid time zone configuration: %v", err)
 	}
 
 	// Connect to Database
-	db, err := postgres.NewDB()
+	db, err := postgres.NewDB(zone.String())
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -43,36 +50,140 @@ func main() {
 	userRepo := postgres.NewUserRepository(db)
 	itemRepo := postgres.NewItemRepository(db)
 	orderRepo := postgres.NewOrderRepository(db)
+	reportRepo := postgres.NewReportRepository(db)
+	movementRepo := postgres.NewMovementRepository(db)
+	adjustmentRepo := postgres.NewAdjustmentRepository(db)
+	costLayerRepo := postgres.NewCostLayerRepository(db)
+	priceRepo := postgres.NewPriceRepository(db)
+	categoryRepo := postgres.NewCategoryRepository(db)
+	brandRepo := postgres.NewBrandRepository(db)
+	tagRepo := postgres.NewTagRepository(db)
+	productRepo := postgres.NewProductRepository(db)
+	halalDocRepo := postgres.NewHalalDocumentRepository(db)
+	attachmentRepo := postgres.NewAttachmentRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	webhookRepo := postgres.NewWebhookRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+	syncRepo := postgres.NewSyncRepository(db)
+	eventHub := application.NewEventHub()
+	// Services record their changes in the outbox for webhooks and publish
+	// them to the hub once they commit.
+	txManager := application.WithEvents(postgres.NewTxManager(db), eventHub, outboxRepo)
+
+	barcodes, err := loadBarcodeGenerator()
+	if err != nil {
+		log.Fatalf("Invalid barcode configuration: %v", err)
+	}
+	adjustmentPolicy, err := loadAdjustmentPolicy()
+	if err != nil {
+		log.Fatalf("Invalid adjustment configuration: %v", err)
+	}
+	lowStockThreshold, err := loadLowStockThreshold()
+	if err != nil {
+		log.Fatalf("Invalid report configuration: %v", err)
+	}
+	valuationMethod, err := loadValuationMethod()
+	if err != nil {
+		log.Fatalf("Invalid valuation configuration: %v", err)
+	}
+	priceInterval, err := loadPriceSchedulerInterval()
+	if err != nil {
+		log.Fatalf("Invalid price scheduler configuration: %v", err)
+	}
+	blobs, err := loadBlobStore()
+	if err != nil {
+		log.Fatalf("Invalid storage configuration: %v", err)
+	}
+	webhookInterval, webhookAttempts, err := loadWebhookConfig()
+	if err != nil {
+		log.Fatalf("Invalid webhook configuration: %v", err)
+	}
+	webhookRetention, err := loadWebhookRetention()
+	if err != nil {
+		log.Fatalf("Invalid webhook configuration: %v", err)
+	}
+	idempotencyTTL, err := loadIdempotencyTTL()
+	if err != nil {
+		log.Fatalf("Invalid idempotency configuration: %v", err)
+	}
+	jwtSecret, jwtTTL, err := loadJWTConfig()
+	if err != nil {
+		log.Fatalf("Invalid auth configuration: %v", err)
+	}
 
+	ledger := application.NewStockLedger(itemRepo, movementRepo, costLayerRepo, valuationMethod)
 	authService := application.NewAuthService(userRepo)
-	inventoryService := application.NewInventoryService(itemRepo)
-	salesService := application.NewSalesService(orderRepo, itemRepo)
+	inventoryService := application.NewInventoryService(txManager, itemRepo, priceRepo, categoryRepo, brandRepo, productRepo, halalDocRepo, ledger, barcodes)
+	attachmentService := application.NewAttachmentService(txManager, itemRepo, attachmentRepo, blobs, imaging.NewThumbnailer())
+	catalogService := application.NewCatalogService(categoryRepo, brandRepo, tagRepo)
+	salesService := application.NewSalesService(txManager, orderRepo, itemRepo, ledger, loadStore())
+	adjustmentService := application.NewAdjustmentService(txManager, itemRepo, ledger, adjustmentRepo, adjustmentPolicy)
+	exportService := application.NewExportService(itemRepo, orderRepo, movementRepo)
+	reportService := application.NewReportService(reportRepo, lowStockThreshold, valuationMethod, zone)
+	idempotencyService := application.NewIdempotencyService(idempotencyRepo, idempotencyTTL)
+	webhookService := application.NewWebhookService(txManager, webhookRepo, outboxRepo, webhook.NewSender(), webhookAttempts)
+	batchService := application.NewBatchService(txManager, inventoryService, salesService)
+	syncService := application.NewSyncService(txManager, syncRepo, itemRepo, orderRepo, movementRepo, salesService, adjustmentService, idempotencyService)
+
+	go inventoryService.RunPriceScheduler(context.Background(), priceInterval)
+	go webhookService.RunDispatcher(context.Background(), webhookInterval)
+	go webhookService.RunCleanup(context.Background(), time.Hour, webhookRetention)
+	go idempotencyService.RunCleanup(context.Background(), time.Hour)
 
-	authHandler := httpHandler.NewAuthHandler(authService)
+	authenticator := httpHandler.NewAuthenticator(jwtSecret, jwtTTL, userRepo)
+	authHandler := httpHandler.NewAuthHandler(authService, authenticator)
+	idempotency := httpHandler.NewIdempotency(idempotencyService)
 	inventoryHandler := httpHandler.NewInventoryHandler(inventoryService)
-	salesHandler := httpHandler.NewSalesHandler(salesService)
+	attachmentHandler := httpHandler.NewAttachmentHandler(attachmentService)
+	catalogHandler := httpHandler.NewCatalogHandler(catalogService)
+	salesHandler := httpHandler.NewSalesHandler(salesService, idempotency)
+	adjustmentHandler := httpHandler.NewAdjustmentHandler(adjustmentService, idempotency)
+	exportHandler := httpHandler.NewExportHandler(exportService, zone)
+	reportHandler := httpHandler.NewReportHandler(reportService, zone)
+	eventHandler := httpHandler.NewEventHandler(eventHub)
+	webhookHandler := httpHandler.NewWebhookHandler(webhookService)
+	syncHandler := httpHandler.NewSyncHandler(syncService)
+	batchHandler := httpHandler.NewBatchHandler(batchService)
 
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
+	r.Use(middleware.RequestID)
+	r.Use(httpHandler.Logger)
 	r.Use(middleware.Recoverer)
 
 	// Basic CORS
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-Id", "If-Match", "X-Change-Reason", "Last-Event-ID", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "X-Request-Id", "ETag", "Content-Disposition", "Location", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
+	r.Use(authenticator.Middleware)
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Multi Inventory API is running!"))
 	})
 
 	r.Mount("/api/auth", authHandler.Routes())
-	r.Mount("/api/inventory", inventoryHandler.Routes())
+	inventoryRoutes := inventoryHandler.Routes()
+	adjustmentHandler.RegisterItemRoutes(inventoryRoutes)
+	attachmentHandler.RegisterItemRoutes(inventoryRoutes)
+	r.Mount("/api/inventory", inventoryRoutes)
+	r.Mount("/api/attachments", attachmentHandler.Routes())
+	r.Mount("/api/products", inventoryHandler.ProductRoutes())
+	r.Mount("/api/categories", catalogHandler.CategoryRoutes())
+	r.Mount("/api/brands", catalogHandler.BrandRoutes())
+	r.Mount("/api/tags", catalogHandler.TagRoutes())
 	r.Mount("/api/sales", salesHandler.Routes())
+	r.Mount("/api/adjustments", adjustmentHandler.Routes())
+	r.Mount("/api/exports", exportHandler.Routes())
+	r.Mount("/api/dashboard", reportHandler.DashboardRoutes())
+	r.Mount("/api/reports", reportHandler.Routes())
+	r.With(authenticator.QueryToken).Mount("/api/events", eventHandler.Routes())
+	r.Mount("/api/webhooks", webhookHandler.Routes())
+	r.Mount("/api/sync", syncHandler.Routes())
+	r.Mount("/api/batch", batchHandler.Routes())
 
 	fmt.Printf("Server starting on port %s...\n", port)
 	if err := http.ListenAndServe(":"+port, r); err != nil {
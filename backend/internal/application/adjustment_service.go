@@ -0,0 +1,205 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+	"strings"
+)
+
+// AdjustmentService handles manual stock adjustments. Adjustments within the
+// policy thresholds are applied immediately; larger ones wait for a manager.
+type AdjustmentService struct {
+	tx             domain.Transactor
+	itemRepo       domain.ItemRepository
+	ledger         *StockLedger
+	adjustmentRepo domain.AdjustmentRepository
+	policy         domain.AdjustmentPolicy
+}
+
+func NewAdjustmentService(tx domain.Transactor, itemRepo domain.ItemRepository, ledger *StockLedger, adjustmentRepo domain.AdjustmentRepository, policy domain.AdjustmentPolicy) *AdjustmentService {
+	return &AdjustmentService{
+		tx:             tx,
+		itemRepo:       itemRepo,
+		ledger:         ledger,
+		adjustmentRepo: adjustmentRepo,
+		policy:         policy,
+	}
+}
+
+func (s *AdjustmentService) Policy() domain.AdjustmentPolicy {
+	return s.policy
+}
+
+// RequestAdjustment records an adjustment by actor. It is applied at once
+// unless it exceeds the approval thresholds, in which case it is queued as
+// pending and the stock stays unchanged.
+func (s *AdjustmentService) RequestAdjustment(ctx context.Context, actor *domain.User, adj *domain.StockAdjustment) error {
+	adj.Reason = strings.TrimSpace(adj.Reason)
+	if err := s.policy.Validate(adj); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := s.itemRepo.GetByID(ctx, adj.ItemID)
+		if err != nil {
+			return err
+		}
+		if adj.Delta, err = item.ToBase("delta", adj.Delta, ""); err != nil {
+			return err
+		}
+		adj.Value = domain.AdjustmentValue(adj.Delta, item.Price)
+		adj.RequestedBy = actor.ID
+
+		if s.policy.NeedsApproval(adj.Delta, adj.Value) {
+			adj.Status = domain.AdjustmentPending
+			return s.adjustmentRepo.Create(ctx, adj)
+		}
+
+		adj.Status = domain.AdjustmentApplied
+		if err := s.adjustmentRepo.Create(ctx, adj); err != nil {
+			return err
+		}
+		return s.apply(ctx, adj, actor)
+	})
+}
+
+// ApproveAdjustment applies a pending adjustment. Stock is checked again at
+// this point, so an approval can fail if the goods have since been sold.
+func (s *AdjustmentService) ApproveAdjustment(ctx context.Context, actor *domain.User, id int64, note string) (*domain.StockAdjustment, error) {
+	return s.review(ctx, actor, id, note, domain.AdjustmentApplied)
+}
+
+func (s *AdjustmentService) RejectAdjustment(ctx context.Context, actor *domain.User, id int64, note string) (*domain.StockAdjustment, error) {
+	return s.review(ctx, actor, id, note, domain.AdjustmentRejected)
+}
+
+func (s *AdjustmentService) review(ctx context.Context, actor *domain.User, id int64, note string, status domain.AdjustmentStatus) (*domain.StockAdjustment, error) {
+	if !actor.HasRole(domain.RoleManager, domain.RoleAdmin) {
+		return nil, domain.NewForbidden("only managers can review adjustments")
+	}
+
+	var adj *domain.StockAdjustment
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		adj, err = s.adjustmentRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		adj.Status = status
+		adj.ReviewedBy = actor.ID
+		adj.ReviewNote = note
+		if err := s.adjustmentRepo.Review(ctx, adj); err != nil {
+			return err
+		}
+		if status == domain.AdjustmentApplied {
+			return s.apply(ctx, adj, actor)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adj, nil
+}
+
+func (s *AdjustmentService) apply(ctx context.Context, adj *domain.StockAdjustment, actor *domain.User) error {
+	note := adj.Reason
+	if adj.Note != "" {
+		note += ": " + adj.Note
+	}
+	_, err := s.ledger.apply(ctx, &domain.StockMovement{
+		ItemID:    adj.ItemID,
+		Type:      domain.MovementAdjustment,
+		Delta:     adj.Delta,
+		Location:  adj.Location,
+		Reference: fmt.Sprintf("adjustment:%d", adj.ID),
+		UserID:    actor.ID,
+		Note:      note,
+	})
+	return err
+}
+
+// ReceiveGoods books stock received from a supplier at its purchase cost.
+// Receipts need no approval; the cost feeds the item's valuation. The item
+// is given by ID, by the receipt's scanned barcode or both, in which case
+// they must agree. The quantity and cost are converted to the base unit.
+func (s *AdjustmentService) ReceiveGoods(ctx context.Context, actor *domain.User, itemID int64, receipt *domain.GoodsReceipt) (*domain.StockMovement, error) {
+	receipt.Reference = strings.TrimSpace(receipt.Reference)
+	receipt.Barcode = strings.TrimSpace(receipt.Barcode)
+	if err := receipt.Validate(); err != nil {
+		return nil, err
+	}
+	if itemID == 0 && receipt.Barcode == "" {
+		verr := &domain.ValidationError{}
+		verr.Add("barcode", domain.CodeRequired, "barcode is required")
+		return nil, verr
+	}
+
+	m := &domain.StockMovement{
+		Type:      domain.MovementReceipt,
+		Location:  receipt.Location,
+		Reference: receipt.Reference,
+		UserID:    actor.ID,
+		Note:      receipt.Note,
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, unit, err := s.receiptItem(ctx, itemID, receipt)
+		if err != nil {
+			return err
+		}
+		if item.Archived() {
+			return domain.NewConflict("item %s is archived and cannot receive stock", item.Name)
+		}
+		if m.Delta, err = item.ToBase("quantity", receipt.Quantity, unit); err != nil {
+			return err
+		}
+		m.ItemID = item.ID
+		m.UnitCost = domain.RoundCost(receipt.UnitCost * receipt.Quantity / m.Delta)
+		if m.Location == "" {
+			m.Location = item.Location
+		}
+		m.ItemName = item.Name
+		_, err = s.ledger.apply(ctx, m)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// receiptItem finds the item of a receipt and the unit its quantity is in.
+func (s *AdjustmentService) receiptItem(ctx context.Context, itemID int64, receipt *domain.GoodsReceipt) (*domain.Item, string, error) {
+	if receipt.Barcode == "" {
+		item, err := s.itemRepo.GetByID(ctx, itemID)
+		return item, receipt.Unit, err
+	}
+	item, err := s.itemRepo.GetByBarcode(ctx, receipt.Barcode)
+	if err != nil {
+		return nil, "", err
+	}
+	if itemID != 0 && item.ID != itemID {
+		verr := &domain.ValidationError{}
+		verr.Add("barcode", domain.CodeInvalid, fmt.Sprintf("barcode belongs to %s", item.Name))
+		return nil, "", verr
+	}
+	unit := receipt.Unit
+	if unit == "" {
+		unit = item.UnitForBarcode(receipt.Barcode)
+	}
+	return item, unit, nil
+}
+
+// ListAdjustments filters by item and status; zero values match everything.
+func (s *AdjustmentService) ListAdjustments(ctx context.Context, itemID int64, status domain.AdjustmentStatus) ([]*domain.StockAdjustment, error) {
+	return s.adjustmentRepo.List(ctx, itemID, status)
+}
+
+// ListMovements returns the stock ledger of an item, newest first.
+func (s *AdjustmentService) ListMovements(ctx context.Context, itemID int64) ([]*domain.StockMovement, error) {
+	if _, err := s.itemRepo.GetByID(ctx, itemID); err != nil {
+		return nil, err
+	}
+	return s.ledger.movements.ListByItem(ctx, itemID)
+}
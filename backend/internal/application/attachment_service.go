@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"multi-inventory/internal/domain"
+)
+
+// AttachmentService stores item photos and documents. File data goes to
+// blob storage and only its description to the database, so the blobs are
+// written first and removed again if the row cannot be saved.
+type AttachmentService struct {
+	tx          domain.Transactor
+	itemRepo    domain.ItemRepository
+	repo        domain.AttachmentRepository
+	blobs       domain.BlobStore
+	thumbnailer domain.Thumbnailer
+}
+
+func NewAttachmentService(tx domain.Transactor, itemRepo domain.ItemRepository, repo domain.AttachmentRepository, blobs domain.BlobStore, thumbnailer domain.Thumbnailer) *AttachmentService {
+	return &AttachmentService{tx: tx, itemRepo: itemRepo, repo: repo, blobs: blobs, thumbnailer: thumbnailer}
+}
+
+// cleanFileName strips any directory from a client-supplied file name.
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name
+}
+
+// detectContentType sniffs the data. Office files sniff as zip and many
+// text formats as plain text, so those fall back to the file extension.
+func detectContentType(fileName string, data []byte) string {
+	sniffed, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	switch sniffed {
+	case "application/octet-stream", "application/zip", "text/plain":
+		if byExt, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(fileName)), ";"); byExt != "" && !strings.HasPrefix(byExt, "text/html") {
+			return byExt
+		}
+	}
+	return sniffed
+}
+
+// blobKey returns a new storage key under the item, keeping the extension
+// so the stored objects are recognizable.
+func blobKey(itemID int64, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("items/%d/%s%s", itemID, hex.EncodeToString(b), strings.ToLower(ext)), nil
+}
+
+// Upload stores a file with the item. Images get a thumbnail; the first
+// image uploaded becomes the item's picture.
+func (s *AttachmentService) Upload(ctx context.Context, actor *domain.User, itemID int64, fileName string, data []byte) (*domain.Attachment, error) {
+	a := &domain.Attachment{
+		ItemID:     itemID,
+		Kind:       domain.AttachmentDocument,
+		FileName:   cleanFileName(fileName),
+		Size:       int64(len(data)),
+		UploadedBy: userID(actor),
+	}
+	verr := &domain.ValidationError{}
+	if a.FileName == "" {
+		verr.Add("file", domain.CodeRequired, "file name is required")
+	}
+	if len(data) == 0 {
+		verr.Add("file", domain.CodeRequired, "file is empty")
+	}
+	if len(data) > domain.MaxAttachmentSize {
+		verr.Add("file", domain.CodeTooLong, fmt.Sprintf("file must be at most %d MB", domain.MaxAttachmentSize>>20))
+	}
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+
+	a.ContentType = detectContentType(a.FileName, data)
+	var thumb []byte
+	var thumbType string
+	if slices.Contains(domain.AttachmentImageTypes, a.ContentType) {
+		var err error
+		a.Kind = domain.AttachmentImage
+		thumb, thumbType, a.Width, a.Height, err = s.thumbnailer.Thumbnail(data, domain.ThumbnailSize)
+		if err != nil {
+			verr.Add("file", domain.CodeInvalid, fmt.Sprintf("image could not be read: %v", err))
+			return nil, verr
+		}
+	}
+
+	// Check the item before writing any blobs.
+	if _, err := s.itemRepo.GetByID(ctx, itemID); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if a.StorageKey, err = blobKey(itemID, filepath.Ext(a.FileName)); err != nil {
+		return nil, err
+	}
+	if err := s.blobs.Put(ctx, a.StorageKey, a.ContentType, data); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if thumb != nil {
+		// The thumbnail's type is recovered from its extension when served.
+		suffix := ".thumb.jpg"
+		if thumbType == "image/png" {
+			suffix = ".thumb.png"
+		}
+		a.ThumbnailKey = strings.TrimSuffix(a.StorageKey, filepath.Ext(a.StorageKey)) + suffix
+		if err := s.blobs.Put(ctx, a.ThumbnailKey, thumbType, thumb); err != nil {
+			s.removeBlobs(ctx, a.StorageKey)
+			return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+		}
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Create(ctx, a)
+	})
+	if err != nil {
+		s.removeBlobs(ctx, a.StorageKey, a.ThumbnailKey)
+		return nil, err
+	}
+	a.SetURLs()
+	return a, nil
+}
+
+// removeBlobs deletes blobs whose rows are gone or were never written. A
+// failure only leaves an orphaned blob behind, so it is logged.
+func (s *AttachmentService) removeBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("attachments: failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, itemID int64) ([]domain.Attachment, error) {
+	if _, err := s.itemRepo.GetByID(ctx, itemID); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListByItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].SetURLs()
+	}
+	return list, nil
+}
+
+func (s *AttachmentService) GetAttachment(ctx context.Context, id int64) (*domain.Attachment, error) {
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a.SetURLs()
+	return a, nil
+}
+
+// Content returns the attachment with its data, or with its thumbnail's
+// data and content type when thumbnail is set.
+func (s *AttachmentService) Content(ctx context.Context, id int64, thumbnail bool) (*domain.Attachment, []byte, error) {
+	a, err := s.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := a.StorageKey
+	if thumbnail {
+		if a.ThumbnailKey == "" {
+			return nil, nil, domain.NewNotFound("attachment %d has no thumbnail", id)
+		}
+		key = a.ThumbnailKey
+		a.ContentType = mime.TypeByExtension(filepath.Ext(key))
+	}
+	data, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, data, nil
+}
+
+// DeleteAttachment removes the row first so the file disappears from the
+// API even if its blobs cannot be deleted.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, id int64) error {
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.removeBlobs(ctx, a.StorageKey, a.ThumbnailKey)
+	return nil
+}
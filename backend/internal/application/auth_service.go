@@ -26,7 +26,7 @@ func (s *AuthService) Register(ctx context.Context, username, password string) (
 	user := &domain.User{
 		Username: username,
 		Password: string(hashedPassword),
-		Role:     "user",
+		Role:     domain.RoleUser,
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
@@ -38,15 +38,15 @@ func (s *AuthService) Register(ctx context.Context, username, password string) (
 
 func (s *AuthService) Login(ctx context.Context, username, password string) (*domain.User, error) {
 	user, err := s.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.NewUnauthorized("invalid credentials")
+	}
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
-		return nil, errors.New("invalid credentials")
-	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, domain.NewUnauthorized("invalid credentials")
 	}
 
 	return user, nil
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"multi-inventory/internal/domain"
+)
+
+// errBatchAborted rolls back an atomic batch after an operation failed.
+var errBatchAborted = errors.New("batch aborted")
+
+// BatchService runs several operations in one request, so a scanner does
+// not pay a round trip per scan. Each operation goes through the same
+// service as its REST endpoint.
+type BatchService struct {
+	tx        domain.Transactor
+	inventory *InventoryService
+	sales     *SalesService
+}
+
+func NewBatchService(tx domain.Transactor, inventory *InventoryService, sales *SalesService) *BatchService {
+	return &BatchService{tx: tx, inventory: inventory, sales: sales}
+}
+
+// Run runs the operations in order. In an atomic batch they share one
+// transaction, so a lookup sees the items created before it and events
+// are only sent if the whole batch commits.
+func (s *BatchService) Run(ctx context.Context, req *domain.BatchRequest) (*domain.BatchResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result := &domain.BatchResult{Committed: true, Results: make([]domain.BatchOpResult, len(req.Ops))}
+	if !req.Atomic {
+		for i := range req.Ops {
+			res, err := s.run(ctx, &req.Ops[i])
+			if err != nil {
+				res = failedOp(err)
+			}
+			result.Results[i] = res
+		}
+		return result, nil
+	}
+
+	failed := -1
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range req.Ops {
+			res, err := s.run(ctx, &req.Ops[i])
+			if err != nil {
+				failed = i
+				result.Results[i] = failedOp(err)
+				return errBatchAborted
+			}
+			result.Results[i] = res
+		}
+		return nil
+	})
+	if failed < 0 {
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	result.Committed = false
+	for i := range result.Results {
+		switch {
+		case i < failed:
+			result.Results[i] = domain.BatchOpResult{Status: domain.BatchRolledBack}
+		case i > failed:
+			result.Results[i] = domain.BatchOpResult{Status: domain.BatchSkipped}
+		}
+	}
+	return result, nil
+}
+
+func (s *BatchService) run(ctx context.Context, op *domain.BatchOp) (domain.BatchOpResult, error) {
+	if err := op.Validate(); err != nil {
+		return domain.BatchOpResult{}, err
+	}
+	switch op.Op {
+	case domain.BatchLookup:
+		item, err := s.inventory.GetItemByBarcode(ctx, op.Barcode)
+		if err != nil {
+			return domain.BatchOpResult{}, err
+		}
+		return domain.BatchOpResult{Status: domain.BatchOK, Item: item}, nil
+	case domain.BatchCreateItem:
+		if err := s.inventory.CreateItem(ctx, op.Item); err != nil {
+			return domain.BatchOpResult{}, err
+		}
+		return domain.BatchOpResult{Status: domain.BatchOK, Item: op.Item}, nil
+	default:
+		version, err := s.sales.UpdateItemFulfillment(ctx, op.OrderItemID, op.IsFulfilled, op.Version)
+		if err != nil {
+			return domain.BatchOpResult{}, err
+		}
+		return domain.BatchOpResult{Status: domain.BatchOK, Version: version}, nil
+	}
+}
+
+// failedOp reports a failed operation without leaking internal errors.
+func failedOp(err error) domain.BatchOpResult {
+	opErr := domain.NewOpError(err)
+	if opErr == nil {
+		log.Printf("batch: %v", err)
+		opErr = internalOpError
+	}
+	return domain.BatchOpResult{Status: domain.BatchError, Error: opErr}
+}
@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"multi-inventory/internal/domain"
+	"strings"
+)
+
+// CatalogService manages the category tree, brands and tags items are
+// classified by.
+type CatalogService struct {
+	categoryRepo domain.CategoryRepository
+	brandRepo    domain.BrandRepository
+	tagRepo      domain.TagRepository
+}
+
+func NewCatalogService(categoryRepo domain.CategoryRepository, brandRepo domain.BrandRepository, tagRepo domain.TagRepository) *CatalogService {
+	return &CatalogService{categoryRepo: categoryRepo, brandRepo: brandRepo, tagRepo: tagRepo}
+}
+
+func (s *CatalogService) ListCategories(ctx context.Context) ([]*domain.Category, error) {
+	return s.categoryRepo.List(ctx)
+}
+
+func (s *CatalogService) GetCategory(ctx context.Context, id int64) (*domain.Category, error) {
+	return s.categoryRepo.GetByID(ctx, id)
+}
+
+func (s *CatalogService) CreateCategory(ctx context.Context, c *domain.Category) error {
+	if err := s.checkCategory(ctx, c); err != nil {
+		return err
+	}
+	return s.categoryRepo.Create(ctx, c)
+}
+
+// UpdateCategory replaces the category. Moving it below one of its own
+// subcategories is rejected.
+func (s *CatalogService) UpdateCategory(ctx context.Context, c *domain.Category) error {
+	if _, err := s.categoryRepo.GetByID(ctx, c.ID); err != nil {
+		return err
+	}
+	if err := s.checkCategory(ctx, c); err != nil {
+		return err
+	}
+	return s.categoryRepo.Update(ctx, c)
+}
+
+func (s *CatalogService) checkCategory(ctx context.Context, c *domain.Category) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	return domain.NewCategoryTree(categories).CheckParent(c.ID, c.ParentID)
+}
+
+// DeleteCategory only deletes empty categories without subcategories.
+func (s *CatalogService) DeleteCategory(ctx context.Context, id int64) error {
+	return s.categoryRepo.Delete(ctx, id)
+}
+
+func (s *CatalogService) ListBrands(ctx context.Context) ([]*domain.Brand, error) {
+	return s.brandRepo.List(ctx)
+}
+
+func (s *CatalogService) CreateBrand(ctx context.Context, b *domain.Brand) error {
+	b.Name = strings.TrimSpace(b.Name)
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	return s.brandRepo.Create(ctx, b)
+}
+
+func (s *CatalogService) UpdateBrand(ctx context.Context, b *domain.Brand) error {
+	b.Name = strings.TrimSpace(b.Name)
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	return s.brandRepo.Update(ctx, b)
+}
+
+func (s *CatalogService) DeleteBrand(ctx context.Context, id int64) error {
+	return s.brandRepo.Delete(ctx, id)
+}
+
+func (s *CatalogService) ListTags(ctx context.Context) ([]domain.Tag, error) {
+	return s.tagRepo.List(ctx)
+}
+
+// prepareLinks normalizes the classification of an item and checks that its
+// brand and categories exist. It returns the category tree for resolving
+// the item's settings.
+func prepareLinks(ctx context.Context, categoryRepo domain.CategoryRepository, brandRepo domain.BrandRepository, item *domain.Item) (*domain.CategoryTree, error) {
+	item.Tags = domain.NormalizeTags(item.Tags)
+	verr := &domain.ValidationError{}
+	if item.BrandID != nil {
+		_, err := brandRepo.GetByID(ctx, *item.BrandID)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			verr.Add("brand_id", domain.CodeInvalid, "brand does not exist")
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	categories, err := categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := domain.NewCategoryTree(categories)
+	seen := make(map[int64]bool, len(item.CategoryIDs))
+	ids := make([]int64, 0, len(item.CategoryIDs))
+	for _, id := range item.CategoryIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, ok := tree.Get(id); !ok {
+			verr.Add("category_ids", domain.CodeInvalid, "category does not exist")
+			continue
+		}
+		ids = append(ids, id)
+	}
+	item.CategoryIDs = ids
+	return tree, verr.Err()
+}
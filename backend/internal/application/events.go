@@ -0,0 +1,197 @@
+package application
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+// Event hub limits. The backlog is what a reconnecting subscriber can
+// resume from; a subscriber lagging by more than its buffer is dropped and
+// resumes from the backlog when it reconnects.
+const (
+	eventBacklogSize  = 1024
+	subscriberBufSize = 256
+)
+
+// EventHub fans committed events out to subscribers and keeps the latest
+// ones for resuming. It lives in memory: event IDs carry the hub's start
+// time, so IDs from before a restart are recognized as unresumable.
+type EventHub struct {
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64
+	backlog []hubEvent // Ring buffer, oldest at head once full
+	head    int
+	subs    map[*Subscription]struct{}
+}
+
+type hubEvent struct {
+	seq uint64
+	ev  domain.Event
+}
+
+// Subscription receives the events matching its filter on C. C is closed
+// when the subscriber falls too far behind.
+type Subscription struct {
+	C      <-chan domain.Event
+	ch     chan domain.Event
+	filter domain.EventFilter
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns IDs to the events and delivers them. It never blocks on
+// a slow subscriber.
+func (h *EventHub) Publish(events ...domain.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, ev := range events {
+		h.seq++
+		ev.ID = h.epoch + "-" + strconv.FormatUint(h.seq, 10)
+		if len(h.backlog) < eventBacklogSize {
+			h.backlog = append(h.backlog, hubEvent{seq: h.seq, ev: ev})
+		} else {
+			h.backlog[h.head] = hubEvent{seq: h.seq, ev: ev}
+			h.head = (h.head + 1) % eventBacklogSize
+		}
+		for sub := range h.subs {
+			if !sub.filter.Matches(ev) {
+				continue
+			}
+			select {
+			case sub.ch <- ev:
+			default:
+				delete(h.subs, sub)
+				close(sub.ch)
+			}
+		}
+	}
+}
+
+// Subscribe starts a subscription. With the ID of the last event a client
+// saw, it also returns the matching events published since. complete is
+// false when some of those are no longer known, either because the
+// backlog moved on or the server restarted; the client should then reload
+// its data.
+func (h *EventHub) Subscribe(filter domain.EventFilter, lastEventID string) (sub *Subscription, missed []domain.Event, complete bool) {
+	ch := make(chan domain.Event, subscriberBufSize)
+	sub = &Subscription{C: ch, ch: ch, filter: filter}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[sub] = struct{}{}
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+	epoch, seqStr, _ := strings.Cut(lastEventID, "-")
+	last, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil || epoch != h.epoch || last > h.seq {
+		return sub, nil, false
+	}
+	complete = true
+	if n := len(h.backlog); n > 0 && h.backlog[h.head].seq > last+1 {
+		complete = false
+	}
+	for i := range h.backlog {
+		e := h.backlog[(h.head+i)%len(h.backlog)]
+		if e.seq > last && filter.Matches(e.ev) {
+			missed = append(missed, e.ev)
+		}
+	}
+	return sub, missed, complete
+}
+
+// LastEventID returns the ID of the latest event, or "" before the first.
+func (h *EventHub) LastEventID() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.seq == 0 {
+		return ""
+	}
+	return h.epoch + "-" + strconv.FormatUint(h.seq, 10)
+}
+
+func (h *EventHub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+type eventsKey struct{}
+
+// eventBuffer holds the events emitted in one transaction.
+type eventBuffer struct {
+	events []domain.Event
+}
+
+// eventTransactor records the events emitted in a transaction in the
+// outbox before it commits and publishes them once it has. Events of a
+// nested transaction are passed to the outer one, so a rolled-back
+// savepoint drops its events and nothing leaves before the outermost
+// commit.
+type eventTransactor struct {
+	tx        domain.Transactor
+	publisher domain.EventPublisher
+	outbox    domain.OutboxRepository
+}
+
+// WithEvents wraps tx so the services using it record and publish their
+// events.
+func WithEvents(tx domain.Transactor, publisher domain.EventPublisher, outbox domain.OutboxRepository) domain.Transactor {
+	return &eventTransactor{tx: tx, publisher: publisher, outbox: outbox}
+}
+
+func (t *eventTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent, _ := ctx.Value(eventsKey{}).(*eventBuffer)
+	buf := &eventBuffer{}
+	err := t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := fn(context.WithValue(ctx, eventsKey{}, buf)); err != nil {
+			return err
+		}
+		if parent != nil || len(buf.events) == 0 {
+			return nil
+		}
+		return t.outbox.Append(ctx, buf.events)
+	})
+	if err != nil {
+		return err
+	}
+	if parent != nil {
+		parent.events = append(parent.events, buf.events...)
+	} else if len(buf.events) > 0 {
+		t.publisher.Publish(buf.events...)
+	}
+	return nil
+}
+
+// emit records an event for publishing when the surrounding transaction
+// commits. Outside a transaction of WithEvents it does nothing, so every
+// change that emits must run in one.
+func emit(ctx context.Context, eventType string, data any, locations ...string) {
+	buf, ok := ctx.Value(eventsKey{}).(*eventBuffer)
+	if !ok {
+		return
+	}
+	ev, err := domain.NewEvent(eventType, data, locations...)
+	if err != nil {
+		// The data types are ours and always encode; losing a
+		// notification is not worth failing the change over.
+		log.Printf("events: %v", err)
+		return
+	}
+	buf.events = append(buf.events, ev)
+}
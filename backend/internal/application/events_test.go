@@ -0,0 +1,122 @@
+package application
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"multi-inventory/internal/domain"
+)
+
+// hubWith returns a hub that published n events, item events at odd and
+// stock events at even sequence numbers.
+func hubWith(n int) *EventHub {
+	h := NewEventHub()
+	for i := 1; i <= n; i++ {
+		topic := domain.TopicItem
+		if i%2 == 0 {
+			topic = domain.TopicStock
+		}
+		h.Publish(domain.Event{Topic: topic, Type: string(topic) + ".test"})
+	}
+	return h
+}
+
+func eventSeq(t *testing.T, ev domain.Event) int {
+	t.Helper()
+	seq, err := strconv.Atoi(ev.ID[strings.LastIndex(ev.ID, "-")+1:])
+	if err != nil {
+		t.Fatalf("bad event id %q", ev.ID)
+	}
+	return seq
+}
+
+func TestEventHubSubscribe(t *testing.T) {
+	full := eventBacklogSize + 10
+	tests := []struct {
+		name      string
+		published int
+		filter    domain.EventFilter
+		last      string // Sequence number of the last event seen, or a raw ID after "raw:"
+		wantFirst int    // Sequence number of the first missed event
+		wantCount int
+		complete  bool
+	}{
+		{"new subscription", 5, domain.EventFilter{}, "", 0, 0, true},
+		{"resume", 5, domain.EventFilter{}, "2", 3, 3, true},
+		{"up to date", 5, domain.EventFilter{}, "5", 0, 0, true},
+		{"before the first event", 5, domain.EventFilter{}, "0", 1, 5, true},
+		{"filtered", 5, domain.EventFilter{Topics: []domain.EventTopic{domain.TopicStock}}, "1", 2, 2, true},
+		{"ahead of the hub", 5, domain.EventFilter{}, "9", 0, 0, false},
+		{"other epoch", 5, domain.EventFilter{}, "raw:0-2", 0, 0, false},
+		{"malformed", 5, domain.EventFilter{}, "raw:garbage", 0, 0, false},
+		{"backlog exactly full", eventBacklogSize, domain.EventFilter{}, "0", 1, eventBacklogSize, true},
+		{"backlog wrapped, oldest kept", full, domain.EventFilter{}, "10", 11, eventBacklogSize, true},
+		{"backlog wrapped, events lost", full, domain.EventFilter{}, "9", 11, eventBacklogSize, false},
+		{"backlog wrapped, resume near the end", full, domain.EventFilter{}, strconv.Itoa(full - 2), full - 1, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := hubWith(tt.published)
+			last := tt.last
+			if raw, ok := strings.CutPrefix(last, "raw:"); ok {
+				last = raw
+			} else if last != "" {
+				last = h.epoch + "-" + last
+			}
+
+			sub, missed, complete := h.Subscribe(tt.filter, last)
+			defer h.Unsubscribe(sub)
+			if complete != tt.complete {
+				t.Errorf("complete = %v, want %v", complete, tt.complete)
+			}
+			if len(missed) != tt.wantCount {
+				t.Fatalf("missed %d events, want %d", len(missed), tt.wantCount)
+			}
+			prev := 0
+			for k, ev := range missed {
+				seq := eventSeq(t, ev)
+				if k == 0 && seq != tt.wantFirst {
+					t.Errorf("first missed event = %d, want %d", seq, tt.wantFirst)
+				}
+				if seq <= prev {
+					t.Errorf("missed events out of order: %d after %d", seq, prev)
+				}
+				if !tt.filter.Matches(ev) {
+					t.Errorf("missed event %d does not match the filter", seq)
+				}
+				prev = seq
+			}
+		})
+	}
+}
+
+func TestEventHubDropsSlowSubscriber(t *testing.T) {
+	h := NewEventHub()
+	slow, _, _ := h.Subscribe(domain.EventFilter{}, "")
+	other, _, _ := h.Subscribe(domain.EventFilter{Topics: []domain.EventTopic{domain.TopicOrder}}, "")
+	defer h.Unsubscribe(other)
+	for i := 0; i <= subscriberBufSize; i++ {
+		h.Publish(domain.Event{Topic: domain.TopicItem, Type: "item.test"})
+	}
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != subscriberBufSize {
+		t.Errorf("slow subscriber received %d events before being dropped, want %d", received, subscriberBufSize)
+	}
+	// Dropping closed the channel; unsubscribing afterwards must not close it again.
+	h.Unsubscribe(slow)
+
+	h.Publish(domain.Event{Topic: domain.TopicOrder, Type: "order.test"})
+	select {
+	case ev, ok := <-other.C:
+		if !ok || ev.Topic != domain.TopicOrder {
+			t.Errorf("filtered subscriber got %v, %v", ev, ok)
+		}
+	default:
+		t.Error("filtered subscriber was dropped for events it does not receive")
+	}
+}
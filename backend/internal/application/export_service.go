@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"math"
+	"multi-inventory/internal/domain"
+)
+
+// RowWriter receives export rows in the order of the export's header.
+type RowWriter interface {
+	Write(values ...any) error
+}
+
+// Export headers. Rows are written with values in the same order.
+var (
+	ItemExportHeader = []string{
+		"id", "name", "barcode", "location", "halal_status", "halal_expires_at", "quantity", "unit", "price", "stock_value",
+		"unit_cost", "cost_value", "archived_at", "updated_at",
+	}
+	SalesExportHeader = []string{
+		"order_id", "invoice_number", "created_at", "status", "user_id", "order_total",
+		"line_id", "item_id", "item_name", "quantity", "price_at_sale", "line_total", "cost_at_sale", "is_fulfilled",
+	}
+	MovementExportHeader = []string{
+		"id", "created_at", "item_id", "item_name", "type", "delta", "quantity_after",
+		"unit_cost", "value_after", "location", "reference", "user_id", "note",
+	}
+)
+
+// ExportService streams data for spreadsheets. Rows go straight from the
+// database to the writer, so exports of any size use constant memory.
+type ExportService struct {
+	itemRepo     domain.ItemRepository
+	orderRepo    domain.OrderRepository
+	movementRepo domain.MovementRepository
+}
+
+func NewExportService(itemRepo domain.ItemRepository, orderRepo domain.OrderRepository, movementRepo domain.MovementRepository) *ExportService {
+	return &ExportService{itemRepo: itemRepo, orderRepo: orderRepo, movementRepo: movementRepo}
+}
+
+// ExportItems writes current stock with its retail value and its value at
+// cost.
+func (s *ExportService) ExportItems(ctx context.Context, filter domain.ItemFilter, w RowWriter) error {
+	return s.itemRepo.Each(ctx, filter, func(item *domain.Item) error {
+		return w.Write(
+			item.ID, item.Name, item.Barcode, item.Location, string(item.Halal.Status), item.Halal.ExpiresAt,
+			item.Quantity, item.Unit, item.Price,
+			roundMoney(item.Price*item.Quantity), item.UnitCost, item.StockValue, item.ArchivedAt, item.UpdatedAt,
+		)
+	})
+}
+
+// ExportSales writes one row per order line, repeating the order columns.
+func (s *ExportService) ExportSales(ctx context.Context, period domain.DateRange, w RowWriter) error {
+	if err := period.Validate(); err != nil {
+		return err
+	}
+	return s.orderRepo.EachLine(ctx, period, func(order *domain.SalesOrder, line *domain.SalesOrderItem) error {
+		return w.Write(
+			order.ID, order.InvoiceNumber, order.CreatedAt, order.Status, order.UserID, order.TotalPrice,
+			line.ID, line.ItemID, line.ItemName, line.Quantity, line.PriceAtSale,
+			roundMoney(line.PriceAtSale*line.Quantity), line.CostAtSale, line.IsFulfilled,
+		)
+	})
+}
+
+func (s *ExportService) ExportMovements(ctx context.Context, filter domain.MovementFilter, w RowWriter) error {
+	if err := filter.Period.Validate(); err != nil {
+		return err
+	}
+	return s.movementRepo.Each(ctx, filter, func(m *domain.StockMovement) error {
+		return w.Write(
+			m.ID, m.CreatedAt, m.ItemID, m.ItemName, string(m.Type), m.Delta, m.QuantityAfter,
+			m.UnitCost, m.ValueAfter, m.Location, m.Reference, m.UserID, m.Note,
+		)
+	})
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,55 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"multi-inventory/internal/domain"
+)
+
+// UploadHalalDocument stores the certificate of a certified item, replacing
+// any earlier upload. The content type is sniffed from the data rather than
+// trusted from the client.
+func (s *InventoryService) UploadHalalDocument(ctx context.Context, actor *domain.User, doc *domain.HalalDocument) error {
+	verr := &domain.ValidationError{}
+	doc.FileName = cleanFileName(doc.FileName)
+	if doc.FileName == "" {
+		verr.Add("file", domain.CodeRequired, "file name is required")
+	}
+	if len(doc.Data) == 0 {
+		verr.Add("file", domain.CodeRequired, "file is empty")
+	}
+	if len(doc.Data) > domain.MaxHalalDocumentSize {
+		verr.Add("file", domain.CodeTooLong, fmt.Sprintf("file must be at most %d MB", domain.MaxHalalDocumentSize>>20))
+	}
+	doc.ContentType, _, _ = strings.Cut(http.DetectContentType(doc.Data), ";")
+	if len(doc.Data) > 0 && !slices.Contains(domain.HalalDocumentTypes, doc.ContentType) {
+		verr.Add("file", domain.CodeInvalid, "file must be a PDF, JPEG or PNG")
+	}
+	if err := verr.Err(); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := s.itemRepo.GetByID(ctx, doc.ItemID)
+		if err != nil {
+			return err
+		}
+		if item.Halal.Status != domain.HalalCertified {
+			return domain.NewConflict("item %s is not certified halal; set its halal status first", item.Name)
+		}
+		doc.UploadedBy = userID(actor)
+		return s.halalDocs.Put(ctx, doc)
+	})
+}
+
+func (s *InventoryService) HalalDocument(ctx context.Context, itemID int64) (*domain.HalalDocument, error) {
+	return s.halalDocs.Get(ctx, itemID)
+}
+
+func (s *InventoryService) DeleteHalalDocument(ctx context.Context, itemID int64) error {
+	return s.halalDocs.Delete(ctx, itemID)
+}
@@ -0,0 +1,91 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+// idempotencyLockTimeout is how long a key stays reserved by a request that
+// never finished, e.g. because the server stopped, before a retry may take
+// it over. It must outlast the slowest request.
+const idempotencyLockTimeout = 5 * time.Minute
+
+// errKeyInProgress is the cause of the Conflict for a key whose first
+// request is still running, which unlike a mismatch is worth retrying.
+var errKeyInProgress = errors.New("idempotency key in progress")
+
+// IdempotencyService makes retried requests safe: the first request with a
+// key runs and its response is kept for ttl; retries with the key get that
+// response back.
+type IdempotencyService struct {
+	repo domain.IdempotencyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotencyService(repo domain.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, ttl: ttl}
+}
+
+// Begin reserves the key for the request identified by requestHash. It
+// returns either the reservation, to be passed to Finish once the request
+// ran, or the stored record to replay. A key used for a different request,
+// or whose first request is still running, fails with a Conflict.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, requestHash string) (reserved, replay *domain.IdempotencyRecord, err error) {
+	now := time.Now()
+	rec := &domain.IdempotencyRecord{Scope: scope, Key: key, RequestHash: requestHash, ExpiresAt: now.Add(s.ttl)}
+	existing, err := s.repo.Reserve(ctx, rec, now.Add(-idempotencyLockTimeout))
+	switch {
+	case err != nil:
+		return nil, nil, err
+	case existing == nil:
+		return rec, nil, nil
+	case existing.RequestHash != requestHash:
+		return nil, nil, domain.NewConflict("Idempotency-Key was already used for a different request")
+	case existing.Status != domain.IdempotencyCompleted:
+		return nil, nil, &domain.Error{
+			Kind:    domain.KindConflict,
+			Message: "a request with this Idempotency-Key is still in progress",
+			Err:     errKeyInProgress,
+		}
+	}
+	return nil, existing, nil
+}
+
+// Finish stores the response for replays. Server errors are not stored:
+// the change was rolled back, so the key is released and a retry runs the
+// request again.
+func (s *IdempotencyService) Finish(ctx context.Context, rec *domain.IdempotencyRecord, status int, headers map[string]string, body []byte) error {
+	if status >= http.StatusInternalServerError {
+		return s.repo.Release(ctx, rec)
+	}
+	rec.ResponseStatus, rec.ResponseHeaders, rec.ResponseBody = status, headers, body
+	return s.repo.Complete(ctx, rec)
+}
+
+// Abandon releases the key of a request that did not finish.
+func (s *IdempotencyService) Abandon(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	return s.repo.Release(ctx, rec)
+}
+
+// RunCleanup deletes expired keys every interval until ctx is done.
+func (s *IdempotencyService) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.repo.DeleteExpired(ctx); err != nil {
+			log.Printf("idempotency cleanup: %v", err)
+		} else if n > 0 {
+			log.Printf("idempotency cleanup: deleted %d expired keys", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
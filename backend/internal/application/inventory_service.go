@@ -2,38 +2,385 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"multi-inventory/internal/domain"
+	"slices"
+	"strings"
 )
 
 type InventoryService struct {
-	itemRepo domain.ItemRepository
+	tx           domain.Transactor
+	itemRepo     domain.ItemRepository
+	priceRepo    domain.PriceRepository
+	categoryRepo domain.CategoryRepository
+	brandRepo    domain.BrandRepository
+	productRepo  domain.ProductRepository
+	halalDocs    domain.HalalDocumentRepository
+	ledger       *StockLedger
+	barcodes     *domain.BarcodeGenerator
 }
 
-func NewInventoryService(itemRepo domain.ItemRepository) *InventoryService {
-	return &InventoryService{itemRepo: itemRepo}
+func NewInventoryService(tx domain.Transactor, itemRepo domain.ItemRepository, priceRepo domain.PriceRepository, categoryRepo domain.CategoryRepository, brandRepo domain.BrandRepository, productRepo domain.ProductRepository, halalDocs domain.HalalDocumentRepository, ledger *StockLedger, barcodes *domain.BarcodeGenerator) *InventoryService {
+	return &InventoryService{
+		tx:           tx,
+		itemRepo:     itemRepo,
+		priceRepo:    priceRepo,
+		categoryRepo: categoryRepo,
+		brandRepo:    brandRepo,
+		productRepo:  productRepo,
+		halalDocs:    halalDocs,
+		ledger:       ledger,
+		barcodes:     barcodes,
+	}
 }
 
+// CreateItem stores a new item. Its opening quantity is recorded as the
+// first stock movement so the ledger always sums to the stored quantity,
+// valued at item.UnitCost.
 func (s *InventoryService) CreateItem(ctx context.Context, item *domain.Item) error {
-	// Add validation logic here if needed
-	return s.itemRepo.Create(ctx, item)
+	tree, err := s.prepareItem(ctx, item)
+	if err != nil {
+		return err
+	}
+	if err := s.prepareBarcode(ctx, item, nil); err != nil {
+		return err
+	}
+	item.Effective = tree.Settings(item)
+	if item.Quantity == 0 {
+		item.UnitCost = 0
+	}
+	item.StockValue = domain.RoundCost(item.Quantity * item.UnitCost)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkSiblings(ctx, item); err != nil {
+			return err
+		}
+		if err := s.itemRepo.Create(ctx, item); err != nil {
+			return err
+		}
+		emit(ctx, domain.EventItemCreated, item, item.Location)
+		if item.Quantity == 0 {
+			return nil
+		}
+		return s.ledger.opening(ctx, item)
+	})
 }
 
-func (s *InventoryService) UpdateItem(ctx context.Context, item *domain.Item) error {
-	return s.itemRepo.Update(ctx, item)
+// UpdateItem replaces the editable fields of an item. The quantity must be
+// left as stored; stock only changes through adjustments and sales. A
+// changed price is added to the price history with actor and reason.
+func (s *InventoryService) UpdateItem(ctx context.Context, actor *domain.User, item *domain.Item, reason string) error {
+	tree, err := s.prepareItem(ctx, item)
+	if err != nil {
+		return err
+	}
+	current, err := s.itemRepo.GetByID(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	if item.Quantity != current.Quantity {
+		verr := &domain.ValidationError{}
+		verr.Add("quantity", domain.CodeReadOnly, "quantity can only change through stock adjustments")
+		return verr
+	}
+	if err := current.CheckUnitChange(item.Unit); err != nil {
+		return err
+	}
+	if err := s.prepareBarcode(ctx, item, current); err != nil {
+		return err
+	}
+	item.Effective = tree.Settings(item)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkSiblings(ctx, item); err != nil {
+			return err
+		}
+		if err := s.itemRepo.Update(ctx, item); err != nil {
+			return err
+		}
+		emitItemUpdated(ctx, item, current.Location)
+		return s.recordPriceEdit(ctx, actor, item, current.Price, reason)
+	})
 }
 
-func (s *InventoryService) DeleteItem(ctx context.Context, id int64) error {
-	return s.itemRepo.Delete(ctx, id)
+// PatchItem applies a JSON Merge Patch to the item at the given version.
+func (s *InventoryService) PatchItem(ctx context.Context, actor *domain.User, id, version int64, patch domain.ItemPatch, reason string) (*domain.Item, error) {
+	item, err := s.itemRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Version != version {
+		return nil, domain.NewPreconditionFailed("item was modified by someone else")
+	}
+	// Decoding the patch may reuse the backing array of Units.
+	previous := *item
+	previous.Units = slices.Clone(item.Units)
+	if err := patch.Apply(item); err != nil {
+		return nil, err
+	}
+	if err := s.checkVariant(ctx, item); err != nil {
+		return nil, err
+	}
+	tree, err := prepareLinks(ctx, s.categoryRepo, s.brandRepo, item)
+	if err != nil {
+		return nil, err
+	}
+	item.Effective = tree.Settings(item)
+	if _, ok := patch["barcode"]; ok {
+		if err := s.prepareBarcode(ctx, item, &previous); err != nil {
+			return nil, err
+		}
+	} else if _, ok := patch["units"]; ok {
+		if err := s.checkUnitBarcodes(ctx, item, previous.Units); err != nil {
+			return nil, err
+		}
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkSiblings(ctx, item); err != nil {
+			return err
+		}
+		if err := s.itemRepo.Update(ctx, item); err != nil {
+			return err
+		}
+		emitItemUpdated(ctx, item, previous.Location)
+		return s.recordPriceEdit(ctx, actor, item, previous.Price, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// prepareItem validates the item, its brand and category links and its
+// variant attributes.
+func (s *InventoryService) prepareItem(ctx context.Context, item *domain.Item) (*domain.CategoryTree, error) {
+	item.Tags = domain.NormalizeTags(item.Tags)
+	item.NormalizeUnits()
+	item.Halal.Normalize()
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkVariant(ctx, item); err != nil {
+		return nil, err
+	}
+	return prepareLinks(ctx, s.categoryRepo, s.brandRepo, item)
+}
+
+// withSettings resolves the inherited settings of the items.
+func (s *InventoryService) withSettings(ctx context.Context, items ...*domain.Item) error {
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	tree := domain.NewCategoryTree(categories)
+	for _, item := range items {
+		item.Effective = tree.Settings(item)
+	}
+	return nil
+}
+
+// prepareBarcode generates an internal barcode when the item has none and
+// otherwise checks it is unique, as are the barcodes of its units. Barcodes
+// that differ from those of previous, the item as stored or nil for a new
+// one, must have a valid GTIN check digit. A barcode that is a leading-zero
+// variant of another item's or unit's barcode counts as a duplicate.
+func (s *InventoryService) prepareBarcode(ctx context.Context, item, previous *domain.Item) error {
+	var stored domain.Item
+	if previous != nil {
+		stored = *previous
+	}
+	item.Barcode = strings.TrimSpace(item.Barcode)
+	if item.Barcode == "" {
+		code, err := s.generateBarcode(ctx)
+		if err != nil {
+			return err
+		}
+		item.Barcode = code
+	} else {
+		if err := item.ValidateNewBarcode(stored.Barcode); err != nil {
+			return err
+		}
+		if err := s.checkBarcode(ctx, item, item.Barcode); err != nil {
+			return err
+		}
+	}
+	return s.checkUnitBarcodes(ctx, item, stored.Units)
+}
+
+// checkUnitBarcodes checks the unit barcodes that are not among the
+// previous units' like prepareBarcode does.
+func (s *InventoryService) checkUnitBarcodes(ctx context.Context, item *domain.Item, previous []domain.ItemUnit) error {
+	if err := item.ValidateNewUnitBarcodes(previous); err != nil {
+		return err
+	}
+	for _, u := range item.Units {
+		if u.Barcode == "" {
+			continue
+		}
+		if err := s.checkBarcode(ctx, item, u.Barcode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBarcode fails if the barcode scans to an item other than item.
+func (s *InventoryService) checkBarcode(ctx context.Context, item *domain.Item, barcode string) error {
+	existing, err := s.itemRepo.GetByBarcode(ctx, barcode)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != item.ID {
+		return domain.NewConflict("barcode %s already exists", barcode)
+	}
+	return nil
+}
+
+// ArchiveItem hides the item from listings, scanning and new orders while
+// keeping its history. Archiving an archived item is a no-op.
+func (s *InventoryService) ArchiveItem(ctx context.Context, id int64) (*domain.Item, error) {
+	return s.setArchived(ctx, id, true)
+}
+
+func (s *InventoryService) RestoreItem(ctx context.Context, id int64) (*domain.Item, error) {
+	return s.setArchived(ctx, id, false)
+}
+
+func (s *InventoryService) setArchived(ctx context.Context, id int64, archived bool) (*domain.Item, error) {
+	var item *domain.Item
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if item, err = s.itemRepo.SetArchived(ctx, id, archived); err != nil {
+			return err
+		}
+		eventType := domain.EventItemRestored
+		if archived {
+			eventType = domain.EventItemArchived
+		}
+		emit(ctx, eventType, item, item.Location)
+		return nil
+	})
+	return item, err
+}
+
+// emitItemUpdated publishes the item to subscribers of its old and new
+// location, so a move between locations is seen on both.
+func emitItemUpdated(ctx context.Context, item *domain.Item, oldLocation string) {
+	emit(ctx, domain.EventItemUpdated, item, oldLocation, item.Location)
+}
+
+// PurgeItem permanently deletes an item. Only admins may purge, and only
+// items that were never sold or adjusted.
+func (s *InventoryService) PurgeItem(ctx context.Context, actor *domain.User, id int64) error {
+	if !actor.HasRole(domain.RoleAdmin) {
+		return domain.NewForbidden("only admins can purge items")
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := s.itemRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.itemRepo.Purge(ctx, id); err != nil {
+			return err
+		}
+		emit(ctx, domain.EventItemPurged, map[string]int64{"id": id}, item.Location)
+		return nil
+	})
 }
 
 func (s *InventoryService) GetItem(ctx context.Context, id int64) (*domain.Item, error) {
-	return s.itemRepo.GetByID(ctx, id)
+	item, err := s.itemRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withSettings(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
+// GetItemByBarcode finds an item by any leading-zero variant of its barcode
+// or of one of its units' barcodes, naming the unit that was scanned.
+// Archived items do not scan.
 func (s *InventoryService) GetItemByBarcode(ctx context.Context, barcode string) (*domain.Item, error) {
-	return s.itemRepo.GetByBarcode(ctx, barcode)
+	item, err := s.itemRepo.GetByBarcode(ctx, strings.TrimSpace(barcode))
+	if err != nil {
+		return nil, err
+	}
+	item.ScannedUnit = item.UnitForBarcode(barcode)
+	if item.Archived() {
+		return nil, domain.NewNotFound("item not found")
+	}
+	if err := s.withSettings(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// LookupBarcodes looks up several scanned barcodes at once, answering in
+// the order given. Barcodes that match no active item get no item.
+func (s *InventoryService) LookupBarcodes(ctx context.Context, barcodes []string) ([]domain.BarcodeMatch, error) {
+	if len(barcodes) > domain.MaxBatchOps {
+		verr := &domain.ValidationError{}
+		verr.Add("barcodes", domain.CodeTooLong, fmt.Sprintf("at most %d barcodes per lookup", domain.MaxBatchOps))
+		return nil, verr
+	}
+	found := make(map[string]*domain.Item, len(barcodes))
+	matches := make([]domain.BarcodeMatch, len(barcodes))
+	for i, code := range barcodes {
+		item, seen := found[code]
+		if !seen {
+			var err error
+			item, err = s.GetItemByBarcode(ctx, code)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return nil, err
+			}
+			found[code] = item
+		}
+		matches[i] = domain.BarcodeMatch{Barcode: code, Item: item}
+	}
+	return matches, nil
+}
+
+func (s *InventoryService) ListItems(ctx context.Context, filter domain.ItemFilter) ([]*domain.Item, error) {
+	items, err := s.itemRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withSettings(ctx, items...); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// PrepareLabels resolves label requests to items. It only reads; items
+// saved before barcodes were assigned automatically must be edited first.
+func (s *InventoryService) PrepareLabels(ctx context.Context, reqs []domain.LabelRequest) ([]domain.Label, error) {
+	if err := domain.ValidateLabelRequests(reqs); err != nil {
+		return nil, err
+	}
+	labels := make([]domain.Label, 0, len(reqs))
+	for i, req := range reqs {
+		item, err := s.itemRepo.GetByID(ctx, req.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item %d: %w", req.ItemID, err)
+		}
+		if item.Barcode == "" {
+			verr := &domain.ValidationError{}
+			verr.Add(fmt.Sprintf("items[%d].item_id", i), domain.CodeInvalid, "item has no barcode; save it to assign one")
+			return nil, verr
+		}
+		labels = append(labels, domain.Label{Item: item, Copies: req.Quantity})
+	}
+	return labels, nil
 }
 
-func (s *InventoryService) ListItems(ctx context.Context) ([]*domain.Item, error) {
-	return s.itemRepo.List(ctx)
+func (s *InventoryService) generateBarcode(ctx context.Context) (string, error) {
+	seq, err := s.itemRepo.NextBarcodeSequence(ctx)
+	if err != nil {
+		return "", err
+	}
+	return s.barcodes.Generate(seq)
 }
@@ -0,0 +1,248 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"multi-inventory/internal/domain"
+)
+
+// ImportOptions control how ImportItems commits.
+type ImportOptions struct {
+	// DryRun validates every row and reports what would happen without
+	// writing anything.
+	DryRun bool
+	// SkipInvalid imports the valid rows and reports the rest. Without it a
+	// single invalid row rolls back the whole import.
+	SkipInvalid bool
+}
+
+// errImportFailed rolls back an import whose rows failed while writing.
+var errImportFailed = errors.New("import has failed rows")
+
+// ImportItems upserts items by barcode in one transaction. Rows with a
+// barcode that matches a stored item update it, other rows create a new
+// item. A changed quantity on an existing item is recorded as an import
+// movement so the stock ledger stays complete.
+func (s *InventoryService) ImportItems(ctx context.Context, actor *domain.User, rows []domain.ImportRow, opts ImportOptions) (*domain.ImportResult, error) {
+	if len(rows) > domain.MaxImportRows {
+		verr := &domain.ValidationError{}
+		verr.Add("file", domain.CodeInvalid, fmt.Sprintf("imports are limited to %d rows", domain.MaxImportRows))
+		return nil, verr
+	}
+
+	// Validate everything first, so a failing import never touches the
+	// barcode sequence or holds locks.
+	preview, err := s.importRows(ctx, actor, rows, false)
+	if err != nil {
+		return nil, err
+	}
+	preview.DryRun = opts.DryRun
+	if opts.DryRun || (preview.Summary.Failed > 0 && !opts.SkipInvalid) {
+		return preview, nil
+	}
+
+	var result *domain.ImportResult
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.importRows(ctx, actor, rows, true)
+		if err != nil {
+			return err
+		}
+		if result.Summary.Failed > 0 && !opts.SkipInvalid {
+			return errImportFailed
+		}
+		return nil
+	})
+	if errors.Is(err, errImportFailed) {
+		// Rows can still fail here if the data changed since the preview.
+		for i := range result.Rows {
+			result.Rows[i].ItemID = 0
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Committed = true
+	return result, nil
+}
+
+func (s *InventoryService) importRows(ctx context.Context, actor *domain.User, rows []domain.ImportRow, write bool) (*domain.ImportResult, error) {
+	result := &domain.ImportResult{Rows: []domain.ImportRowResult{}}
+	seen := make(map[string]int) // normalized barcode -> row
+	for _, row := range rows {
+		if row.Blank() {
+			continue
+		}
+		res, err := s.importRow(ctx, actor, row, seen, write)
+		if err != nil {
+			return nil, err
+		}
+		result.Add(res)
+	}
+	return result, nil
+}
+
+// importRow returns an error only for failures that should abort the whole
+// import; problems with the row itself are reported in the result.
+func (s *InventoryService) importRow(ctx context.Context, actor *domain.User, row domain.ImportRow, seen map[string]int, write bool) (domain.ImportRowResult, error) {
+	res := domain.ImportRowResult{Row: row.Row}
+	item, set, err := row.Item()
+	if err != nil {
+		return rowFailed(res, err)
+	}
+	res.Barcode = item.Barcode
+
+	if item.Barcode != "" {
+		key := domain.NormalizeGTIN(item.Barcode)
+		if first, dup := seen[key]; dup {
+			verr := &domain.ValidationError{}
+			verr.Add("barcode", domain.CodeInvalid, fmt.Sprintf("barcode already used on row %d", first))
+			return rowFailed(res, verr)
+		}
+		seen[key] = row.Row
+
+		existing, err := s.itemRepo.GetByBarcode(ctx, item.Barcode)
+		switch {
+		case err == nil && existing.Archived():
+			// Restoring is a deliberate step; an import must not revive or
+			// edit an archived item behind the user's back.
+			res.ItemID = existing.ID
+			return rowFailed(res, domain.NewConflict("item %d with this barcode is archived; restore it first", existing.ID))
+		case err == nil:
+			return s.importUpdate(ctx, actor, res, existing, item, set, write)
+		case !errors.Is(err, domain.ErrNotFound):
+			return res, err
+		}
+	}
+
+	res.Action = domain.ImportCreate
+	if err := item.Validate(); err != nil {
+		return rowFailed(res, err)
+	}
+	if err := item.ValidateNewBarcode(""); err != nil {
+		return rowFailed(res, err)
+	}
+	if !write {
+		return res, nil
+	}
+	if err := s.CreateItem(ctx, item); err != nil {
+		return rowFailed(res, err)
+	}
+	res.ItemID, res.Barcode = item.ID, item.Barcode
+	return res, nil
+}
+
+func (s *InventoryService) importUpdate(ctx context.Context, actor *domain.User, res domain.ImportRowResult, existing, incoming *domain.Item, set map[string]bool, write bool) (domain.ImportRowResult, error) {
+	res.ItemID, res.Barcode = existing.ID, existing.Barcode
+	updated := *existing
+	if set["name"] {
+		updated.Name = incoming.Name
+	}
+	if set["price"] {
+		updated.Price = incoming.Price
+	}
+	if set["location"] {
+		updated.Location = incoming.Location
+	}
+	if set["halal_status"] {
+		updated.Halal.Status = incoming.Halal.Status
+	}
+	if set["halal_certifying_body"] {
+		updated.Halal.CertifyingBody = incoming.Halal.CertifyingBody
+	}
+	if set["halal_certificate_number"] {
+		updated.Halal.CertificateNumber = incoming.Halal.CertificateNumber
+	}
+	if set["halal_expires_at"] {
+		updated.Halal.ExpiresAt = incoming.Halal.ExpiresAt
+	}
+	if set["unit"] {
+		updated.Unit = incoming.Unit
+	}
+	if set["allow_decimal"] {
+		updated.AllowDecimal = incoming.AllowDecimal
+	}
+	var delta float64
+	if set["quantity"] {
+		delta = domain.RoundQuantity(incoming.Quantity - existing.Quantity)
+		updated.Quantity = incoming.Quantity
+	}
+	if err := updated.Validate(); err != nil {
+		return rowFailed(res, err)
+	}
+	if err := existing.CheckUnitChange(updated.Unit); err != nil {
+		return rowFailed(res, err)
+	}
+
+	fieldsChanged := updated.Name != existing.Name || updated.Price != existing.Price ||
+		updated.Location != existing.Location || updated.Unit != existing.Unit ||
+		updated.AllowDecimal != existing.AllowDecimal || halalChanged(existing.Halal, updated.Halal)
+	if !fieldsChanged && delta == 0 {
+		res.Action = domain.ImportSkip
+		return res, nil
+	}
+	res.Action = domain.ImportUpdate
+	if !write {
+		return res, nil
+	}
+
+	// A savepoint per row, so a failed row does not abort the transaction
+	// when invalid rows are skipped.
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if fieldsChanged {
+			if err := s.itemRepo.Update(ctx, &updated); err != nil {
+				return err
+			}
+			emitItemUpdated(ctx, &updated, existing.Location)
+			reason := fmt.Sprintf("import row %d", res.Row)
+			if err := s.recordPriceEdit(ctx, actor, &updated, existing.Price, reason); err != nil {
+				return err
+			}
+		}
+		if delta == 0 {
+			return nil
+		}
+		_, err := s.ledger.apply(ctx, &domain.StockMovement{
+			ItemID:   existing.ID,
+			Type:     domain.MovementImport,
+			Delta:    delta,
+			Location: updated.Location,
+			UserID:   actor.ID,
+			Note:     fmt.Sprintf("import row %d", res.Row),
+		})
+		return err
+	})
+	if err != nil {
+		return rowFailed(res, err)
+	}
+	return res, nil
+}
+
+func halalChanged(a, b domain.Halal) bool {
+	if a.Status != b.Status || a.CertifyingBody != b.CertifyingBody || a.CertificateNumber != b.CertificateNumber {
+		return true
+	}
+	if a.ExpiresAt == nil || b.ExpiresAt == nil {
+		return a.ExpiresAt != b.ExpiresAt
+	}
+	return !a.ExpiresAt.Equal(*b.ExpiresAt)
+}
+
+// rowFailed marks the row as failed when err is about the row's data.
+// Infrastructure errors are passed on to abort the import.
+func rowFailed(res domain.ImportRowResult, err error) (domain.ImportRowResult, error) {
+	var verr *domain.ValidationError
+	var derr *domain.Error
+	switch {
+	case errors.As(err, &verr):
+		res.Errors = verr.Fields
+	case errors.As(err, &derr):
+		res.Errors = []domain.FieldError{{Field: "row", Code: string(derr.Kind), Message: derr.Message}}
+	default:
+		return res, err
+	}
+	res.Action = domain.ImportError
+	return res, nil
+}
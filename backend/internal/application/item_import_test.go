@@ -0,0 +1,353 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+// importStore keeps items and movements in memory. WithinTx snapshots the
+// store and restores it when fn fails, so nested calls behave like
+// savepoints.
+type importStore struct {
+	items     map[int64]*domain.Item
+	movements []*domain.StockMovement
+	prices    []*domain.PriceChange
+	nextID    int64
+	seq       int64
+	// failMovements makes ledger writes for this item fail, after the item
+	// itself may already have been updated.
+	failMovements int64
+}
+
+func (s *importStore) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	items := make(map[int64]*domain.Item, len(s.items))
+	for id, item := range s.items {
+		copied := *item
+		items[id] = &copied
+	}
+	movements, prices, nextID := slices.Clone(s.movements), slices.Clone(s.prices), s.nextID
+	if err := fn(ctx); err != nil {
+		s.items, s.movements, s.prices, s.nextID = items, movements, prices, nextID
+		return err
+	}
+	return nil
+}
+
+// importItems implements only the item methods an import calls.
+type importItems struct {
+	domain.ItemRepository
+	*importStore
+}
+
+func (r importItems) Create(ctx context.Context, item *domain.Item) error {
+	r.nextID++
+	item.ID, item.Version = r.nextID, 1
+	copied := *item
+	r.items[item.ID] = &copied
+	return nil
+}
+
+func (r importItems) Update(ctx context.Context, item *domain.Item) error {
+	stored, ok := r.items[item.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Version != item.Version {
+		return domain.ErrPreconditionFailed
+	}
+	item.Quantity, item.Version = stored.Quantity, stored.Version+1
+	copied := *item
+	r.items[item.ID] = &copied
+	return nil
+}
+
+func (r importItems) AdjustQuantity(ctx context.Context, id int64, delta float64) (*domain.Item, error) {
+	stored := r.items[id]
+	q := domain.RoundQuantity(stored.Quantity + delta)
+	if q < 0 {
+		return nil, domain.ErrInsufficientStock
+	}
+	stored.Quantity = q
+	copied := *stored
+	return &copied, nil
+}
+
+func (r importItems) SetStockValue(ctx context.Context, id int64, value float64) error {
+	r.items[id].StockValue = value
+	return nil
+}
+
+func (r importItems) GetByID(ctx context.Context, id int64) (*domain.Item, error) {
+	stored, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *stored
+	return &copied, nil
+}
+
+func (r importItems) GetByBarcode(ctx context.Context, barcode string) (*domain.Item, error) {
+	for _, stored := range r.items {
+		if domain.NormalizeGTIN(stored.Barcode) == domain.NormalizeGTIN(barcode) {
+			copied := *stored
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r importItems) NextBarcodeSequence(ctx context.Context) (int64, error) {
+	r.seq++
+	return r.seq, nil
+}
+
+type importMovements struct {
+	domain.MovementRepository
+	*importStore
+}
+
+func (r importMovements) Create(ctx context.Context, m *domain.StockMovement) error {
+	if m.ItemID == r.failMovements {
+		return domain.NewConflict("ledger of item %d is locked", m.ItemID)
+	}
+	r.movements = append(r.movements, m)
+	return nil
+}
+
+type importPrices struct {
+	domain.PriceRepository
+	*importStore
+}
+
+func (r importPrices) Create(ctx context.Context, change *domain.PriceChange) error {
+	r.prices = append(r.prices, change)
+	return nil
+}
+
+// importCategories has no categories; import rows carry no classification.
+type importCategories struct {
+	domain.CategoryRepository
+}
+
+func (importCategories) List(ctx context.Context) ([]*domain.Category, error) {
+	return nil, nil
+}
+
+func newImportService(t *testing.T, store *importStore) *InventoryService {
+	t.Helper()
+	barcodes, err := domain.NewBarcodeGenerator(domain.DefaultBarcodePrefixes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := importItems{importStore: store}
+	ledger := NewStockLedger(items, importMovements{importStore: store}, nil, domain.ValuationAverage)
+	return NewInventoryService(store, items, importPrices{importStore: store}, importCategories{}, nil, nil, nil, ledger, barcodes)
+}
+
+// seedImportStore holds an EAN-13 item, a Code128 item and an archived one.
+func seedImportStore() *importStore {
+	archived := time.Now()
+	unknown := domain.Halal{Status: domain.HalalUnknown}
+	return &importStore{
+		items: map[int64]*domain.Item{
+			1: {ID: 1, Name: "Tea", Barcode: "4006381333931", Price: 2, Quantity: 5, Unit: "pcs", Halal: unknown, Version: 1},
+			2: {ID: 2, Name: "Coffee", Barcode: "CODE-2", Price: 4, Unit: "pcs", Halal: unknown, Version: 1},
+			3: {ID: 3, Name: "Old", Barcode: "OLD-3", Price: 1, Unit: "pcs", Halal: unknown, Version: 1, ArchivedAt: &archived},
+		},
+		nextID: 3,
+	}
+}
+
+func importRow(n int, kv ...string) domain.ImportRow {
+	row := domain.ImportRow{Row: n, Values: make(map[string]string)}
+	for i := 0; i < len(kv); i += 2 {
+		row.Values[kv[i]] = kv[i+1]
+	}
+	return row
+}
+
+func TestImportItems(t *testing.T) {
+	tests := []struct {
+		name          string
+		rows          []domain.ImportRow
+		opts          ImportOptions
+		failMovements int64
+		wantActions   []domain.ImportAction
+		wantCommitted bool
+		wantItems     int              // Stored items afterwards
+		wantNames     map[int64]string // Stored names afterwards
+		wantStock     map[int64]float64
+		wantPrices    int // Price history entries afterwards
+	}{
+		{
+			name: "dry run writes nothing",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Green Tea", "barcode", "4006381333931"),
+				importRow(3, "name", "Milk", "quantity", "3"),
+			},
+			opts:        ImportOptions{DryRun: true},
+			wantActions: []domain.ImportAction{domain.ImportUpdate, domain.ImportCreate},
+			wantItems:   3,
+			wantNames:   map[int64]string{1: "Tea"},
+			wantStock:   map[int64]float64{1: 5},
+		},
+		{
+			name: "commit creates, updates and skips",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Green Tea", "barcode", "04006381333931", "quantity", "8"),
+				importRow(3, "name", "Coffee", "barcode", "CODE-2"),
+				importRow(4, "name", "Milk", "quantity", "3"),
+				importRow(5, "name", "", "barcode", ""),
+			},
+			wantActions:   []domain.ImportAction{domain.ImportUpdate, domain.ImportSkip, domain.ImportCreate},
+			wantCommitted: true,
+			wantItems:     4,
+			wantNames:     map[int64]string{1: "Green Tea", 4: "Milk"},
+			wantStock:     map[int64]float64{1: 8, 4: 3},
+		},
+		{
+			name: "price change is kept in the history",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Tea", "barcode", "4006381333931", "price", "2.5"),
+			},
+			wantActions:   []domain.ImportAction{domain.ImportUpdate},
+			wantCommitted: true,
+			wantItems:     3,
+			wantPrices:    1,
+		},
+		{
+			name: "invalid row rolls back the whole import",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Milk"),
+				importRow(3, "name", "Bad", "price", "-1"),
+				importRow(4, "name", "Tea", "barcode", "4006381333931", "price", "2.5"),
+			},
+			wantActions: []domain.ImportAction{domain.ImportCreate, domain.ImportError, domain.ImportUpdate},
+			wantItems:   3,
+		},
+		{
+			name: "skip invalid commits the valid rows",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Milk"),
+				importRow(3, "name", "Bad", "barcode", "4006381333932"),
+			},
+			opts:          ImportOptions{SkipInvalid: true},
+			wantActions:   []domain.ImportAction{domain.ImportCreate, domain.ImportError},
+			wantCommitted: true,
+			wantItems:     4,
+			wantNames:     map[int64]string{4: "Milk"},
+		},
+		{
+			name: "failed row is rolled back to its savepoint",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Green Tea", "barcode", "4006381333931", "quantity", "9"),
+				importRow(3, "name", "Dark Coffee", "barcode", "CODE-2", "quantity", "1"),
+			},
+			opts:          ImportOptions{SkipInvalid: true},
+			failMovements: 1,
+			wantActions:   []domain.ImportAction{domain.ImportError, domain.ImportUpdate},
+			wantCommitted: true,
+			wantItems:     3,
+			wantNames:     map[int64]string{1: "Tea", 2: "Dark Coffee"},
+			wantStock:     map[int64]float64{1: 5, 2: 1},
+		},
+		{
+			name: "failed write without skip invalid rolls back everything",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Dark Coffee", "barcode", "CODE-2", "quantity", "1"),
+				importRow(3, "name", "Green Tea", "barcode", "4006381333931", "quantity", "9"),
+			},
+			failMovements: 1,
+			wantActions:   []domain.ImportAction{domain.ImportUpdate, domain.ImportError},
+			wantItems:     3,
+			wantNames:     map[int64]string{1: "Tea", 2: "Coffee"},
+			wantStock:     map[int64]float64{1: 5, 2: 0},
+		},
+		{
+			name: "duplicate barcodes within the file",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Juice", "barcode", "96385074"),
+				importRow(3, "name", "Juice 2", "barcode", "0000096385074"),
+			},
+			opts:          ImportOptions{SkipInvalid: true},
+			wantActions:   []domain.ImportAction{domain.ImportCreate, domain.ImportError},
+			wantCommitted: true,
+			wantItems:     4,
+			wantNames:     map[int64]string{4: "Juice"},
+		},
+		{
+			name: "archived item is not updated",
+			rows: []domain.ImportRow{
+				importRow(2, "name", "Revived", "barcode", "OLD-3"),
+			},
+			opts:          ImportOptions{SkipInvalid: true},
+			wantActions:   []domain.ImportAction{domain.ImportError},
+			wantCommitted: true,
+			wantItems:     3,
+			wantNames:     map[int64]string{3: "Old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := seedImportStore()
+			store.failMovements = tt.failMovements
+			svc := newImportService(t, store)
+
+			result, err := svc.ImportItems(context.Background(), &domain.User{ID: "u1"}, tt.rows, tt.opts)
+			if err != nil {
+				t.Fatalf("ImportItems() error = %v", err)
+			}
+			var actions []domain.ImportAction
+			for _, row := range result.Rows {
+				actions = append(actions, row.Action)
+			}
+			if !slices.Equal(actions, tt.wantActions) {
+				t.Errorf("actions = %v, want %v", actions, tt.wantActions)
+			}
+			if result.Committed != tt.wantCommitted || result.DryRun != tt.opts.DryRun {
+				t.Errorf("committed, dry run = %v, %v; want %v, %v", result.Committed, result.DryRun, tt.wantCommitted, tt.opts.DryRun)
+			}
+			if len(store.items) != tt.wantItems {
+				t.Errorf("stored items = %d, want %d", len(store.items), tt.wantItems)
+			}
+			for id, want := range tt.wantNames {
+				if item := store.items[id]; item == nil || item.Name != want {
+					t.Errorf("item %d = %+v, want name %q", id, item, want)
+				}
+			}
+			for id, want := range tt.wantStock {
+				if got := store.items[id].Quantity; got != want {
+					t.Errorf("item %d quantity = %v, want %v", id, got, want)
+				}
+			}
+			if len(store.prices) != tt.wantPrices {
+				t.Errorf("price history entries = %d, want %d", len(store.prices), tt.wantPrices)
+			}
+			// The ledger must always sum to the stored stock.
+			sums := make(map[int64]float64)
+			for _, m := range store.movements {
+				sums[m.ItemID] += m.Delta
+			}
+			for id, item := range store.items {
+				if id > 3 && domain.RoundQuantity(sums[id]) != item.Quantity {
+					t.Errorf("item %d ledger = %v, quantity %v", id, sums[id], item.Quantity)
+				}
+			}
+		})
+	}
+}
+
+func TestImportItemsRowLimit(t *testing.T) {
+	svc := newImportService(t, seedImportStore())
+	rows := make([]domain.ImportRow, domain.MaxImportRows+1)
+	_, err := svc.ImportItems(context.Background(), &domain.User{ID: "u1"}, rows, ImportOptions{})
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "file" {
+		t.Fatalf("ImportItems() error = %v, want a validation error on file", err)
+	}
+}
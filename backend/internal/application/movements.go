@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"multi-inventory/internal/domain"
+)
+
+// StockLedger is the single place where stock quantities change. Every
+// change is appended to the movement ledger and valued at cost with the
+// configured valuation method, so the stock value can be rebuilt for any
+// past date.
+type StockLedger struct {
+	items     domain.ItemRepository
+	movements domain.MovementRepository
+	layers    domain.CostLayerRepository
+	method    domain.ValuationMethod
+}
+
+func NewStockLedger(items domain.ItemRepository, movements domain.MovementRepository, layers domain.CostLayerRepository, method domain.ValuationMethod) *StockLedger {
+	return &StockLedger{items: items, movements: movements, layers: layers, method: method}
+}
+
+func (l *StockLedger) Method() domain.ValuationMethod {
+	return l.method
+}
+
+// apply changes an item's stock by m.Delta and appends m to the ledger.
+// Incoming stock is valued at m.UnitCost, or at the current average cost
+// when it is zero; outgoing stock gets its cost of goods in m.UnitCost.
+// Call it inside a transaction so every write commits together.
+func (l *StockLedger) apply(ctx context.Context, m *domain.StockMovement) (*domain.Item, error) {
+	m.Delta = domain.RoundQuantity(m.Delta)
+	item, err := l.items.AdjustQuantity(ctx, m.ItemID, m.Delta)
+	if err != nil {
+		return nil, err
+	}
+	// AdjustQuantity locked the row, so the value read with it is current.
+	before := domain.RoundQuantity(item.Quantity - m.Delta)
+	value := item.StockValue
+	average := 0.0
+	if before > 0 {
+		average = value / before
+	}
+
+	if m.Delta > 0 {
+		if m.UnitCost <= 0 {
+			m.UnitCost = average
+		}
+		value += m.Delta * m.UnitCost
+	} else {
+		cost, err := l.costOfGoods(ctx, item.ID, -m.Delta, average)
+		if err != nil {
+			return nil, err
+		}
+		m.UnitCost = cost / -m.Delta
+		value -= cost
+	}
+	if item.Quantity == 0 || value < 0 {
+		value = 0 // Drop rounding leftovers once the shelf is empty
+	}
+	m.UnitCost = domain.RoundCost(m.UnitCost)
+	value = domain.RoundCost(value)
+
+	if err := l.items.SetStockValue(ctx, item.ID, value); err != nil {
+		return nil, err
+	}
+	item.StockValue = value
+	item.UnitCost = 0
+	if item.Quantity > 0 {
+		item.UnitCost = domain.RoundCost(value / item.Quantity)
+	}
+
+	m.QuantityAfter = item.Quantity
+	m.ValueAfter = value
+	if err := l.record(ctx, m); err != nil {
+		return nil, err
+	}
+	emit(ctx, domain.EventStockMoved, m, m.Location, item.Location)
+	return item, nil
+}
+
+// opening records the stock an item was created with. The item must have
+// been stored with its Quantity and StockValue already.
+func (l *StockLedger) opening(ctx context.Context, item *domain.Item) error {
+	m := &domain.StockMovement{
+		ItemID:        item.ID,
+		Type:          domain.MovementInitial,
+		Delta:         item.Quantity,
+		QuantityAfter: item.Quantity,
+		UnitCost:      item.UnitCost,
+		ValueAfter:    item.StockValue,
+		Location:      item.Location,
+	}
+	if err := l.record(ctx, m); err != nil {
+		return err
+	}
+	emit(ctx, domain.EventStockMoved, m, m.Location)
+	return nil
+}
+
+// record appends the movement and, under FIFO, opens a cost layer for
+// incoming stock.
+func (l *StockLedger) record(ctx context.Context, m *domain.StockMovement) error {
+	if err := l.movements.Create(ctx, m); err != nil {
+		return err
+	}
+	if l.method != domain.ValuationFIFO || m.Delta <= 0 {
+		return nil
+	}
+	return l.layers.Add(ctx, &domain.CostLayer{
+		ItemID:     m.ItemID,
+		MovementID: m.ID,
+		Quantity:   m.Delta,
+		UnitCost:   m.UnitCost,
+	})
+}
+
+// costOfGoods is the cost of quantity units leaving stock. Under FIFO the
+// oldest layers are drawn first; stock received before layers were kept is
+// costed at the average.
+func (l *StockLedger) costOfGoods(ctx context.Context, itemID int64, quantity, average float64) (float64, error) {
+	if l.method != domain.ValuationFIFO {
+		return quantity * average, nil
+	}
+	covered, cost, err := l.layers.Consume(ctx, itemID, quantity)
+	if err != nil {
+		return 0, err
+	}
+	return cost + domain.RoundQuantity(quantity-covered)*average, nil
+}
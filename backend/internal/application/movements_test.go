@@ -0,0 +1,228 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"multi-inventory/internal/domain"
+)
+
+// ledgerItems keeps the stock of the items in memory. Only the methods the
+// ledger calls are implemented.
+type ledgerItems struct {
+	domain.ItemRepository
+	items map[int64]*domain.Item
+}
+
+func (r *ledgerItems) AdjustQuantity(ctx context.Context, id int64, delta float64) (*domain.Item, error) {
+	item := r.items[id]
+	q := domain.RoundQuantity(item.Quantity + delta)
+	if q < 0 {
+		return nil, domain.ErrInsufficientStock
+	}
+	item.Quantity = q
+	copied := *item
+	return &copied, nil
+}
+
+func (r *ledgerItems) SetStockValue(ctx context.Context, id int64, value float64) error {
+	r.items[id].StockValue = value
+	return nil
+}
+
+type ledgerMovements struct {
+	domain.MovementRepository
+	created []*domain.StockMovement
+}
+
+func (r *ledgerMovements) Create(ctx context.Context, m *domain.StockMovement) error {
+	m.ID = int64(len(r.created) + 1)
+	r.created = append(r.created, m)
+	return nil
+}
+
+// ledgerLayers is a FIFO queue of cost layers.
+type ledgerLayers struct {
+	layers []*domain.CostLayer
+}
+
+func (r *ledgerLayers) Add(ctx context.Context, layer *domain.CostLayer) error {
+	r.layers = append(r.layers, layer)
+	return nil
+}
+
+func (r *ledgerLayers) Consume(ctx context.Context, itemID int64, quantity float64) (float64, float64, error) {
+	covered, cost := 0.0, 0.0
+	for _, l := range r.layers {
+		if covered == quantity {
+			break
+		}
+		if l.ItemID != itemID || l.Quantity == 0 {
+			continue
+		}
+		take := min(l.Quantity, domain.RoundQuantity(quantity-covered))
+		l.Quantity = domain.RoundQuantity(l.Quantity - take)
+		covered = domain.RoundQuantity(covered + take)
+		cost += take * l.UnitCost
+	}
+	return covered, cost, nil
+}
+
+type ledgerStep struct {
+	delta, unitCost float64
+}
+
+func TestStockLedgerApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    domain.ValuationMethod
+		quantity  float64 // Opening stock, kept without cost layers
+		value     float64
+		steps     []ledgerStep
+		wantCosts []float64 // Unit cost booked on each movement
+		wantValue float64
+		wantErr   error
+	}{
+		{
+			name:      "average of two receipts",
+			method:    domain.ValuationAverage,
+			steps:     []ledgerStep{{10, 2}, {10, 4}, {-5, 0}},
+			wantCosts: []float64{2, 4, 3},
+			wantValue: 45,
+		},
+		{
+			name:      "receipt without cost at the average",
+			method:    domain.ValuationAverage,
+			quantity:  4,
+			value:     10,
+			steps:     []ledgerStep{{6, 0}, {-2, 0}},
+			wantCosts: []float64{2.5, 2.5},
+			wantValue: 20,
+		},
+		{
+			name:      "rounding leftovers dropped on the last sale",
+			method:    domain.ValuationAverage,
+			steps:     []ledgerStep{{3, 3.3333}, {-1, 0}, {-1, 0}, {-1, 0}},
+			wantCosts: []float64{3.3333, 3.3333, 3.3333, 3.3333},
+			wantValue: 0,
+		},
+		{
+			name:      "sale beyond the stock",
+			method:    domain.ValuationAverage,
+			quantity:  1,
+			value:     5,
+			steps:     []ledgerStep{{-2, 0}},
+			wantValue: 5,
+			wantErr:   domain.ErrInsufficientStock,
+		},
+		{
+			name:      "fifo draws the oldest layer first",
+			method:    domain.ValuationFIFO,
+			steps:     []ledgerStep{{10, 2}, {10, 4}, {-5, 0}},
+			wantCosts: []float64{2, 4, 2},
+			wantValue: 50,
+		},
+		{
+			name:      "fifo sale across layers",
+			method:    domain.ValuationFIFO,
+			steps:     []ledgerStep{{10, 2}, {10, 4}, {-15, 0}, {-5, 0}},
+			wantCosts: []float64{2, 4, 2.6667, 4},
+			wantValue: 0,
+		},
+		{
+			name:      "fifo stock without layers at the average",
+			method:    domain.ValuationFIFO,
+			quantity:  10,
+			value:     30,
+			steps:     []ledgerStep{{-4, 0}},
+			wantCosts: []float64{3},
+			wantValue: 18,
+		},
+		{
+			name:      "fifo layers drawn before stock without layers",
+			method:    domain.ValuationFIFO,
+			quantity:  5,
+			value:     10,
+			steps:     []ledgerStep{{5, 4}, {-8, 0}},
+			wantCosts: []float64{4, 3.625},
+			wantValue: 1,
+		},
+		{
+			name:      "fifo fractional quantities",
+			method:    domain.ValuationFIFO,
+			steps:     []ledgerStep{{1.5, 2}, {0.5, 6}, {-1.75, 0}},
+			wantCosts: []float64{2, 6, 2.5714},
+			wantValue: 1.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := &ledgerItems{items: map[int64]*domain.Item{
+				1: {ID: 1, Quantity: tt.quantity, StockValue: tt.value},
+			}}
+			movements := &ledgerMovements{}
+			ledger := NewStockLedger(items, movements, &ledgerLayers{}, tt.method)
+
+			var err error
+			for _, s := range tt.steps {
+				m := &domain.StockMovement{ItemID: 1, Delta: s.delta, UnitCost: s.unitCost}
+				if _, err = ledger.apply(context.Background(), m); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(movements.created) != len(tt.wantCosts) {
+				t.Fatalf("recorded %d movements, want %d", len(movements.created), len(tt.wantCosts))
+			}
+			for k, m := range movements.created {
+				if m.UnitCost != tt.wantCosts[k] {
+					t.Errorf("movement %d unit cost = %v, want %v", k, m.UnitCost, tt.wantCosts[k])
+				}
+				if m.ValueAfter < 0 {
+					t.Errorf("movement %d value after = %v", k, m.ValueAfter)
+				}
+			}
+			if got := items.items[1].StockValue; got != tt.wantValue {
+				t.Errorf("stock value = %v, want %v", got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestCostOfGoods(t *testing.T) {
+	layers := func() []*domain.CostLayer {
+		return []*domain.CostLayer{
+			{ItemID: 1, Quantity: 2, UnitCost: 1},
+			{ItemID: 2, Quantity: 5, UnitCost: 9},
+			{ItemID: 1, Quantity: 3, UnitCost: 2},
+		}
+	}
+	tests := []struct {
+		name     string
+		method   domain.ValuationMethod
+		quantity float64
+		average  float64
+		want     float64
+	}{
+		{"average", domain.ValuationAverage, 4, 1.5, 6},
+		{"fifo within the first layer", domain.ValuationFIFO, 1, 7, 1},
+		{"fifo across layers", domain.ValuationFIFO, 4, 7, 6},
+		{"fifo draining the layers", domain.ValuationFIFO, 5, 7, 8},
+		{"fifo beyond the layers at the average", domain.ValuationFIFO, 7, 1.5, 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := NewStockLedger(nil, nil, &ledgerLayers{layers: layers()}, tt.method)
+			got, err := ledger.costOfGoods(context.Background(), 1, tt.quantity, tt.average)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("costOfGoods(%v) = %v, want %v", tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
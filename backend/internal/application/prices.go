@@ -0,0 +1,168 @@
+package application
+
+import (
+	"context"
+	"log"
+	"multi-inventory/internal/domain"
+	"strings"
+	"time"
+)
+
+// priceBatchSize bounds how many scheduled prices one transaction applies.
+const priceBatchSize = 100
+
+// ChangePrice sets a new price for an item. Without an effective time, or
+// with one that has passed, the price changes at once; otherwise it is
+// scheduled and applied by the price scheduler.
+func (s *InventoryService) ChangePrice(ctx context.Context, actor *domain.User, itemID int64, change *domain.PriceChange) error {
+	change.Reason = strings.TrimSpace(change.Reason)
+	if err := change.Validate(); err != nil {
+		return err
+	}
+	change.ItemID = itemID
+	change.ChangedBy = userID(actor)
+
+	now := time.Now()
+	if change.EffectiveAt.After(now) {
+		if _, err := s.itemRepo.GetByID(ctx, itemID); err != nil {
+			return err
+		}
+		change.Status = domain.PriceScheduled
+		change.OldPrice, change.AppliedAt = nil, nil
+		return s.priceRepo.Create(ctx, change)
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.itemRepo.SetPrice(ctx, itemID, change.Price)
+		if err != nil {
+			return err
+		}
+		change.Status = domain.PriceApplied
+		change.OldPrice, change.AppliedAt, change.EffectiveAt = &old, &now, now
+		if err := s.priceRepo.Create(ctx, change); err != nil {
+			return err
+		}
+		return s.emitPriceChanged(ctx, itemID)
+	})
+}
+
+// CancelPriceChange withdraws a scheduled price change.
+func (s *InventoryService) CancelPriceChange(ctx context.Context, itemID, changeID int64) (*domain.PriceChange, error) {
+	change, err := s.priceRepo.GetByID(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+	if change.ItemID != itemID {
+		return nil, domain.NewNotFound("price change not found")
+	}
+	if change.Status != domain.PriceScheduled {
+		return nil, domain.NewConflict("only scheduled price changes can be cancelled")
+	}
+	change.Status = domain.PriceCancelled
+	if err := s.priceRepo.SetStatus(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// PriceHistory returns the price timeline of an item, scheduled changes
+// included, latest first.
+func (s *InventoryService) PriceHistory(ctx context.Context, itemID int64) ([]*domain.PriceChange, error) {
+	if _, err := s.itemRepo.GetByID(ctx, itemID); err != nil {
+		return nil, err
+	}
+	return s.priceRepo.ListByItem(ctx, itemID)
+}
+
+// ApplyDuePrices applies one batch of scheduled prices that are due at the
+// given time and returns how many were applied.
+func (s *InventoryService) ApplyDuePrices(ctx context.Context, at time.Time) (int, error) {
+	var applied int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		due, err := s.priceRepo.Due(ctx, at, priceBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, change := range due {
+			old, err := s.itemRepo.SetPrice(ctx, change.ItemID, change.Price)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			change.Status = domain.PriceApplied
+			change.OldPrice, change.AppliedAt = &old, &now
+			if err := s.priceRepo.SetStatus(ctx, change); err != nil {
+				return err
+			}
+			if err := s.emitPriceChanged(ctx, change.ItemID); err != nil {
+				return err
+			}
+		}
+		applied = len(due)
+		return nil
+	})
+	return applied, err
+}
+
+// emitPriceChanged publishes the item after SetPrice, which only returns
+// the old price.
+func (s *InventoryService) emitPriceChanged(ctx context.Context, itemID int64) error {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	emitItemUpdated(ctx, item, item.Location)
+	return nil
+}
+
+// RunPriceScheduler applies due prices every interval until ctx is done.
+func (s *InventoryService) RunPriceScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.ApplyDuePrices(ctx, time.Now())
+			if err != nil {
+				log.Printf("price scheduler: %v", err)
+				break
+			}
+			if n > 0 {
+				log.Printf("price scheduler: applied %d scheduled prices", n)
+			}
+			if n < priceBatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordPriceEdit adds an applied entry to the timeline when an edit of the
+// item changed its price.
+func (s *InventoryService) recordPriceEdit(ctx context.Context, actor *domain.User, item *domain.Item, old float64, reason string) error {
+	if item.Price == old {
+		return nil
+	}
+	now := time.Now()
+	return s.priceRepo.Create(ctx, &domain.PriceChange{
+		ItemID:      item.ID,
+		OldPrice:    &old,
+		Price:       item.Price,
+		Reason:      strings.TrimSpace(reason),
+		ChangedBy:   userID(actor),
+		Status:      domain.PriceApplied,
+		EffectiveAt: now,
+		AppliedAt:   &now,
+	})
+}
+
+func userID(user *domain.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.ID
+}
@@ -0,0 +1,114 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"multi-inventory/internal/domain"
+	"slices"
+)
+
+func (s *InventoryService) ListProducts(ctx context.Context) ([]*domain.Product, error) {
+	return s.productRepo.List(ctx)
+}
+
+// GetProduct returns the product with its active variants.
+func (s *InventoryService) GetProduct(ctx context.Context, id int64) (*domain.Product, error) {
+	product, err := s.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	product.Variants, err = s.ListItems(ctx, domain.ItemFilter{ProductID: id})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *InventoryService) CreateProduct(ctx context.Context, product *domain.Product) error {
+	if err := product.Validate(); err != nil {
+		return err
+	}
+	return s.productRepo.Create(ctx, product)
+}
+
+// UpdateProduct renames the product. Its attributes can only change while it
+// has no variants, so existing variants never miss a value.
+func (s *InventoryService) UpdateProduct(ctx context.Context, product *domain.Product) (*domain.Product, error) {
+	if err := product.Validate(); err != nil {
+		return nil, err
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Locked so no variant is added while the attributes change.
+		if err := s.productRepo.Lock(ctx, product.ID); err != nil {
+			return err
+		}
+		current, err := s.productRepo.GetByID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(current.Attributes, product.Attributes) {
+			variants, err := s.itemRepo.List(ctx, domain.ItemFilter{ProductID: product.ID, Archived: domain.ArchiveInclude})
+			if err != nil {
+				return err
+			}
+			if len(variants) > 0 {
+				return domain.NewConflict("attributes cannot change while the product has variants")
+			}
+		}
+		return s.productRepo.Update(ctx, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.productRepo.GetByID(ctx, product.ID)
+}
+
+// DeleteProduct only deletes products without variants.
+func (s *InventoryService) DeleteProduct(ctx context.Context, id int64) error {
+	return s.productRepo.Delete(ctx, id)
+}
+
+// checkVariant normalizes the variant attributes of an item and validates
+// them against its product.
+func (s *InventoryService) checkVariant(ctx context.Context, item *domain.Item) error {
+	item.Attributes = domain.NormalizeAttributes(item.Attributes)
+	verr := &domain.ValidationError{}
+	if item.ProductID == nil {
+		if len(item.Attributes) > 0 {
+			verr.Add("attributes", domain.CodeInvalid, "attributes need a product_id")
+		}
+		return verr.Err()
+	}
+
+	product, err := s.productRepo.GetByID(ctx, *item.ProductID)
+	if errors.Is(err, domain.ErrNotFound) {
+		verr.Add("product_id", domain.CodeInvalid, "product does not exist")
+		return verr
+	}
+	if err != nil {
+		return err
+	}
+	return product.CheckVariant(item.Attributes)
+}
+
+// checkSiblings makes sure no two variants of a product share the same
+// values. It must run in the transaction that saves the item: the product
+// stays locked until then, so concurrent saves cannot both pass.
+func (s *InventoryService) checkSiblings(ctx context.Context, item *domain.Item) error {
+	if item.ProductID == nil {
+		return nil
+	}
+	if err := s.productRepo.Lock(ctx, *item.ProductID); err != nil {
+		return err
+	}
+	siblings, err := s.itemRepo.List(ctx, domain.ItemFilter{ProductID: *item.ProductID, Archived: domain.ArchiveInclude})
+	if err != nil {
+		return err
+	}
+	for _, sibling := range siblings {
+		if sibling.ID != item.ID && domain.SameVariant(sibling.Attributes, item.Attributes) {
+			return domain.NewConflict("the product already has this variant: %s", sibling.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"multi-inventory/internal/domain"
+	"time"
+)
+
+// ReportService builds dashboards and reports from database aggregates.
+type ReportService struct {
+	reportRepo        domain.ReportRepository
+	lowStockThreshold int
+	valuation         domain.ValuationMethod
+	zone              *time.Location
+	now               func() time.Time
+}
+
+// NewReportService counts days, weeks and months in zone, which must match
+// the time zone of the database sessions.
+func NewReportService(reportRepo domain.ReportRepository, lowStockThreshold int, valuation domain.ValuationMethod, zone *time.Location) *ReportService {
+	return &ReportService{reportRepo: reportRepo, lowStockThreshold: lowStockThreshold, valuation: valuation, zone: zone, now: time.Now}
+}
+
+// Dashboard computes the overview, optionally for one location. Periods
+// start at midnight in the report time zone, on Monday for the week and on
+// the 1st for the month.
+func (s *ReportService) Dashboard(ctx context.Context, location string) (*domain.Dashboard, error) {
+	now := s.now().In(s.zone)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	week := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	totals, err := s.reportRepo.SalesTotals(ctx, location,
+		domain.DateRange{From: today},
+		domain.DateRange{From: week},
+		domain.DateRange{From: month},
+	)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.reportRepo.PendingOrders(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	stock, err := s.reportRepo.StockSummary(ctx, location, s.lowStockThreshold)
+	if err != nil {
+		return nil, err
+	}
+	top, err := s.reportRepo.TopSellers(ctx, domain.ReportFilter{Period: domain.DateRange{From: month}, Location: location}, domain.RankByQuantity, 5)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := s.reportRepo.HalalAlerts(ctx, now, location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Dashboard{
+		Location:             location,
+		Today:                totals[0],
+		Week:                 totals[1],
+		Month:                totals[2],
+		PendingOrders:        pending,
+		LowStockItems:        stock.LowStockItems,
+		LowStockThreshold:    s.lowStockThreshold,
+		InventoryValueCost:   stock.CostValue,
+		InventoryValueRetail: stock.RetailValue,
+		HalalExpiredItems:    len(expired),
+		TopSellers:           top,
+		GeneratedAt:          now,
+	}, nil
+}
+
+// defaultPeriod fills a missing bound: the period ends at the start of
+// tomorrow and spans 30 days.
+func (s *ReportService) defaultPeriod(period domain.DateRange) domain.DateRange {
+	if period.To.IsZero() {
+		now := s.now().In(s.zone)
+		period.To = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	}
+	if period.From.IsZero() {
+		period.From = period.To.AddDate(0, 0, -30)
+	}
+	return period
+}
+
+// SalesSeries buckets sales over the period, the last 30 days by default.
+func (s *ReportService) SalesSeries(ctx context.Context, filter domain.ReportFilter, interval domain.Interval) ([]domain.SalesBucket, error) {
+	filter.Period = s.defaultPeriod(filter.Period)
+	if err := filter.Period.Validate(); err != nil {
+		return nil, err
+	}
+	if interval.Buckets(filter.Period) > domain.MaxSeriesBuckets {
+		verr := &domain.ValidationError{}
+		verr.Add("interval", domain.CodeInvalid, fmt.Sprintf("the period spans more than %d buckets, use a larger interval", domain.MaxSeriesBuckets))
+		return nil, verr
+	}
+	return s.reportRepo.SalesSeries(ctx, filter, interval)
+}
+
+// TopSellers ranks items over the period, the last 30 days by default.
+func (s *ReportService) TopSellers(ctx context.Context, filter domain.ReportFilter, by domain.RankBy, limit int) ([]domain.TopSeller, error) {
+	filter.Period = s.defaultPeriod(filter.Period)
+	if err := filter.Period.Validate(); err != nil {
+		return nil, err
+	}
+	return s.reportRepo.TopSellers(ctx, filter, by, limit)
+}
+
+// DeadStock lists items in stock that have not sold for the given days.
+func (s *ReportService) DeadStock(ctx context.Context, days int, location string) ([]domain.DeadStockItem, error) {
+	since := s.now().AddDate(0, 0, -days)
+	return s.reportRepo.DeadStock(ctx, since, location)
+}
+
+// SellThrough reports the sell-through rate per item over the period, the
+// last 30 days by default.
+func (s *ReportService) SellThrough(ctx context.Context, filter domain.ReportFilter) ([]domain.SellThroughItem, error) {
+	filter.Period = s.defaultPeriod(filter.Period)
+	if err := filter.Period.Validate(); err != nil {
+		return nil, err
+	}
+	return s.reportRepo.SellThrough(ctx, filter)
+}
+
+// Margins reports revenue, cost of goods sold and gross margin per item over
+// the period, the last 30 days by default.
+func (s *ReportService) Margins(ctx context.Context, filter domain.ReportFilter) ([]domain.ItemMargin, error) {
+	filter.Period = s.defaultPeriod(filter.Period)
+	if err := filter.Period.Validate(); err != nil {
+		return nil, err
+	}
+	return s.reportRepo.Margins(ctx, filter)
+}
+
+// Valuation values the stock on hand at the given time, now when zero, from
+// the value recorded with each item's last movement before it.
+func (s *ReportService) Valuation(ctx context.Context, at time.Time, location string) (*domain.Valuation, error) {
+	if at.IsZero() {
+		at = s.now()
+	}
+	items, err := s.reportRepo.ValuationAt(ctx, at, location)
+	if err != nil {
+		return nil, err
+	}
+	valuation := &domain.Valuation{At: at, Method: s.valuation, Items: items}
+	for _, item := range items {
+		valuation.TotalQuantity += item.Quantity
+		valuation.TotalValue += item.Value
+	}
+	valuation.TotalQuantity = domain.RoundQuantity(valuation.TotalQuantity)
+	valuation.TotalValue = domain.RoundCost(valuation.TotalValue)
+	return valuation, nil
+}
+
+// HalalAlerts lists certified items whose certificate has expired or
+// expires within the given number of days.
+func (s *ReportService) HalalAlerts(ctx context.Context, days int, location string) ([]domain.HalalAlert, error) {
+	if days < 0 || days > domain.MaxHalalAlertDays {
+		verr := &domain.ValidationError{}
+		verr.Add("days", domain.CodeInvalid, fmt.Sprintf("days must be between 0 and %d", domain.MaxHalalAlertDays))
+		return nil, verr
+	}
+	now := s.now()
+	alerts, err := s.reportRepo.HalalAlerts(ctx, now.AddDate(0, 0, days), location)
+	if err != nil {
+		return nil, err
+	}
+	for i := range alerts {
+		alerts[i].DaysLeft = int(math.Floor(alerts[i].ExpiresAt.Sub(now).Hours() / 24))
+	}
+	return alerts, nil
+}
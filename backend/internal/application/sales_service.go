@@ -4,70 +4,137 @@ import (
 	"context"
 	"fmt"
 	"multi-inventory/internal/domain"
+	"strings"
 )
 
 type SalesService struct {
+	tx        domain.Transactor
 	orderRepo domain.OrderRepository
 	itemRepo  domain.ItemRepository
+	ledger    *StockLedger
+	store     domain.Store
 }
 
-func NewSalesService(orderRepo domain.OrderRepository, itemRepo domain.ItemRepository) *SalesService {
+func NewSalesService(tx domain.Transactor, orderRepo domain.OrderRepository, itemRepo domain.ItemRepository, ledger *StockLedger, store domain.Store) *SalesService {
 	return &SalesService{
+		tx:        tx,
 		orderRepo: orderRepo,
 		itemRepo:  itemRepo,
+		ledger:    ledger,
+		store:     store,
 	}
 }
 
-func (s *SalesService) CreateOrder(ctx context.Context, userID string, items []struct {
-	ItemID   int64 `json:"item_id"`
-	Quantity int   `json:"quantity"`
-}) (*domain.SalesOrder, error) {
-	order := &domain.SalesOrder{
-		UserID: userID,
-		Status: "pending",
-		Items:  make([]*domain.SalesOrderItem, 0, len(items)),
+// OrderOptions control how CreateOrder checks an order.
+type OrderOptions struct {
+	// AllowMixedHalal lets halal and non-halal items share the order; the
+	// order is then marked with HalalOverride.
+	AllowMixedHalal bool
+}
+
+func (s *SalesService) CreateOrder(ctx context.Context, userID string, items []domain.OrderLine, opts OrderOptions) (*domain.SalesOrder, error) {
+	if err := domain.ValidateOrderLines(items); err != nil {
+		return nil, err
 	}
 
-	var totalPrice float64
+	order := &domain.SalesOrder{
+		UserID:    userID,
+		StoreCode: s.store.Code,
+		Status:    domain.OrderPending,
+		Items:     make([]*domain.SalesOrderItem, 0, len(items)),
+	}
 
-	for _, reqItem := range items {
-		// Fetch item to get price and check availability
-		item, err := s.itemRepo.GetByID(ctx, reqItem.ItemID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get item %d: %w", reqItem.ItemID, err)
-		}
-		if item == nil {
-			return nil, fmt.Errorf("item %d not found", reqItem.ItemID)
+	// The order and every stock decrement commit together, so a failed line
+	// leaves no partial order and no missing stock behind.
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var totalPrice float64
+		lineItems := make([]*domain.Item, 0, len(items))
+		for i, reqItem := range items {
+			item, unit, err := s.lineItem(ctx, reqItem)
+			if err != nil {
+				return err
+			}
+			if item.Archived() {
+				return domain.NewConflict("item %s is archived and cannot be sold", item.Name)
+			}
+			quantity, err := item.ToBase(fmt.Sprintf("items[%d].quantity", i), reqItem.Quantity, unit)
+			if err != nil {
+				return err
+			}
+			lineItems = append(lineItems, item)
+			order.Items = append(order.Items, &domain.SalesOrderItem{
+				ItemID:      item.ID,
+				ItemName:    item.Name,
+				Quantity:    quantity,
+				PriceAtSale: item.Price,
+				IsFulfilled: false,
+			})
+			totalPrice += item.Price * quantity
 		}
-		if item.Quantity < reqItem.Quantity {
-			return nil, fmt.Errorf("insufficient quantity for item %s", item.Name)
+		order.TotalPrice = roundMoney(totalPrice)
+		if err := domain.CheckHalalMix(lineItems); err != nil {
+			if !opts.AllowMixedHalal {
+				return err
+			}
+			order.HalalOverride = true
 		}
 
-		// Update item quantity (simple approach, should be transactional ideally)
-		item.Quantity -= reqItem.Quantity
-		if err := s.itemRepo.Update(ctx, item); err != nil {
-			return nil, fmt.Errorf("failed to update inventory for item %s: %w", item.Name, err)
+		if err := s.orderRepo.Create(ctx, order); err != nil {
+			return err
 		}
+		order.TotalPrice = totalPrice
 
-		orderItem := &domain.SalesOrderItem{
-			ItemID:      item.ID,
-			Quantity:    reqItem.Quantity,
-			PriceAtSale: item.Price,
-			IsFulfilled: false,
+		// The conditional decrement cannot oversell even when two orders
+		// race for the last units.
+		for _, line := range order.Items {
+			m := &domain.StockMovement{
+				ItemID:    line.ItemID,
+				Type:      domain.MovementSale,
+				Delta:     -line.Quantity,
+				Reference: fmt.Sprintf("order:%d", order.ID),
+				UserID:    userID,
+			}
+			if _, err := s.ledger.apply(ctx, m); err != nil {
+				return err
+			}
+			cost := m.UnitCost
+			line.CostAtSale = &cost
 		}
-		order.Items = append(order.Items, orderItem)
-		totalPrice += item.Price * float64(reqItem.Quantity)
-	}
-
-	order.TotalPrice = totalPrice
-
-	if err := s.orderRepo.Create(ctx, order); err != nil {
+		if err := s.orderRepo.SetLineCosts(ctx, order.Items); err != nil {
+			return err
+		}
+		emit(ctx, domain.EventOrderCreated, order, orderLocations(lineItems)...)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return order, nil
 }
 
+// lineItem finds the item of an order line and the unit its quantity is
+// in. A barcode wins over the ID, so scanning a variant sells exactly that
+// variant and scanning a carton sells a carton.
+func (s *SalesService) lineItem(ctx context.Context, line domain.OrderLine) (*domain.Item, string, error) {
+	if code := strings.TrimSpace(line.Barcode); code != "" {
+		item, err := s.itemRepo.GetByBarcode(ctx, code)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get item with barcode %s: %w", code, err)
+		}
+		unit := line.Unit
+		if unit == "" {
+			unit = item.UnitForBarcode(code)
+		}
+		return item, unit, nil
+	}
+	item, err := s.itemRepo.GetByID(ctx, line.ItemID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get item %d: %w", line.ItemID, err)
+	}
+	return item, line.Unit, nil
+}
+
 func (s *SalesService) ListOrders(ctx context.Context) ([]*domain.SalesOrder, error) {
 	return s.orderRepo.List(ctx)
 }
@@ -76,6 +143,75 @@ func (s *SalesService) GetOrder(ctx context.Context, id int64) (*domain.SalesOrd
 	return s.orderRepo.GetByID(ctx, id)
 }
 
-func (s *SalesService) UpdateItemFulfillment(ctx context.Context, itemId int64, isFulfilled bool) error {
-	return s.orderRepo.UpdateItemFulfillment(ctx, itemId, isFulfilled)
+// GetReceipt returns the order together with the store details to print on it.
+func (s *SalesService) GetReceipt(ctx context.Context, id int64) (*domain.Receipt, error) {
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Receipt{Store: s.store, Order: order}, nil
+}
+
+// UpdateItemFulfillment toggles an order line and returns the order's new
+// version. expectedVersion is optional (0 skips the check). Fulfilling the
+// last open line completes the order; unfulfilling a line of a completed
+// order reopens it.
+func (s *SalesService) UpdateItemFulfillment(ctx context.Context, itemId int64, isFulfilled bool, expectedVersion int64) (int64, error) {
+	var version int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		change, err := s.orderRepo.UpdateItemFulfillment(ctx, itemId, isFulfilled, expectedVersion)
+		if err != nil {
+			return err
+		}
+		item, err := s.itemRepo.GetByID(ctx, change.ItemID)
+		if err != nil {
+			return err
+		}
+		emit(ctx, domain.EventOrderFulfillment, change, item.Location)
+		version = change.Version
+
+		order, err := s.orderRepo.GetByID(ctx, change.OrderID)
+		if err != nil {
+			return err
+		}
+		status := order.Status
+		switch {
+		case order.Status == domain.OrderPending && order.Fulfilled():
+			status = domain.OrderCompleted
+		case order.Status == domain.OrderCompleted && !order.Fulfilled():
+			status = domain.OrderPending
+		}
+		if status == order.Status {
+			return nil
+		}
+		if version, err = s.orderRepo.UpdateStatus(ctx, order.ID, status, version); err != nil {
+			return err
+		}
+		if status == domain.OrderCompleted {
+			order.Status, order.Version = status, version
+			emit(ctx, domain.EventOrderCompleted, order, s.lineLocations(ctx, order)...)
+		}
+		return nil
+	})
+	return version, err
+}
+
+// lineLocations lists the locations of the items on a stored order.
+func (s *SalesService) lineLocations(ctx context.Context, order *domain.SalesOrder) []string {
+	var locations []string
+	for _, line := range order.Items {
+		if item, err := s.itemRepo.GetByID(ctx, line.ItemID); err == nil {
+			locations = append(locations, item.Location)
+		}
+	}
+	return locations
+}
+
+// orderLocations lists the locations of the items on an order.
+func orderLocations(items []*domain.Item) []string {
+	locations := make([]string, 0, len(items))
+	for _, item := range items {
+		locations = append(locations, item.Location)
+	}
+	return locations
 }
@@ -0,0 +1,294 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+// SyncService lets devices work offline. They pull the items and orders
+// changed since their cursor and push the sales, counts and adjustments
+// they queued meanwhile. Each pushed op goes through the same services as
+// the REST API, so it is checked against the stock as it is when the op
+// arrives, not as the device saw it.
+type SyncService struct {
+	tx           domain.Transactor
+	repo         domain.SyncRepository
+	itemRepo     domain.ItemRepository
+	orderRepo    domain.OrderRepository
+	movementRepo domain.MovementRepository
+	sales        *SalesService
+	adjustments  *AdjustmentService
+	idempotency  *IdempotencyService
+}
+
+func NewSyncService(tx domain.Transactor, repo domain.SyncRepository, itemRepo domain.ItemRepository, orderRepo domain.OrderRepository, movementRepo domain.MovementRepository, sales *SalesService, adjustments *AdjustmentService, idempotency *IdempotencyService) *SyncService {
+	return &SyncService{
+		tx:           tx,
+		repo:         repo,
+		itemRepo:     itemRepo,
+		orderRepo:    orderRepo,
+		movementRepo: movementRepo,
+		sales:        sales,
+		adjustments:  adjustments,
+		idempotency:  idempotency,
+	}
+}
+
+// Pull returns up to limit changes after the cursor. A row changed several
+// times appears once, as it is now; a row changed again after being pulled
+// appears again in a later pull.
+func (s *SyncService) Pull(ctx context.Context, after domain.SyncCursor, limit int) (*domain.SyncPage, error) {
+	horizon, err := s.repo.Horizon(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.repo.Changes(ctx, after, horizon, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &domain.SyncPage{
+		Items:   []*domain.Item{},
+		Orders:  []*domain.SalesOrder{},
+		Deleted: []domain.SyncDeletion{},
+	}
+	if page.HasMore = len(changes) > limit; page.HasMore {
+		changes = changes[:limit]
+	}
+
+	next := after
+	var itemIDs []int64
+	for _, ch := range changes {
+		next = domain.SyncCursor{Seq: ch.Seq, Entity: ch.Entity, ID: ch.ID}
+		switch {
+		case ch.Deleted:
+			page.Deleted = append(page.Deleted, domain.SyncDeletion{Entity: ch.Entity, ID: ch.ID})
+		case ch.Entity == domain.SyncItem:
+			itemIDs = append(itemIDs, ch.ID)
+		case ch.Entity == domain.SyncOrder:
+			order, err := s.orderRepo.GetByID(ctx, ch.ID)
+			if errors.Is(err, domain.ErrNotFound) {
+				continue // Deleted since; its tombstone follows
+			}
+			if err != nil {
+				return nil, err
+			}
+			page.Orders = append(page.Orders, order)
+		}
+	}
+	if len(itemIDs) > 0 {
+		items, err := s.itemRepo.List(ctx, domain.ItemFilter{Archived: domain.ArchiveInclude, IDs: itemIDs})
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, items...)
+	}
+	// Every change below the horizon has been handed out, so the next pull
+	// can start there.
+	if !page.HasMore && next.Seq < horizon {
+		next = domain.SyncCursor{Seq: horizon}
+	}
+	page.Cursor = next.String()
+	return page, nil
+}
+
+// Push applies the ops in order and reports each. A rejected op does not
+// stop the ones after it. An op pushed again with the same ID is not
+// applied twice; its first result is returned instead.
+func (s *SyncService) Push(ctx context.Context, actor *domain.User, ops []domain.SyncOp) ([]domain.SyncOpResult, error) {
+	if len(ops) > domain.MaxSyncOps {
+		verr := &domain.ValidationError{}
+		verr.Add("ops", domain.CodeTooLong, fmt.Sprintf("at most %d ops per push", domain.MaxSyncOps))
+		return nil, verr
+	}
+	results := make([]domain.SyncOpResult, len(ops))
+	for i := range ops {
+		results[i] = s.push(ctx, actor, &ops[i])
+	}
+	return results, nil
+}
+
+func (s *SyncService) push(ctx context.Context, actor *domain.User, op *domain.SyncOp) domain.SyncOpResult {
+	if err := op.Validate(); err != nil {
+		return opResult(op.ID, err)
+	}
+	payload, err := json.Marshal(op)
+	if err != nil {
+		return opResult(op.ID, err)
+	}
+	hash := sha256.Sum256(payload)
+	reserved, replay, err := s.idempotency.Begin(ctx, "sync:"+actor.ID, op.ID, hex.EncodeToString(hash[:]))
+	if errors.Is(err, errKeyInProgress) {
+		// Another push is applying the op; its result is replayed when the
+		// device pushes again.
+		return domain.SyncOpResult{OpID: op.ID, Status: domain.SyncFailed, Error: &domain.OpError{
+			Code: string(domain.KindConflict), Message: "op is being applied by another push",
+		}}
+	}
+	if err != nil {
+		return opResult(op.ID, err)
+	}
+	if replay != nil {
+		var result domain.SyncOpResult
+		if err := json.Unmarshal(replay.ResponseBody, &result); err != nil {
+			return opResult(op.ID, fmt.Errorf("failed to decode stored result: %w", err))
+		}
+		result.Replayed = true
+		return result
+	}
+
+	result := s.apply(ctx, actor, op)
+	status := http.StatusOK
+	switch result.Status {
+	case domain.SyncRejected:
+		status = http.StatusUnprocessableEntity
+	case domain.SyncFailed:
+		status = http.StatusInternalServerError // Released, so the op can run again
+	}
+	body, err := json.Marshal(result)
+	if err == nil {
+		err = s.idempotency.Finish(context.WithoutCancel(ctx), reserved, status, nil, body)
+	}
+	if err != nil {
+		log.Printf("sync: failed to store result of op %s: %v", op.ID, err)
+	}
+	return result
+}
+
+func (s *SyncService) apply(ctx context.Context, actor *domain.User, op *domain.SyncOp) domain.SyncOpResult {
+	switch op.Type {
+	case domain.SyncOpSale:
+		order, err := s.sales.CreateOrder(ctx, actor.ID, op.Items, OrderOptions{AllowMixedHalal: op.AllowMixedHalal})
+		if err != nil {
+			return opResult(op.ID, err)
+		}
+		return domain.SyncOpResult{OpID: op.ID, Status: domain.SyncApplied, Order: order}
+	case domain.SyncOpCount:
+		return s.count(ctx, actor, op)
+	default:
+		return s.adjust(ctx, actor, op)
+	}
+}
+
+// adjust books an adjustment op through the adjustment policy, so large
+// ones wait for approval like any other.
+func (s *SyncService) adjust(ctx context.Context, actor *domain.User, op *domain.SyncOp) domain.SyncOpResult {
+	var adj *domain.StockAdjustment
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, unit, err := s.opItem(ctx, op)
+		if err != nil {
+			return err
+		}
+		delta, err := item.ToBase("delta", op.Delta, unit)
+		if err != nil {
+			return err
+		}
+		adj = &domain.StockAdjustment{ItemID: item.ID, Delta: delta, Reason: op.Reason, Note: op.Note, Location: op.Location}
+		return s.adjustments.RequestAdjustment(ctx, actor, adj)
+	})
+	if err != nil {
+		return opResult(op.ID, err)
+	}
+	return adjustmentResult(op.ID, adj)
+}
+
+// count books the difference between the counted quantity and the stock
+// at the time of the count. Movements booked after the count, such as
+// sales synced from other devices, are kept; if they leave too little
+// stock for the correction the op is rejected.
+func (s *SyncService) count(ctx context.Context, actor *domain.User, op *domain.SyncOp) domain.SyncOpResult {
+	at := op.At
+	if now := time.Now(); at.IsZero() || at.After(now) {
+		at = now
+	}
+	reason := op.Reason
+	if reason == "" {
+		reason = domain.DefaultCountReason
+	}
+	var adj *domain.StockAdjustment
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, unit, err := s.opItem(ctx, op)
+		if err != nil {
+			return err
+		}
+		counted, err := item.ToBase("quantity", op.Quantity, unit)
+		if err != nil {
+			return err
+		}
+		before, err := s.movementRepo.QuantityAt(ctx, item.ID, at)
+		if err != nil {
+			return err
+		}
+		delta := domain.RoundQuantity(counted - before)
+		if delta == 0 {
+			return nil // The count confirms the stock
+		}
+		note := fmt.Sprintf("count of %g at %s", counted, at.UTC().Format(time.RFC3339))
+		if op.Note != "" {
+			note += ": " + op.Note
+		}
+		adj = &domain.StockAdjustment{ItemID: item.ID, Delta: delta, Reason: reason, Note: note, Location: op.Location}
+		return s.adjustments.RequestAdjustment(ctx, actor, adj)
+	})
+	if err != nil {
+		return opResult(op.ID, err)
+	}
+	if adj == nil {
+		return domain.SyncOpResult{OpID: op.ID, Status: domain.SyncApplied}
+	}
+	return adjustmentResult(op.ID, adj)
+}
+
+// opItem finds the item of a count or adjustment and the unit its quantity
+// is in, like receipts do.
+func (s *SyncService) opItem(ctx context.Context, op *domain.SyncOp) (*domain.Item, string, error) {
+	if op.Barcode == "" {
+		item, err := s.itemRepo.GetByID(ctx, op.ItemID)
+		return item, op.Unit, err
+	}
+	item, err := s.itemRepo.GetByBarcode(ctx, op.Barcode)
+	if err != nil {
+		return nil, "", err
+	}
+	if op.ItemID != 0 && item.ID != op.ItemID {
+		verr := &domain.ValidationError{}
+		verr.Add("barcode", domain.CodeInvalid, fmt.Sprintf("barcode belongs to %s", item.Name))
+		return nil, "", verr
+	}
+	unit := op.Unit
+	if unit == "" {
+		unit = item.UnitForBarcode(op.Barcode)
+	}
+	return item, unit, nil
+}
+
+func adjustmentResult(opID string, adj *domain.StockAdjustment) domain.SyncOpResult {
+	status := domain.SyncApplied
+	if adj.Status == domain.AdjustmentPending {
+		status = domain.SyncPending
+	}
+	return domain.SyncOpResult{OpID: opID, Status: status, Adjustment: adj}
+}
+
+// opResult reports a failed op. Domain errors reject it for good; anything
+// else is a server problem and the op may be pushed again.
+func opResult(opID string, err error) domain.SyncOpResult {
+	result := domain.SyncOpResult{OpID: opID, Status: domain.SyncRejected, Error: domain.NewOpError(err)}
+	if result.Error == nil {
+		log.Printf("sync: op %s: %v", opID, err)
+		result.Status = domain.SyncFailed
+		result.Error = internalOpError
+	}
+	return result
+}
+
+// internalOpError stands in for errors that are not domain errors.
+var internalOpError = &domain.OpError{Code: "internal_error", Message: "internal server error"}
@@ -0,0 +1,274 @@
+package application
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+// Dispatcher limits. A claimed delivery is not picked up again for
+// webhookLease, which must outlast a send including its timeout.
+const (
+	outboxBatchSize    = 100
+	deliveryBatchSize  = 50
+	webhookConcurrency = 8
+	webhookLease       = 2 * time.Minute
+	maxWebhookErrorLen = 500
+)
+
+// WebhookService manages webhooks and delivers the events recorded in the
+// outbox to them. Delivery is at least once: receivers should use the
+// event ID to drop duplicates.
+type WebhookService struct {
+	tx          domain.Transactor
+	repo        domain.WebhookRepository
+	outbox      domain.OutboxRepository
+	sender      domain.WebhookSender
+	maxAttempts int
+}
+
+func NewWebhookService(tx domain.Transactor, repo domain.WebhookRepository, outbox domain.OutboxRepository, sender domain.WebhookSender, maxAttempts int) *WebhookService {
+	return &WebhookService{tx: tx, repo: repo, outbox: outbox, sender: sender, maxAttempts: maxAttempts}
+}
+
+// newWebhookSecret returns a random signing secret.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// CreateWebhook registers w. Without a secret one is generated; either way
+// it is returned in w this once.
+func (s *WebhookService) CreateWebhook(ctx context.Context, w *domain.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	if w.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return err
+		}
+		w.Secret = secret
+	}
+	return s.repo.Create(ctx, w)
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id int64) (*domain.Webhook, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateWebhook replaces the webhook's settings. A non-empty secret
+// replaces the stored one; otherwise it is kept.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, w *domain.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	secret := w.Secret
+	if err := s.repo.Update(ctx, w); err != nil {
+		return err
+	}
+	w.Secret = secret
+	return nil
+}
+
+// RotateSecret replaces the webhook's secret with a generated one and
+// returns the webhook with it. Deliveries already claimed may still go out
+// signed with the old secret.
+func (s *WebhookService) RotateSecret(ctx context.Context, id int64) (*domain.Webhook, error) {
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.Secret, err = newWebhookSecret(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	return s.repo.ListDeliveries(ctx, filter)
+}
+
+func (s *WebhookService) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	return s.repo.GetDelivery(ctx, id)
+}
+
+// RetryDelivery queues a dead delivery again with a fresh set of attempts.
+func (s *WebhookService) RetryDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	return s.repo.Requeue(ctx, id)
+}
+
+// FanOut queues one batch of outbox entries for the active webhooks whose
+// filter matches and returns how many entries it handled. Entries stay
+// locked until the transaction ends, so several dispatchers can run.
+func (s *WebhookService) FanOut(ctx context.Context) (int, error) {
+	var handled int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		entries, err := s.outbox.Pending(ctx, outboxBatchSize)
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+		hooks, err := s.repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		ids := make([]int64, len(entries))
+		for i, entry := range entries {
+			var targets []int64
+			for _, w := range hooks {
+				if w.Active && w.Filter().Matches(entry.Event) {
+					targets = append(targets, w.ID)
+				}
+			}
+			if err := s.repo.CreateDeliveries(ctx, entry, targets); err != nil {
+				return err
+			}
+			ids[i] = entry.ID
+		}
+		handled = len(entries)
+		return s.outbox.MarkDispatched(ctx, ids)
+	})
+	return handled, err
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many it
+// attempted.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.ClaimDeliveries(ctx, webhookLease, deliveryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, webhookConcurrency)
+	for _, d := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			s.deliver(ctx, d)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// deliver makes one attempt and records its outcome. Failures are retried
+// with backoff until the attempts run out and the delivery is dead.
+func (s *WebhookService) deliver(ctx context.Context, d *domain.WebhookDelivery) {
+	start := time.Now()
+	status, err := s.sender.Send(ctx, d)
+	now := time.Now()
+	d.Attempts++
+	attempt := domain.WebhookAttempt{
+		Attempt:    d.Attempts,
+		StatusCode: status,
+		DurationMS: now.Sub(start).Milliseconds(),
+		At:         now,
+	}
+	switch {
+	case err == nil:
+		d.Status, d.NextAttemptAt, d.DeliveredAt, d.LastError = domain.DeliveryDelivered, nil, &now, ""
+	case d.Attempts >= s.maxAttempts:
+		attempt.Error = truncate(err.Error(), maxWebhookErrorLen)
+		d.Status, d.NextAttemptAt, d.LastError = domain.DeliveryDead, nil, attempt.Error
+	default:
+		attempt.Error = truncate(err.Error(), maxWebhookErrorLen)
+		// Jitter spreads the retries of deliveries that failed together.
+		wait := domain.WebhookBackoff(d.Attempts)
+		next := now.Add(wait + rand.N(wait/10+1))
+		d.NextAttemptAt, d.LastError = &next, attempt.Error
+	}
+	if err := s.repo.RecordAttempt(ctx, d, attempt); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		log.Printf("webhooks: failed to record delivery %d: %v", d.ID, err)
+	}
+}
+
+// truncate shortens s to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// RunCleanup deletes finished deliveries with their log, and then the
+// outbox entries no delivery needs any more, once they are older than
+// retention. It runs every interval until ctx is done. Pending deliveries
+// are kept however old, so they can still be sent.
+func (s *WebhookService) RunCleanup(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		before := time.Now().Add(-retention)
+		if n, err := s.repo.DeleteFinished(ctx, before); err != nil {
+			log.Printf("webhook cleanup: %v", err)
+		} else if n > 0 {
+			log.Printf("webhook cleanup: deleted %d deliveries", n)
+		}
+		if n, err := s.outbox.DeleteDispatched(ctx, before); err != nil {
+			log.Printf("webhook cleanup: %v", err)
+		} else if n > 0 {
+			log.Printf("webhook cleanup: deleted %d outbox entries", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDispatcher fans out new events and sends due deliveries every
+// interval until ctx is done.
+func (s *WebhookService) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.FanOut(ctx)
+			if err != nil {
+				log.Printf("webhooks: %v", err)
+				break
+			}
+			if n < outboxBatchSize {
+				break
+			}
+		}
+		for {
+			n, err := s.DeliverDue(ctx)
+			if err != nil {
+				log.Printf("webhooks: %v", err)
+				break
+			}
+			if n < deliveryBatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
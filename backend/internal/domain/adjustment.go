@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AdjustmentStatus tracks an adjustment through the approval queue.
+type AdjustmentStatus string
+
+const (
+	AdjustmentPending  AdjustmentStatus = "pending"  // Waiting for a manager; stock unchanged
+	AdjustmentApplied  AdjustmentStatus = "applied"  // Stock changed, directly or after approval
+	AdjustmentRejected AdjustmentStatus = "rejected" // Declined by a manager; stock unchanged
+)
+
+// StockAdjustment is a manual stock change with a reason, e.g. writing off
+// damaged goods or correcting a miscount.
+type StockAdjustment struct {
+	ID          int64            `json:"id"`
+	ItemID      int64            `json:"item_id"`
+	Delta       float64          `json:"delta"` // In the item's base unit
+	Reason      string           `json:"reason"`
+	Note        string           `json:"note,omitempty"`
+	Location    string           `json:"location,omitempty"`
+	Value       float64          `json:"value"` // |delta| x item price when requested
+	Status      AdjustmentStatus `json:"status"`
+	RequestedBy string           `json:"requested_by,omitempty"`
+	ReviewedBy  string           `json:"reviewed_by,omitempty"`
+	ReviewNote  string           `json:"review_note,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ReviewedAt  *time.Time       `json:"reviewed_at,omitempty"`
+}
+
+// AdjustmentPolicy holds the configurable reason codes and the thresholds
+// above which an adjustment needs a manager's approval. A zero threshold
+// disables that check.
+type AdjustmentPolicy struct {
+	Reasons          []string `json:"reasons"`
+	ApprovalQuantity int      `json:"approval_quantity"`
+	ApprovalValue    float64  `json:"approval_value"`
+}
+
+// DefaultAdjustmentReasons is used when no reason list is configured.
+var DefaultAdjustmentReasons = []string{"damaged", "expired", "miscount", "theft", "found", "returned"}
+
+// NeedsApproval reports whether an adjustment of delta units worth value
+// exceeds either threshold.
+func (p AdjustmentPolicy) NeedsApproval(delta float64, value float64) bool {
+	if p.ApprovalQuantity > 0 && math.Abs(delta) > float64(p.ApprovalQuantity) {
+		return true
+	}
+	return p.ApprovalValue > 0 && value > p.ApprovalValue
+}
+
+// Validate checks a new adjustment against the policy.
+func (p AdjustmentPolicy) Validate(adj *StockAdjustment) error {
+	verr := &ValidationError{}
+	if adj.Delta == 0 {
+		verr.Add("delta", CodeInvalid, "delta must not be zero")
+	}
+	if !slices.Contains(p.Reasons, adj.Reason) {
+		verr.Add("reason", CodeInvalid, "reason must be one of: "+strings.Join(p.Reasons, ", "))
+	}
+	return verr.Err()
+}
+
+// AdjustmentValue is the retail value moved by an adjustment.
+func AdjustmentValue(delta float64, price float64) float64 {
+	return math.Round(math.Abs(delta)*price*100) / 100
+}
+
+type AdjustmentRepository interface {
+	Create(ctx context.Context, adj *StockAdjustment) error
+	GetByID(ctx context.Context, id int64) (*StockAdjustment, error)
+	// List filters by item and status; zero values match everything.
+	List(ctx context.Context, itemID int64, status AdjustmentStatus) ([]*StockAdjustment, error)
+	// Review records the decision on a pending adjustment. It fails with an
+	// error matching ErrConflict if the adjustment is no longer pending.
+	Review(ctx context.Context, adj *StockAdjustment) error
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AttachmentKind tells product photos apart from other files. It is
+// derived from the uploaded data, not chosen by the client.
+type AttachmentKind string
+
+const (
+	AttachmentImage    AttachmentKind = "image"
+	AttachmentDocument AttachmentKind = "document" // Spec sheets and other files
+)
+
+// Attachment is a file stored with an item. The data lives in blob
+// storage under StorageKey; the row only describes it.
+type Attachment struct {
+	ID          int64          `json:"id"`
+	ItemID      int64          `json:"item_id"`
+	Kind        AttachmentKind `json:"kind"`
+	FileName    string         `json:"file_name"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	// Width and Height are the pixel size of images.
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	StorageKey   string `json:"-"`
+	ThumbnailKey string `json:"-"` // Empty when no thumbnail could be made
+	// URL and ThumbnailURL are where the API serves the file.
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	UploadedBy   string    `json:"uploaded_by,omitempty"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
+
+// AttachmentURL is the API path serving an attachment's data.
+func AttachmentURL(id int64) string {
+	return fmt.Sprintf("/api/attachments/%d", id)
+}
+
+// AttachmentThumbnailURL is the API path serving an image's thumbnail.
+func AttachmentThumbnailURL(id int64) string {
+	return fmt.Sprintf("/api/attachments/%d/thumbnail", id)
+}
+
+// SetURLs fills URL and ThumbnailURL from the ID.
+func (a *Attachment) SetURLs() {
+	a.URL = AttachmentURL(a.ID)
+	a.ThumbnailURL = ""
+	if a.ThumbnailKey != "" {
+		a.ThumbnailURL = AttachmentThumbnailURL(a.ID)
+	}
+}
+
+// Attachment limits.
+const (
+	MaxAttachmentSize  = 20 << 20
+	MaxItemAttachments = 20
+	// ThumbnailSize bounds the longer side of thumbnails, in pixels.
+	ThumbnailSize = 256
+)
+
+// AttachmentImageTypes are the formats stored as images with a thumbnail.
+// Anything else is kept as a document.
+var AttachmentImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// Lookups of a missing attachment return an error matching ErrNotFound.
+type AttachmentRepository interface {
+	// Create fails with an error matching ErrConflict once the item has
+	// MaxItemAttachments.
+	Create(ctx context.Context, a *Attachment) error
+	GetByID(ctx context.Context, id int64) (*Attachment, error)
+	// ListByItem returns the item's attachments, oldest first. The oldest
+	// image is the item's picture.
+	ListByItem(ctx context.Context, itemID int64) ([]Attachment, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// BlobStore keeps attachment data by key. Get of a missing key returns an
+// error matching ErrNotFound; Delete of a missing key succeeds.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Thumbnailer scales images down to at most size pixels on the longer side.
+type Thumbnailer interface {
+	// Thumbnail returns the encoded thumbnail with its content type, and
+	// the pixel size of the original.
+	Thumbnail(data []byte, size int) (thumb []byte, contentType string, width, height int, err error)
+}
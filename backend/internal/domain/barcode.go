@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidBarcode is returned for barcodes that look like a GTIN but fail
+// the check digit, or contain characters no scanner would produce.
+var ErrInvalidBarcode = errors.New("invalid barcode")
+
+// BarcodeFormat is the symbology family detected from a barcode's content.
+type BarcodeFormat string
+
+const (
+	BarcodeEAN8   BarcodeFormat = "ean8"
+	BarcodeUPCA   BarcodeFormat = "upca"
+	BarcodeEAN13  BarcodeFormat = "ean13"
+	BarcodeGTIN14 BarcodeFormat = "gtin14"
+	// BarcodeOther covers any other printable code, e.g. Code128 or QR content.
+	BarcodeOther BarcodeFormat = "other"
+)
+
+// DetectBarcode classifies a barcode by its length when it is all digits.
+func DetectBarcode(code string) BarcodeFormat {
+	if !isDigits(code) {
+		return BarcodeOther
+	}
+	switch len(code) {
+	case 8:
+		return BarcodeEAN8
+	case 12:
+		return BarcodeUPCA
+	case 13:
+		return BarcodeEAN13
+	case 14:
+		return BarcodeGTIN14
+	default:
+		return BarcodeOther
+	}
+}
+
+// ValidateBarcode detects the format and verifies the check digit for GTINs.
+// Other codes only need to be non-empty printable ASCII without spaces.
+func ValidateBarcode(code string) (BarcodeFormat, error) {
+	if code == "" {
+		return "", fmt.Errorf("%w: barcode is empty", ErrInvalidBarcode)
+	}
+	format := DetectBarcode(code)
+	if format == BarcodeOther {
+		if err := checkBarcodeCharacters(code); err != nil {
+			return "", err
+		}
+		return format, nil
+	}
+	body, check := code[:len(code)-1], code[len(code)-1]
+	if GTINCheckDigit(body) != check {
+		return "", fmt.Errorf("%w: %q has a wrong check digit for %s", ErrInvalidBarcode, code, format)
+	}
+	return format, nil
+}
+
+// checkBarcodeCharacters accepts printable ASCII without spaces, which is
+// what scanners produce.
+func checkBarcodeCharacters(code string) error {
+	for _, r := range code {
+		if r <= ' ' || r > '~' {
+			return fmt.Errorf("%w: %q contains unsupported characters", ErrInvalidBarcode, code)
+		}
+	}
+	return nil
+}
+
+// NormalizeGTIN returns the 14-digit form of a GTIN, so that the same product
+// scanned as UPC-A, EAN-13 or GTIN-14 compares equal. Other codes are
+// returned unchanged.
+func NormalizeGTIN(code string) string {
+	if DetectBarcode(code) == BarcodeOther {
+		return code
+	}
+	return strings.Repeat("0", 14-len(code)) + code
+}
+
+// BarcodeVariants lists every leading-zero form a GTIN can be scanned as,
+// starting with the code itself. Other codes have only themselves.
+func BarcodeVariants(code string) []string {
+	if DetectBarcode(code) == BarcodeOther {
+		return []string{code}
+	}
+	gtin := NormalizeGTIN(code)
+	variants := []string{code}
+	for _, n := range []int{14, 13, 12, 8} {
+		v := gtin[14-n:]
+		if v != code && strings.Trim(gtin[:14-n], "0") == "" {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// GTINCheckDigit computes the GS1 mod-10 check digit for the given digits
+// (without the check digit). It is the same algorithm for EAN-8, UPC-A,
+// EAN-13 and GTIN-14.
+func GTINCheckDigit(digits string) byte {
+	sum := 0
+	// Weights alternate 3,1,3,... starting from the rightmost digit.
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// BarcodeGenerator builds internal EAN-13 barcodes from a range of GS1
+// restricted-circulation prefixes (e.g. 200-299). Codes in those ranges are
+// never issued to manufacturers, so they cannot clash with a product's own
+// barcode.
+type BarcodeGenerator struct {
+	low, high int
+	width     int // digits in the prefix
+}
+
+// DefaultBarcodePrefixes is the in-store range used when none is configured.
+const DefaultBarcodePrefixes = "200-299"
+
+// NewBarcodeGenerator parses a prefix range such as "200-299", "20-29" or a
+// single prefix such as "250". Both ends must have the same number of digits.
+func NewBarcodeGenerator(spec string) (*BarcodeGenerator, error) {
+	lowStr, highStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		highStr = lowStr
+	}
+	lowStr, highStr = strings.TrimSpace(lowStr), strings.TrimSpace(highStr)
+	if !isDigits(lowStr) || !isDigits(highStr) || len(lowStr) != len(highStr) || len(lowStr) > 6 {
+		return nil, fmt.Errorf("invalid barcode prefix range %q", spec)
+	}
+	low, _ := strconv.Atoi(lowStr)
+	high, _ := strconv.Atoi(highStr)
+	if low > high {
+		return nil, fmt.Errorf("invalid barcode prefix range %q", spec)
+	}
+	return &BarcodeGenerator{low: low, high: high, width: len(lowStr)}, nil
+}
+
+// Generate returns the EAN-13 for the given sequence value. Sequence values
+// fill the lowest prefix first and move on to the next when it is exhausted.
+func (g *BarcodeGenerator) Generate(seq int64) (string, error) {
+	bodyDigits := 12 - g.width
+	perPrefix := int64(1)
+	for i := 0; i < bodyDigits; i++ {
+		perPrefix *= 10
+	}
+	prefix := int64(g.low) + seq/perPrefix
+	if seq < 0 || prefix > int64(g.high) {
+		return "", fmt.Errorf("internal barcode range exhausted at sequence %d", seq)
+	}
+	body := fmt.Sprintf("%0*d%0*d", g.width, prefix, bodyDigits, seq%perPrefix)
+	return body + string(GTINCheckDigit(body)), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
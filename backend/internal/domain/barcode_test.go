@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestGTINCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"9638507", '4'},       // EAN-8
+		{"03600029145", '2'},   // UPC-A
+		{"400638133393", '1'},  // EAN-13
+		{"1001234512345", '7'}, // GTIN-14
+		{"0000000", '0'},
+		{"", '0'},
+	}
+	for _, tt := range tests {
+		if got := GTINCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("GTINCheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeGTIN(t *testing.T) {
+	tests := []struct {
+		code, want string
+	}{
+		{"96385074", "00000096385074"},
+		{"036000291452", "00036000291452"},
+		{"0036000291452", "00036000291452"},
+		{"4006381333931", "04006381333931"},
+		{"10012345123457", "10012345123457"},
+		{"12345", "12345"},     // Not a GTIN length
+		{"ABC-123", "ABC-123"}, // Not digits
+		{"0123456789012345", "0123456789012345"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeGTIN(tt.code); got != tt.want {
+			t.Errorf("NormalizeGTIN(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBarcode(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    BarcodeFormat
+		invalid bool
+	}{
+		{"96385074", BarcodeEAN8, false},
+		{"036000291452", BarcodeUPCA, false},
+		{"4006381333931", BarcodeEAN13, false},
+		{"10012345123457", BarcodeGTIN14, false},
+		{"4006381333932", "", true},
+		{"ABC-123", BarcodeOther, false},
+		{"AB 123", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ValidateBarcode(tt.code)
+		if tt.invalid != errors.Is(err, ErrInvalidBarcode) || got != tt.want {
+			t.Errorf("ValidateBarcode(%q) = %q, %v; want %q, invalid %v", tt.code, got, err, tt.want, tt.invalid)
+		}
+	}
+}
+
+func TestBarcodeVariants(t *testing.T) {
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{"036000291452", []string{"036000291452", "00036000291452", "0036000291452"}},
+		{"00000096385074", []string{"00000096385074", "0000096385074", "000096385074", "96385074"}},
+		{"4006381333931", []string{"4006381333931", "04006381333931"}},
+		{"ABC-123", []string{"ABC-123"}},
+	}
+	for _, tt := range tests {
+		if got := BarcodeVariants(tt.code); !slices.Equal(got, tt.want) {
+			t.Errorf("BarcodeVariants(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
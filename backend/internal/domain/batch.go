@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxBatchOps caps the operations of one batch and the barcodes of one
+// bulk lookup.
+const MaxBatchOps = 100
+
+// BatchOpType is an operation a batch can run.
+type BatchOpType string
+
+const (
+	BatchLookup         BatchOpType = "lookup"          // GET /api/inventory/barcode/{code}
+	BatchCreateItem     BatchOpType = "create_item"     // POST /api/inventory
+	BatchSetFulfillment BatchOpType = "set_fulfillment" // PUT /api/sales/items/{itemId}/fulfillment
+)
+
+// BatchOp is one operation of a batch. Which fields apply depends on Op:
+//
+//	lookup           barcode
+//	create_item      item
+//	set_fulfillment  order_item_id, is_fulfilled, version (optional If-Match)
+type BatchOp struct {
+	Op          BatchOpType `json:"op"`
+	Barcode     string      `json:"barcode,omitempty"`
+	Item        *Item       `json:"item,omitempty"`
+	OrderItemID int64       `json:"order_item_id,omitempty"`
+	IsFulfilled bool        `json:"is_fulfilled,omitempty"`
+	Version     int64       `json:"version,omitempty"`
+}
+
+// Validate checks the fields the operation needs.
+func (op *BatchOp) Validate() error {
+	verr := &ValidationError{}
+	switch op.Op {
+	case BatchLookup:
+		if strings.TrimSpace(op.Barcode) == "" {
+			verr.Add("barcode", CodeRequired, "barcode is required")
+		}
+	case BatchCreateItem:
+		if op.Item == nil {
+			verr.Add("item", CodeRequired, "item is required")
+		}
+	case BatchSetFulfillment:
+		if op.OrderItemID <= 0 {
+			verr.Add("order_item_id", CodeRequired, "order_item_id is required")
+		}
+		if op.Version < 0 {
+			verr.Add("version", CodeMin, "version must not be negative")
+		}
+	default:
+		verr.Add("op", CodeInvalid, "op must be lookup, create_item or set_fulfillment")
+	}
+	return verr.Err()
+}
+
+// BatchRequest runs its operations in order. An atomic batch runs them in
+// one transaction: the first failure undoes the ones before it and skips
+// the rest. Otherwise every operation stands on its own.
+type BatchRequest struct {
+	Atomic bool      `json:"atomic"`
+	Ops    []BatchOp `json:"ops"`
+}
+
+// Validate checks the size of the batch. The operations are checked as
+// they run, so one bad operation does not refuse a non-atomic batch.
+func (b *BatchRequest) Validate() error {
+	verr := &ValidationError{}
+	if len(b.Ops) == 0 {
+		verr.Add("ops", CodeRequired, "ops is required")
+	} else if len(b.Ops) > MaxBatchOps {
+		verr.Add("ops", CodeTooLong, fmt.Sprintf("at most %d ops per batch", MaxBatchOps))
+	}
+	return verr.Err()
+}
+
+// BatchOpStatus is the outcome of one operation.
+type BatchOpStatus string
+
+const (
+	BatchOK         BatchOpStatus = "ok"
+	BatchError      BatchOpStatus = "error"
+	BatchRolledBack BatchOpStatus = "rolled_back" // Succeeded, then undone by a later failure of an atomic batch
+	BatchSkipped    BatchOpStatus = "skipped"     // Not run after a failure of an atomic batch
+)
+
+// BatchOpResult reports one operation, at the same index as the operation.
+type BatchOpResult struct {
+	Status BatchOpStatus `json:"status"`
+	// Item is the item found or created.
+	Item *Item `json:"item,omitempty"`
+	// Version is the order's version after a fulfillment change.
+	Version int64    `json:"version,omitempty"`
+	Error   *OpError `json:"error,omitempty"`
+}
+
+// BatchResult reports a batch. Committed is false when an atomic batch
+// failed and nothing was kept.
+type BatchResult struct {
+	Committed bool            `json:"committed"`
+	Results   []BatchOpResult `json:"results"`
+}
+
+// BarcodeMatch is the answer for one barcode of a bulk lookup. Item is nil
+// when no active item has the barcode.
+type BarcodeMatch struct {
+	Barcode string `json:"barcode"`
+	Item    *Item  `json:"item"`
+}
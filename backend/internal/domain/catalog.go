@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Category is a node in the category tree. Its settings apply to
+// subcategories and items that do not set their own.
+type Category struct {
+	ID       int64  `json:"id"`
+	ParentID *int64 `json:"parent_id"`
+	Name     string `json:"name"`
+	// TaxRate is a percentage, e.g. 11 for 11%.
+	TaxRate      *float64  `json:"tax_rate"`
+	ReorderPoint *int      `json:"reorder_point"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (c *Category) Validate() error {
+	verr := &ValidationError{}
+	validateName(verr, c.Name)
+	validateSettings(verr, c.TaxRate, c.ReorderPoint)
+	return verr.Err()
+}
+
+// Brand is the manufacturer or label an item is sold under.
+type Brand struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (b *Brand) Validate() error {
+	verr := &ValidationError{}
+	validateName(verr, b.Name)
+	return verr.Err()
+}
+
+// Tag is a free-form label with the number of items carrying it.
+type Tag struct {
+	Name  string `json:"name"`
+	Items int    `json:"items"`
+}
+
+// Tag limits.
+const (
+	MaxTagLength   = 50
+	MaxTagsPerItem = 20
+)
+
+// NormalizeTags trims, lowercases and de-duplicates tags, keeping their
+// order.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+func validateName(verr *ValidationError, name string) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		verr.Add("name", CodeRequired, "name is required")
+	case len([]rune(name)) > MaxNameLength:
+		verr.Add("name", CodeTooLong, fmt.Sprintf("name must be at most %d characters", MaxNameLength))
+	}
+}
+
+func validateSettings(verr *ValidationError, taxRate *float64, reorderPoint *int) {
+	if taxRate != nil && (*taxRate < 0 || *taxRate > 100) {
+		verr.Add("tax_rate", CodeInvalid, "tax_rate must be a percentage between 0 and 100")
+	}
+	if reorderPoint != nil && *reorderPoint < 0 {
+		verr.Add("reorder_point", CodeMin, "reorder_point must not be negative")
+	}
+}
+
+// ItemSettings are the settings in effect for an item: its own where set,
+// otherwise the nearest category's. Nil means none is configured.
+type ItemSettings struct {
+	TaxRate      *float64 `json:"tax_rate"`
+	ReorderPoint *int     `json:"reorder_point"`
+}
+
+// CategoryTree resolves parents and inherited settings of a set of
+// categories.
+type CategoryTree struct {
+	byID map[int64]*Category
+}
+
+func NewCategoryTree(categories []*Category) *CategoryTree {
+	t := &CategoryTree{byID: make(map[int64]*Category, len(categories))}
+	for _, c := range categories {
+		t.byID[c.ID] = c
+	}
+	return t
+}
+
+func (t *CategoryTree) Get(id int64) (*Category, bool) {
+	c, ok := t.byID[id]
+	return c, ok
+}
+
+// Ancestors returns the category and its parents up to the root. The walk
+// is bounded by the tree size, so a corrupt cycle cannot loop forever.
+func (t *CategoryTree) Ancestors(id int64) []*Category {
+	var chain []*Category
+	for c, ok := t.byID[id]; ok && len(chain) <= len(t.byID); c, ok = t.parent(c) {
+		chain = append(chain, c)
+	}
+	return chain
+}
+
+func (t *CategoryTree) parent(c *Category) (*Category, bool) {
+	if c.ParentID == nil {
+		return nil, false
+	}
+	p, ok := t.byID[*c.ParentID]
+	return p, ok
+}
+
+// CheckParent rejects a parent that does not exist or would make the
+// category its own ancestor.
+func (t *CategoryTree) CheckParent(id int64, parentID *int64) error {
+	if parentID == nil {
+		return nil
+	}
+	verr := &ValidationError{}
+	if _, ok := t.byID[*parentID]; !ok {
+		verr.Add("parent_id", CodeInvalid, "parent category does not exist")
+		return verr
+	}
+	for _, a := range t.Ancestors(*parentID) {
+		if a.ID == id {
+			verr.Add("parent_id", CodeInvalid, "a category cannot be moved below itself")
+			return verr
+		}
+	}
+	return nil
+}
+
+// Settings resolves the settings of an item. The item's own values win;
+// otherwise its categories are tried in order, each walking up to the root.
+func (t *CategoryTree) Settings(item *Item) ItemSettings {
+	s := ItemSettings{TaxRate: item.TaxRate, ReorderPoint: item.ReorderPoint}
+	for _, id := range item.CategoryIDs {
+		for _, c := range t.Ancestors(id) {
+			if s.TaxRate == nil {
+				s.TaxRate = c.TaxRate
+			}
+			if s.ReorderPoint == nil {
+				s.ReorderPoint = c.ReorderPoint
+			}
+		}
+	}
+	return s
+}
+
+// Lookups of a missing row return an error matching ErrNotFound.
+type CategoryRepository interface {
+	Create(ctx context.Context, category *Category) error
+	Update(ctx context.Context, category *Category) error
+	// Delete fails with an error matching ErrConflict while the category
+	// has subcategories or items.
+	Delete(ctx context.Context, id int64) error
+	GetByID(ctx context.Context, id int64) (*Category, error)
+	// List returns every category ordered by name.
+	List(ctx context.Context) ([]*Category, error)
+}
+
+type BrandRepository interface {
+	Create(ctx context.Context, brand *Brand) error
+	Update(ctx context.Context, brand *Brand) error
+	// Delete fails with an error matching ErrConflict while items use the
+	// brand.
+	Delete(ctx context.Context, id int64) error
+	GetByID(ctx context.Context, id int64) (*Brand, error)
+	List(ctx context.Context) ([]*Brand, error)
+}
+
+type TagRepository interface {
+	// List returns the tags in use, most used first.
+	List(ctx context.Context) ([]Tag, error)
+}
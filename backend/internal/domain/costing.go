@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ValuationMethod decides the cost of goods leaving stock.
+type ValuationMethod string
+
+const (
+	// ValuationAverage costs goods at the moving weighted average of the
+	// stock on hand, recomputed on every receipt.
+	ValuationAverage ValuationMethod = "average"
+	// ValuationFIFO costs goods from the oldest cost layers first.
+	ValuationFIFO ValuationMethod = "fifo"
+)
+
+func ParseValuationMethod(s string) (ValuationMethod, error) {
+	switch m := ValuationMethod(s); m {
+	case "":
+		return ValuationAverage, nil
+	case ValuationAverage, ValuationFIFO:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown valuation method %q, use average or fifo", s)
+}
+
+// CostLayer is a quantity received at one unit cost. FIFO valuation
+// consumes layers oldest first.
+type CostLayer struct {
+	ID         int64
+	ItemID     int64
+	MovementID int64
+	Quantity   float64 // Remaining base units
+	UnitCost   float64
+	CreatedAt  time.Time
+}
+
+type CostLayerRepository interface {
+	Add(ctx context.Context, layer *CostLayer) error
+	// Consume takes up to quantity units from the oldest layers of the item
+	// and returns how many units were covered and their total cost. Must run
+	// in a transaction.
+	Consume(ctx context.Context, itemID int64, quantity float64) (covered float64, cost float64, err error)
+}
+
+// GoodsReceipt is stock received from a supplier at a purchase cost.
+// Quantity and UnitCost are per Unit, which defaults to the unit of the
+// scanned Barcode and otherwise to the item's base unit, so receiving 2
+// cartons of 24 at 12.00 adds 48 pieces at 0.50.
+type GoodsReceipt struct {
+	Barcode   string  `json:"barcode,omitempty"`
+	Unit      string  `json:"unit,omitempty"`
+	Quantity  float64 `json:"quantity"`
+	UnitCost  float64 `json:"unit_cost"`
+	Location  string  `json:"location"`
+	Reference string  `json:"reference"` // e.g. the supplier's invoice number
+	Note      string  `json:"note"`
+}
+
+func (r *GoodsReceipt) Validate() error {
+	verr := &ValidationError{}
+	if r.Quantity <= 0 {
+		verr.Add("quantity", CodeMin, "quantity must be positive")
+	}
+	if r.UnitCost < 0 {
+		verr.Add("unit_cost", CodeMin, "unit_cost must not be negative")
+	}
+	return verr.Err()
+}
+
+// ItemValuation is the stock of one item valued at cost.
+type ItemValuation struct {
+	ItemID   int64   `json:"item_id"`
+	Name     string  `json:"name"`
+	Location string  `json:"location"`
+	Quantity float64 `json:"quantity"`
+	Value    float64 `json:"value"`
+	UnitCost float64 `json:"unit_cost"`
+}
+
+// Valuation is the inventory at cost at a point in time, rebuilt from the
+// stock ledger.
+type Valuation struct {
+	At            time.Time       `json:"at"`
+	Method        ValuationMethod `json:"method"`
+	TotalQuantity float64         `json:"total_quantity"`
+	TotalValue    float64         `json:"total_value"`
+	Items         []ItemValuation `json:"items"`
+}
+
+// ItemMargin is the gross margin of an item's sales over a period. Only
+// lines sold with a recorded cost are included.
+type ItemMargin struct {
+	ItemID     int64   `json:"item_id"`
+	Name       string  `json:"name"`
+	Quantity   float64 `json:"quantity"`
+	Revenue    float64 `json:"revenue"`
+	COGS       float64 `json:"cogs"`
+	Margin     float64 `json:"margin"`
+	MarginRate float64 `json:"margin_rate"` // Margin / revenue
+}
+
+// RoundCost rounds a cost amount to the precision stored in the database.
+func RoundCost(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// DateRange is the half-open interval [From, To). A zero bound is open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Validate rejects ranges that end before they start.
+func (r DateRange) Validate() error {
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		verr := &ValidationError{}
+		verr.Add("to", CodeInvalid, "to must be after from")
+		return verr
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorKind classifies domain errors so transports can map them to a status
+// code. The values are sent to clients as a machine-readable error code.
+type ErrorKind string
+
+const (
+	KindNotFound          ErrorKind = "not_found"
+	KindConflict          ErrorKind = "conflict"
+	KindValidation        ErrorKind = "validation_failed"
+	KindInsufficientStock ErrorKind = "insufficient_stock"
+	KindForbidden         ErrorKind = "forbidden"
+	KindUnauthorized      ErrorKind = "unauthorized"
+	// KindPreconditionFailed means the row changed since the client read it.
+	KindPreconditionFailed ErrorKind = "precondition_failed"
+)
+
+// Error is a domain error with a kind and a message that is safe to show
+// to clients.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Err     error // optional underlying cause, never shown to clients
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is matches any domain error of the same kind, so callers can write
+// errors.Is(err, domain.ErrNotFound) regardless of the message.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind
+}
+
+// Sentinels for errors.Is checks.
+var (
+	ErrNotFound           = &Error{Kind: KindNotFound, Message: "not found"}
+	ErrConflict           = &Error{Kind: KindConflict, Message: "conflict"}
+	ErrValidation         = &Error{Kind: KindValidation, Message: "validation failed"}
+	ErrInsufficientStock  = &Error{Kind: KindInsufficientStock, Message: "insufficient stock"}
+	ErrForbidden          = &Error{Kind: KindForbidden, Message: "forbidden"}
+	ErrUnauthorized       = &Error{Kind: KindUnauthorized, Message: "unauthorized"}
+	ErrPreconditionFailed = &Error{Kind: KindPreconditionFailed, Message: "precondition failed"}
+)
+
+func newError(kind ErrorKind, format string, args ...any) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewNotFound(format string, args ...any) error {
+	return newError(KindNotFound, format, args...)
+}
+
+func NewConflict(format string, args ...any) error {
+	return newError(KindConflict, format, args...)
+}
+
+func NewInsufficientStock(format string, args ...any) error {
+	return newError(KindInsufficientStock, format, args...)
+}
+
+func NewForbidden(format string, args ...any) error {
+	return newError(KindForbidden, format, args...)
+}
+
+func NewUnauthorized(format string, args ...any) error {
+	return newError(KindUnauthorized, format, args...)
+}
+
+func NewPreconditionFailed(format string, args ...any) error {
+	return newError(KindPreconditionFailed, format, args...)
+}
+
+// KindOf returns the kind of the first domain error in err's chain, or ""
+// for errors that are not domain errors.
+func KindOf(err error) ErrorKind {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return KindValidation
+	}
+	var derr *Error
+	if errors.As(err, &derr) {
+		return derr.Kind
+	}
+	return ""
+}
+
+// OpError reports a failed operation inside a sync push or batch, with the
+// same codes as the REST API's error responses.
+type OpError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// NewOpError describes err for a client. It returns nil for errors that
+// are not domain errors; those must not leak to clients.
+func NewOpError(err error) *OpError {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return &OpError{Code: string(KindValidation), Message: "validation failed", Fields: verr.Fields}
+	}
+	var derr *Error
+	if errors.As(err, &derr) {
+		return &OpError{Code: string(derr.Kind), Message: derr.Message}
+	}
+	return nil
+}
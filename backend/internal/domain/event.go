@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventTopic groups events for subscribers.
+type EventTopic string
+
+const (
+	TopicItem  EventTopic = "item"  // Item created, changed, archived, restored or purged
+	TopicStock EventTopic = "stock" // Stock movements
+	TopicOrder EventTopic = "order" // Orders created, lines fulfilled and orders completed
+)
+
+var EventTopics = []EventTopic{TopicItem, TopicStock, TopicOrder}
+
+// Event types. The part before the dot is the topic.
+const (
+	EventItemCreated      = "item.created"
+	EventItemUpdated      = "item.updated"
+	EventItemArchived     = "item.archived"
+	EventItemRestored     = "item.restored"
+	EventItemPurged       = "item.purged"
+	EventStockMoved       = "stock.moved"
+	EventOrderCreated     = "order.created"
+	EventOrderFulfillment = "order.fulfillment"
+	EventOrderCompleted   = "order.completed"
+)
+
+// Event is a change published to subscribers once it has been committed.
+type Event struct {
+	// ID is assigned on publishing; subscribers resume after it.
+	ID    string     `json:"id"`
+	Topic EventTopic `json:"topic"`
+	Type  string     `json:"type"`
+	// Locations are the stock locations the change concerns, used to filter
+	// subscriptions.
+	Locations []string `json:"locations,omitempty"`
+	// Data is the changed entity as returned by the REST API, encoded when
+	// the event is created so later changes to it do not leak in.
+	Data json.RawMessage `json:"data"`
+	At   time.Time       `json:"at"`
+}
+
+// NewEvent encodes data into an event of the given type. Empty and
+// repeated locations are dropped.
+func NewEvent(eventType string, data any, locations ...string) (Event, error) {
+	topic, _, _ := strings.Cut(eventType, ".")
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	ev := Event{Topic: EventTopic(topic), Type: eventType, Data: raw, At: time.Now()}
+	for _, loc := range locations {
+		if loc = strings.TrimSpace(loc); loc != "" && !slices.Contains(ev.Locations, loc) {
+			ev.Locations = append(ev.Locations, loc)
+		}
+	}
+	return ev, nil
+}
+
+// EventFilter selects the events a subscriber receives. Zero values match
+// everything.
+type EventFilter struct {
+	Topics []EventTopic
+	// Location matches events concerning that location, case-insensitively.
+	// Events without a location do not match.
+	Location string
+}
+
+// ParseEventTopics parses a comma-separated topic list.
+func ParseEventTopics(s string) ([]EventTopic, error) {
+	var topics []EventTopic
+	for _, part := range strings.Split(s, ",") {
+		t := EventTopic(strings.ToLower(strings.TrimSpace(part)))
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(EventTopics, t) {
+			return nil, fmt.Errorf("unknown topic %q, use item, stock or order", part)
+		}
+		topics = append(topics, t)
+	}
+	return topics, nil
+}
+
+func (f EventFilter) Matches(ev Event) bool {
+	if len(f.Topics) > 0 && !slices.Contains(f.Topics, ev.Topic) {
+		return false
+	}
+	if f.Location == "" {
+		return true
+	}
+	return slices.ContainsFunc(ev.Locations, func(loc string) bool {
+		return strings.EqualFold(loc, f.Location)
+	})
+}
+
+// EventPublisher delivers committed events to subscribers.
+type EventPublisher interface {
+	Publish(events ...Event)
+}
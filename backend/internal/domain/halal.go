@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HalalStatus is the halal standing of an item. Items are unknown until
+// someone records otherwise; nothing is assumed halal.
+type HalalStatus string
+
+const (
+	HalalUnknown   HalalStatus = "unknown"
+	HalalCertified HalalStatus = "certified" // Backed by a certificate
+	HalalNot       HalalStatus = "not_halal"
+)
+
+func ParseHalalStatus(s string) (HalalStatus, error) {
+	switch st := HalalStatus(strings.ToLower(strings.TrimSpace(s))); st {
+	case "":
+		return HalalUnknown, nil
+	case HalalUnknown, HalalCertified, HalalNot:
+		return st, nil
+	}
+	return "", fmt.Errorf("unknown halal status %q, use certified, not_halal or unknown", s)
+}
+
+// MaxCertificateFieldLength bounds the certifying body and certificate
+// number.
+const MaxCertificateFieldLength = 100
+
+// Halal is an item's halal status with the certificate behind it.
+type Halal struct {
+	Status            HalalStatus `json:"status"`
+	CertifyingBody    string      `json:"certifying_body,omitempty"`
+	CertificateNumber string      `json:"certificate_number,omitempty"`
+	ExpiresAt         *time.Time  `json:"expires_at,omitempty"`
+	// Document is the file name of the uploaded certificate; it is set by
+	// the upload endpoint only.
+	Document string `json:"document,omitempty"`
+	// Expired is set when the certificate's expiry date has passed.
+	Expired bool `json:"expired"`
+}
+
+// Normalize defaults the status and trims the certificate fields.
+func (h *Halal) Normalize() {
+	if h.Status == "" {
+		h.Status = HalalUnknown
+	}
+	h.CertifyingBody = strings.TrimSpace(h.CertifyingBody)
+	h.CertificateNumber = strings.TrimSpace(h.CertificateNumber)
+}
+
+// validate checks the halal fields. Certified items need the certifying
+// body, certificate number and expiry date, so lapsed certificates are
+// noticed; the others cannot carry a certificate.
+func (h *Halal) validate(verr *ValidationError) {
+	switch h.Status {
+	case HalalCertified:
+		if h.CertifyingBody == "" {
+			verr.Add("halal.certifying_body", CodeRequired, "certifying_body is required for certified items")
+		}
+		if h.CertificateNumber == "" {
+			verr.Add("halal.certificate_number", CodeRequired, "certificate_number is required for certified items")
+		}
+		if h.ExpiresAt == nil {
+			verr.Add("halal.expires_at", CodeRequired, "expires_at is required for certified items")
+		}
+	case HalalUnknown, HalalNot:
+		if h.CertifyingBody != "" || h.CertificateNumber != "" || h.ExpiresAt != nil {
+			verr.Add("halal.status", CodeInvalid, "only certified items can have a certificate")
+		}
+	default:
+		verr.Add("halal.status", CodeInvalid, "status must be certified, not_halal or unknown")
+	}
+	if len([]rune(h.CertifyingBody)) > MaxCertificateFieldLength {
+		verr.Add("halal.certifying_body", CodeTooLong, fmt.Sprintf("certifying_body must be at most %d characters", MaxCertificateFieldLength))
+	}
+	if len([]rune(h.CertificateNumber)) > MaxCertificateFieldLength {
+		verr.Add("halal.certificate_number", CodeTooLong, fmt.Sprintf("certificate_number must be at most %d characters", MaxCertificateFieldLength))
+	}
+}
+
+// Valid reports whether the item is certified halal with a certificate
+// that has not expired.
+func (h Halal) Valid() bool {
+	return h.Status == HalalCertified && !h.Expired
+}
+
+// CheckHalalMix fails when certified halal and non-halal items are on the
+// same order. Items of unknown status do not count either way.
+func CheckHalalMix(items []*Item) error {
+	var halal, nonHalal *Item
+	for _, item := range items {
+		switch item.Halal.Status {
+		case HalalCertified:
+			halal = item
+		case HalalNot:
+			nonHalal = item
+		}
+	}
+	if halal != nil && nonHalal != nil {
+		return NewConflict("order mixes halal %s with non-halal %s; set allow_mixed_halal to override", halal.Name, nonHalal.Name)
+	}
+	return nil
+}
+
+// MaxHalalAlertDays bounds how far ahead expiring certificates are listed.
+const MaxHalalAlertDays = 365
+
+// HalalAlert is a certified item whose certificate has expired or expires
+// soon.
+type HalalAlert struct {
+	ItemID            int64     `json:"item_id"`
+	Name              string    `json:"name"`
+	Location          string    `json:"location"`
+	CertifyingBody    string    `json:"certifying_body"`
+	CertificateNumber string    `json:"certificate_number"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	Expired           bool      `json:"expired"`
+	DaysLeft          int       `json:"days_left"` // Negative once expired
+}
+
+// HalalDocument is an uploaded halal certificate.
+type HalalDocument struct {
+	ItemID      int64     `json:"item_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Data        []byte    `json:"-"`
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// MaxHalalDocumentSize bounds uploaded certificates.
+const MaxHalalDocumentSize = 10 << 20
+
+// HalalDocumentTypes are the accepted certificate formats.
+var HalalDocumentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+// Lookups of a missing document return an error matching ErrNotFound.
+type HalalDocumentRepository interface {
+	// Put stores the item's certificate, replacing any earlier one.
+	Put(ctx context.Context, doc *HalalDocument) error
+	Get(ctx context.Context, itemID int64) (*HalalDocument, error)
+	Delete(ctx context.Context, itemID int64) error
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// IdempotencyStatus tracks a request made with an Idempotency-Key.
+type IdempotencyStatus string
+
+const (
+	IdempotencyInProgress IdempotencyStatus = "in_progress" // The first request is still running
+	IdempotencyCompleted  IdempotencyStatus = "completed"   // The response is stored for replays
+)
+
+// MaxIdempotencyKeyLength bounds client-chosen keys; UUIDs fit easily.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyRecord is a key with the request it was first used for and,
+// once that finished, its response.
+type IdempotencyRecord struct {
+	// Scope separates the keys of different users.
+	Scope string
+	Key   string
+	// RequestHash identifies the request: method, path and body.
+	RequestHash string
+	Status      IdempotencyStatus
+	// ResponseStatus, ResponseHeaders and ResponseBody are replayed.
+	ResponseStatus  int
+	ResponseHeaders map[string]string
+	ResponseBody    []byte
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+}
+
+// ValidateIdempotencyKey checks a client-supplied key.
+func ValidateIdempotencyKey(key string) error {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return fmt.Errorf("Idempotency-Key must be 1 to %d characters", MaxIdempotencyKeyLength)
+	}
+	for _, c := range key {
+		if c < 0x21 || c > 0x7e {
+			return fmt.Errorf("Idempotency-Key must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
+
+type IdempotencyRepository interface {
+	// Reserve stores rec as in progress unless its key is taken. A key is
+	// free when it is new, expired, or was left in progress since before
+	// staleBefore by a request that never finished. Otherwise the stored
+	// record is returned and nothing changes.
+	Reserve(ctx context.Context, rec *IdempotencyRecord, staleBefore time.Time) (existing *IdempotencyRecord, err error)
+	// Complete stores the response of a reserved key.
+	Complete(ctx context.Context, rec *IdempotencyRecord) error
+	// Release frees a key reserved by rec so the request can be retried.
+	Release(ctx context.Context, rec *IdempotencyRecord) error
+	// DeleteExpired removes the records that expired and returns how many.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -2,26 +2,184 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
 type Item struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Barcode   string    `json:"barcode"`
-	Price     float64   `json:"price"`
-	Location  string    `json:"location"`
-	IsHalal   bool      `json:"is_halal"`
-	Quantity  int       `json:"quantity"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Barcode  string  `json:"barcode"`
+	Price    float64 `json:"price"`
+	Location string  `json:"location"`
+	Halal    Halal   `json:"halal"`
+	// IsHalal is true while the item is certified with a current
+	// certificate. It is derived from Halal and read-only.
+	IsHalal bool `json:"is_halal"`
+	// Quantity is the stock on hand in the base unit.
+	Quantity float64 `json:"quantity"`
+	// Unit is the base unit stock is counted in, e.g. pcs or kg. Only items
+	// with AllowDecimal can hold fractional quantities, like weighed goods.
+	Unit         string `json:"unit"`
+	AllowDecimal bool   `json:"allow_decimal"`
+	// Units are alternate units with their conversion to the base unit.
+	Units []ItemUnit `json:"units"`
+	// ScannedUnit is set on barcode lookups that matched an alternate unit.
+	ScannedUnit string `json:"scanned_unit,omitempty"`
+	// ImageURL and ThumbnailURL show the item's picture, its oldest image
+	// attachment. They are read-only.
+	ImageURL     string `json:"image_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	BrandID      *int64 `json:"brand_id"`
+	// ProductID makes the item a variant of a product, told apart from its
+	// siblings by Attributes (e.g. size: 500ml).
+	ProductID  *int64            `json:"product_id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// CategoryIDs links the item to categories; settings are inherited from
+	// them in this order.
+	CategoryIDs []int64  `json:"category_ids"`
+	Tags        []string `json:"tags"`
+	// TaxRate and ReorderPoint override the category settings when set.
+	TaxRate      *float64 `json:"tax_rate"`
+	ReorderPoint *int     `json:"reorder_point"`
+	// Effective holds the settings in effect after inheritance.
+	Effective ItemSettings `json:"effective"`
+	// UnitCost is the average cost of the stock on hand. On create it is the
+	// purchase cost of the opening quantity; afterwards it is read-only.
+	UnitCost float64 `json:"unit_cost"`
+	// StockValue is the stock on hand valued at cost.
+	StockValue float64   `json:"stock_value"`
+	Version    int64     `json:"version"` // Incremented on every update, used for optimistic locking
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// ArchivedAt is set for items no longer sold. They keep their history
+	// but are hidden from lists and scanning until restored.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
+// Archived reports whether the item has been archived.
+func (i *Item) Archived() bool {
+	return i.ArchivedAt != nil
+}
+
+// SetImage points the image URLs at the attachment, or clears them. Every
+// stored image has a thumbnail.
+func (i *Item) SetImage(attachmentID *int64) {
+	i.ImageURL, i.ThumbnailURL = "", ""
+	if attachmentID != nil {
+		i.ImageURL = AttachmentURL(*attachmentID)
+		i.ThumbnailURL = AttachmentThumbnailURL(*attachmentID)
+	}
+}
+
+// ArchiveFilter selects items by archival state in listings.
+type ArchiveFilter string
+
+const (
+	ArchiveExclude ArchiveFilter = ""        // Active items only (default)
+	ArchiveInclude ArchiveFilter = "include" // Active and archived items
+	ArchiveOnly    ArchiveFilter = "only"    // Archived items only
+)
+
+// ItemFilter narrows item listings. Zero values match everything.
+type ItemFilter struct {
+	Archived ArchiveFilter
+	// CategoryID matches items in the category or any of its subcategories.
+	CategoryID int64
+	BrandID    int64
+	ProductID  int64
+	Tag        string
+	IDs        []int64 // Only these items, when set
+}
+
+// MaxNameLength bounds item names so they fit on receipts and labels.
+const MaxNameLength = 200
+
+// Validate checks the fields a client can set. An empty barcode is allowed
+// because one is generated on create.
+func (i *Item) Validate() error {
+	verr := &ValidationError{}
+	validateName(verr, i.Name)
+	i.Halal.validate(verr)
+	if i.Barcode != "" {
+		if err := checkBarcodeCharacters(i.Barcode); err != nil {
+			verr.Add("barcode", CodeInvalid, err.Error())
+		}
+	}
+	if i.Price < 0 {
+		verr.Add("price", CodeMin, "price must not be negative")
+	}
+	if i.Quantity < 0 {
+		verr.Add("quantity", CodeMin, "quantity must not be negative")
+	} else {
+		i.validateQuantity(verr, "quantity", i.Quantity)
+	}
+	i.validateUnits(verr)
+	if i.UnitCost < 0 {
+		verr.Add("unit_cost", CodeMin, "unit_cost must not be negative")
+	}
+	validateSettings(verr, i.TaxRate, i.ReorderPoint)
+	if len(i.Tags) > MaxTagsPerItem {
+		verr.Add("tags", CodeInvalid, fmt.Sprintf("an item can have at most %d tags", MaxTagsPerItem))
+	}
+	for _, t := range i.Tags {
+		if len([]rune(t)) > MaxTagLength {
+			verr.Add("tags", CodeTooLong, fmt.Sprintf("tags must be at most %d characters", MaxTagLength))
+			break
+		}
+	}
+	return verr.Err()
+}
+
+// ValidateNewBarcode enforces the GTIN check digit on a barcode that differs
+// from previous. An unchanged barcode is accepted as stored, so items saved
+// with a numeric Code128 or legacy code of a GTIN length stay editable.
+func (i *Item) ValidateNewBarcode(previous string) error {
+	if i.Barcode == "" || i.Barcode == previous {
+		return nil
+	}
+	if _, err := ValidateBarcode(i.Barcode); err != nil {
+		verr := &ValidationError{}
+		verr.Add("barcode", CodeInvalid, err.Error())
+		return verr
+	}
+	return nil
+}
+
+// Lookups and updates of a missing row return an error matching ErrNotFound.
 type ItemRepository interface {
 	Create(ctx context.Context, item *Item) error
+	// Create and Update also store the brand, category and tag links and
+	// the alternate units.
+	// Update writes every field except Quantity. It only succeeds if
+	// item.Version matches the stored version; a stale version returns an
+	// error matching ErrPreconditionFailed. On success item holds the new
+	// version and the stored quantity.
 	Update(ctx context.Context, item *Item) error
-	Delete(ctx context.Context, id int64) error
+	// AdjustQuantity atomically adds delta to the stock. It returns an error
+	// matching ErrInsufficientStock instead of going below zero.
+	AdjustQuantity(ctx context.Context, id int64, delta float64) (*Item, error)
+	// SetStockValue stores the cost value of the stock on hand. It does not
+	// change the version, as the value only follows stock movements.
+	SetStockValue(ctx context.Context, id int64, value float64) error
+	// SetPrice changes the price regardless of the version, bumps the version
+	// and returns the price it replaced.
+	SetPrice(ctx context.Context, id int64, price float64) (old float64, err error)
+	// SetArchived archives or restores the item and bumps its version.
+	SetArchived(ctx context.Context, id int64, archived bool) (*Item, error)
+	// Purge permanently deletes the item together with its opening stock
+	// movement, price history, units and category and tag links. It fails
+	// with an error matching ErrConflict if the item is referenced by sales,
+	// adjustments or any other movement, or still has attachments.
+	Purge(ctx context.Context, id int64) error
+	// GetByID and GetByBarcode also return archived items. GetByBarcode
+	// matches the barcodes of alternate units too.
 	GetByID(ctx context.Context, id int64) (*Item, error)
 	GetByBarcode(ctx context.Context, barcode string) (*Item, error)
-	List(ctx context.Context) ([]*Item, error)
+	List(ctx context.Context, filter ItemFilter) ([]*Item, error)
+	// Each streams the filtered items to fn without loading them all.
+	// Returning an error from fn stops the iteration.
+	Each(ctx context.Context, filter ItemFilter, fn func(*Item) error) error
+	// NextBarcodeSequence returns a new value for generating internal barcodes.
+	NextBarcodeSequence(ctx context.Context) (int64, error)
 }
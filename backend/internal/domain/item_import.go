@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportFields are the item fields a spreadsheet column can map to.
+var ImportFields = []string{
+	"name", "barcode", "price", "location", "quantity", "unit", "allow_decimal",
+	"halal_status", "halal_certifying_body", "halal_certificate_number", "halal_expires_at",
+}
+
+// MaxImportRows bounds a single import so it fits in one transaction.
+const MaxImportRows = 10000
+
+// ImportMapping maps item fields to spreadsheet column headers, e.g.
+// {"name": "Product", "price": "Retail Price"}. Fields without a mapping
+// are matched to a header of the same name, ignoring case.
+type ImportMapping map[string]string
+
+// Columns resolves the mapping against the header row and returns the
+// column index of every mapped field.
+func (m ImportMapping) Columns(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	verr := &ValidationError{}
+	for field := range m {
+		if !isImportField(field) {
+			verr.Add("mapping."+field, CodeInvalid, "unknown field")
+		}
+	}
+	columns := make(map[string]int)
+	for _, field := range ImportFields {
+		header, mapped := m[field]
+		if !mapped {
+			header = field
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(header))]
+		switch {
+		case ok:
+			columns[field] = i
+		case mapped:
+			verr.Add("mapping."+field, CodeInvalid, fmt.Sprintf("column %q not found", header))
+		}
+	}
+	if _, ok := columns["name"]; !ok && verr.Err() == nil {
+		verr.Add("mapping.name", CodeRequired, "a column must map to name")
+	}
+	return columns, verr.Err()
+}
+
+func isImportField(field string) bool {
+	for _, f := range ImportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportRow is one data row of an import, numbered as in the spreadsheet
+// (the header is row 1). Values holds the mapped fields only, so a column
+// that is not in the file leaves the existing value untouched on update.
+type ImportRow struct {
+	Row    int
+	Values map[string]string
+}
+
+// Blank reports whether every cell of the row is empty.
+func (r ImportRow) Blank() bool {
+	for _, v := range r.Values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Item parses the row into an item. Only fields present in Values are set;
+// set lists them. Blank price, quantity, allow_decimal, halal_status and
+// halal_expires_at cells count as absent.
+func (r ImportRow) Item() (item *Item, set map[string]bool, err error) {
+	item = &Item{}
+	set = make(map[string]bool, len(r.Values))
+	verr := &ValidationError{}
+	for field, raw := range r.Values {
+		raw = strings.TrimSpace(raw)
+		set[field] = true
+		switch field {
+		case "name":
+			item.Name = raw
+		case "barcode":
+			item.Barcode = raw
+		case "location":
+			item.Location = raw
+		case "unit":
+			item.Unit = raw
+		case "price":
+			if raw == "" {
+				delete(set, field)
+				continue
+			}
+			price, perr := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+			if perr != nil {
+				verr.Add("price", CodeInvalid, "price must be a number")
+			}
+			item.Price = price
+		case "quantity":
+			if raw == "" {
+				delete(set, field)
+				continue
+			}
+			qty, perr := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+			if perr != nil {
+				verr.Add("quantity", CodeInvalid, "quantity must be a number")
+			}
+			item.Quantity = qty
+		case "halal_status":
+			if raw == "" {
+				delete(set, field)
+				continue
+			}
+			status, perr := ParseHalalStatus(raw)
+			if perr != nil {
+				verr.Add("halal_status", CodeInvalid, perr.Error())
+			}
+			item.Halal.Status = status
+		case "halal_certifying_body":
+			item.Halal.CertifyingBody = raw
+		case "halal_certificate_number":
+			item.Halal.CertificateNumber = raw
+		case "halal_expires_at":
+			if raw == "" {
+				delete(set, field)
+				continue
+			}
+			expires, perr := parseImportDate(raw)
+			if perr != nil {
+				verr.Add("halal_expires_at", CodeInvalid, "halal_expires_at must be a date (YYYY-MM-DD)")
+			}
+			item.Halal.ExpiresAt = &expires
+		case "allow_decimal":
+			if raw == "" {
+				delete(set, field)
+				continue
+			}
+			allow, ok := parseImportBool(raw)
+			if !ok {
+				verr.Add("allow_decimal", CodeInvalid, "allow_decimal must be yes or no")
+			}
+			item.AllowDecimal = allow
+		}
+	}
+	item.NormalizeUnits()
+	item.Halal.Normalize()
+	if err := verr.Err(); err != nil {
+		return nil, nil, err
+	}
+	return item, set, nil
+}
+
+// parseImportDate accepts a date or an RFC 3339 timestamp. Dates are taken
+// as midnight UTC.
+func parseImportDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func parseImportBool(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "1", "y", "yes", "true":
+		return true, true
+	case "0", "n", "no", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// ImportAction is what an import does with a row.
+type ImportAction string
+
+const (
+	ImportCreate ImportAction = "create"
+	ImportUpdate ImportAction = "update"
+	ImportSkip   ImportAction = "skip" // Matches the stored item, nothing to change
+	ImportError  ImportAction = "error"
+)
+
+type ImportRowResult struct {
+	Row     int          `json:"row"`
+	Action  ImportAction `json:"action"`
+	ItemID  int64        `json:"item_id,omitempty"`
+	Barcode string       `json:"barcode,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+type ImportSummary struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// ImportResult reports every row. Committed is false for dry runs and for
+// imports rolled back because of invalid rows.
+type ImportResult struct {
+	DryRun    bool              `json:"dry_run"`
+	Committed bool              `json:"committed"`
+	Summary   ImportSummary     `json:"summary"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+// Add records a row result and counts it in the summary.
+func (r *ImportResult) Add(row ImportRowResult) {
+	switch row.Action {
+	case ImportCreate:
+		r.Summary.Created++
+	case ImportUpdate:
+		r.Summary.Updated++
+	case ImportSkip:
+		r.Summary.Skipped++
+	case ImportError:
+		r.Summary.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
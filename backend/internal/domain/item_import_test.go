@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestImportMappingColumns(t *testing.T) {
+	header := []string{"Product", "EAN", "Retail Price", "quantity", "Notes"}
+	tests := []struct {
+		name        string
+		mapping     ImportMapping
+		want        map[string]int
+		wantInvalid []string // Fields of the validation errors
+	}{
+		{
+			name:    "mapped and same-name columns",
+			mapping: ImportMapping{"name": "product", "barcode": "EAN", "price": " Retail Price "},
+			want:    map[string]int{"name": 0, "barcode": 1, "price": 2, "quantity": 3},
+		},
+		{
+			name:        "name is required",
+			mapping:     ImportMapping{"barcode": "EAN"},
+			wantInvalid: []string{"mapping.name"},
+		},
+		{
+			name:        "unknown field and missing column",
+			mapping:     ImportMapping{"name": "Product", "colour": "Notes", "price": "Cost"},
+			wantInvalid: []string{"mapping.colour", "mapping.price"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.mapping.Columns(header)
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				var fields []string
+				for _, f := range verr.Fields {
+					fields = append(fields, f.Field)
+				}
+				slices.Sort(fields)
+				if !slices.Equal(fields, tt.wantInvalid) {
+					t.Errorf("Columns() invalid fields = %v, want %v", fields, tt.wantInvalid)
+				}
+				return
+			}
+			if err != nil || tt.wantInvalid != nil {
+				t.Fatalf("Columns() error = %v, want invalid %v", err, tt.wantInvalid)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Columns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportRowItem(t *testing.T) {
+	expires := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    Item
+		wantSet []string
+		invalid bool
+	}{
+		{
+			name: "every field",
+			values: map[string]string{"name": " Tea ", "barcode": "4006381333931", "price": "1,250.50", "location": "A1", "quantity": "7",
+				"halal_status": "Certified", "halal_certifying_body": " MUI ", "halal_certificate_number": "ID-1", "halal_expires_at": "2030-01-31"},
+			want: Item{Name: "Tea", Barcode: "4006381333931", Price: 1250.5, Location: "A1", Quantity: 7, Unit: "pcs",
+				Halal: Halal{Status: HalalCertified, CertifyingBody: "MUI", CertificateNumber: "ID-1", ExpiresAt: &expires}},
+			wantSet: []string{"barcode", "halal_certificate_number", "halal_certifying_body", "halal_expires_at", "halal_status", "location", "name", "price", "quantity"},
+		},
+		{
+			name:    "blank cells count as absent",
+			values:  map[string]string{"name": "Tea", "price": "", "quantity": " ", "halal_status": "", "halal_expires_at": ""},
+			want:    Item{Name: "Tea", Unit: "pcs", Halal: Halal{Status: HalalUnknown}},
+			wantSet: []string{"name"},
+		},
+		{
+			name:    "unparsable numbers",
+			values:  map[string]string{"name": "Tea", "price": "cheap", "halal_status": "maybe"},
+			invalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, set, err := ImportRow{Row: 2, Values: tt.values}.Item()
+			if tt.invalid {
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("Item() error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Item() error = %v", err)
+			}
+			if !reflect.DeepEqual(*item, tt.want) {
+				t.Errorf("Item() = %+v, want %+v", *item, tt.want)
+			}
+			if got := slices.Sorted(maps.Keys(set)); !slices.Equal(got, tt.wantSet) {
+				t.Errorf("Item() set = %v, want %v", got, tt.wantSet)
+			}
+		})
+	}
+}
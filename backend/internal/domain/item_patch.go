@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Field error code for fields a client may not change through the request.
+const CodeReadOnly = "read_only"
+
+// ItemPatch is a JSON Merge Patch (RFC 7396) document for an item, keyed by
+// JSON field name. Members that are absent stay unchanged; null removes the
+// value, which for required fields is a validation error.
+type ItemPatch map[string]json.RawMessage
+
+// readOnlyItemFields cannot be patched. Quantity only changes through stock
+// adjustments and sales so every change is recorded.
+var readOnlyItemFields = map[string]string{
+	"id":            "id cannot be changed",
+	"quantity":      "quantity can only change through stock adjustments",
+	"version":       "version is managed by the server, send it as If-Match",
+	"created_at":    "created_at cannot be changed",
+	"updated_at":    "updated_at cannot be changed",
+	"archived_at":   "use the archive and restore endpoints instead",
+	"unit_cost":     "cost only changes through goods receipts",
+	"stock_value":   "stock_value follows stock movements",
+	"effective":     "effective settings are inherited from categories",
+	"scanned_unit":  "scanned_unit is only set by barcode lookups",
+	"is_halal":      "is_halal follows the halal certificate, set halal instead",
+	"image_url":     "upload an image attachment instead",
+	"thumbnail_url": "upload an image attachment instead",
+}
+
+// Apply changes item in place and validates the patched fields only, so
+// unrelated legacy data does not block an edit.
+func (p ItemPatch) Apply(item *Item) error {
+	verr := &ValidationError{}
+	unit := item.Unit
+	for field, raw := range p {
+		if msg, ok := readOnlyItemFields[field]; ok {
+			verr.Add(field, CodeReadOnly, msg)
+			continue
+		}
+		isNull := string(raw) == "null"
+		var err error
+		switch field {
+		case "name":
+			err = decodeRequired(raw, isNull, &item.Name)
+		case "barcode":
+			// Removing the barcode makes the service generate an internal one.
+			item.Barcode = ""
+			if !isNull {
+				err = json.Unmarshal(raw, &item.Barcode)
+			}
+		case "price":
+			err = decodeRequired(raw, isNull, &item.Price)
+		case "location":
+			item.Location = ""
+			if !isNull {
+				err = json.Unmarshal(raw, &item.Location)
+			}
+		case "halal":
+			// The object is replaced as a whole; null resets it to unknown.
+			// The uploaded document is kept, as it has its own endpoint.
+			doc := item.Halal.Document
+			item.Halal = Halal{}
+			if !isNull {
+				err = json.Unmarshal(raw, &item.Halal)
+			}
+			item.Halal.Document = doc
+		case "unit":
+			// null returns the item to the default unit.
+			item.Unit = ""
+			if !isNull {
+				err = json.Unmarshal(raw, &item.Unit)
+			}
+		case "allow_decimal":
+			err = decodeRequired(raw, isNull, &item.AllowDecimal)
+		case "units":
+			item.Units = nil
+			err = json.Unmarshal(raw, &item.Units)
+		case "brand_id":
+			item.BrandID = nil
+			err = json.Unmarshal(raw, &item.BrandID)
+		case "product_id":
+			item.ProductID = nil
+			err = json.Unmarshal(raw, &item.ProductID)
+		case "attributes":
+			item.Attributes = nil
+			err = json.Unmarshal(raw, &item.Attributes)
+		case "category_ids":
+			item.CategoryIDs = nil
+			err = json.Unmarshal(raw, &item.CategoryIDs)
+		case "tags":
+			item.Tags = nil
+			err = json.Unmarshal(raw, &item.Tags)
+		case "tax_rate":
+			// null returns the item to the category's setting.
+			item.TaxRate = nil
+			err = json.Unmarshal(raw, &item.TaxRate)
+		case "reorder_point":
+			item.ReorderPoint = nil
+			err = json.Unmarshal(raw, &item.ReorderPoint)
+		default:
+			verr.Add(field, CodeInvalid, "unknown field")
+			continue
+		}
+		if err != nil {
+			verr.Add(field, CodeInvalid, err.Error())
+		}
+	}
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+
+	item.NormalizeUnits()
+	item.Halal.Normalize()
+	if err := item.Validate(); err != nil {
+		if all, ok := err.(*ValidationError); ok {
+			for _, f := range all.Fields {
+				if _, patched := p[patchedField(f.Field)]; patched {
+					verr.Fields = append(verr.Fields, f)
+				}
+			}
+		}
+	}
+	// The stock on hand must still fit the unit after a change to it.
+	if _, ok := p["allow_decimal"]; ok && !item.AllowDecimal && !IsWhole(item.Quantity) {
+		verr.Add("allow_decimal", CodeInvalid, "item has a fractional quantity in stock")
+	}
+	if item.Unit != unit && item.Quantity != 0 {
+		verr.Add("unit", CodeInvalid, "unit cannot change while the item has stock")
+	}
+	return verr.Err()
+}
+
+// patchedField maps an indexed error field like units[1].factor to the
+// member of the patch it came from.
+func patchedField(field string) string {
+	if i := strings.IndexAny(field, "[."); i > 0 {
+		return field[:i]
+	}
+	return field
+}
+
+func decodeRequired(raw json.RawMessage, isNull bool, dst any) error {
+	if isNull {
+		return fmt.Errorf("cannot be removed")
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("has the wrong type")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "fmt"
+
+// LabelRequest asks for Quantity copies of an item's shelf label.
+type LabelRequest struct {
+	ItemID   int64 `json:"item_id"`
+	Quantity int   `json:"quantity"`
+}
+
+// Label limits. A print job is rendered in memory, so it must stay small.
+const (
+	MaxLabelCopies = 500  // Per item
+	MaxLabels      = 2000 // Per print job
+)
+
+// ValidateLabelRequests checks that at least one label with a positive
+// number of copies is requested, within the label limits.
+func ValidateLabelRequests(reqs []LabelRequest) error {
+	verr := &ValidationError{}
+	if len(reqs) == 0 {
+		verr.Add("items", CodeRequired, "at least one item is required")
+	}
+	total := 0
+	for i, req := range reqs {
+		if req.ItemID <= 0 {
+			verr.Add(fmt.Sprintf("items[%d].item_id", i), CodeRequired, "item is required")
+		}
+		switch {
+		case req.Quantity <= 0:
+			verr.Add(fmt.Sprintf("items[%d].quantity", i), CodeMin, "quantity must be at least 1")
+		case req.Quantity > MaxLabelCopies:
+			verr.Add(fmt.Sprintf("items[%d].quantity", i), CodeInvalid, fmt.Sprintf("quantity must be at most %d", MaxLabelCopies))
+		default:
+			total += req.Quantity
+		}
+	}
+	if total > MaxLabels {
+		verr.Add("items", CodeTooLong, fmt.Sprintf("at most %d labels per print job", MaxLabels))
+	}
+	return verr.Err()
+}
+
+// Label is a resolved label: the item to print and how many copies.
+type Label struct {
+	Item   *Item
+	Copies int
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MovementType says why stock changed.
+type MovementType string
+
+const (
+	MovementInitial    MovementType = "initial"    // Opening stock when the item is created
+	MovementSale       MovementType = "sale"       // Sold on a sales order
+	MovementAdjustment MovementType = "adjustment" // Write-off or count correction
+	MovementImport     MovementType = "import"     // Stock level set by a spreadsheet import
+	MovementReceipt    MovementType = "receipt"    // Goods received from a supplier at a purchase cost
+)
+
+// StockMovement is one entry in the append-only stock ledger. Summing the
+// deltas of an item gives its quantity.
+type StockMovement struct {
+	ID            int64        `json:"id"`
+	ItemID        int64        `json:"item_id"`
+	ItemName      string       `json:"item_name,omitempty"` // Only filled by Each
+	Type          MovementType `json:"type"`
+	Delta         float64      `json:"delta"` // In the item's base unit
+	QuantityAfter float64      `json:"quantity_after"`
+	// UnitCost is the purchase cost for receipts and the cost of goods for
+	// outgoing stock. Incoming stock without a cost is valued at the current
+	// average cost.
+	UnitCost   float64   `json:"unit_cost"`
+	ValueAfter float64   `json:"value_after"` // Stock value at cost after the movement
+	Location   string    `json:"location,omitempty"`
+	Reference  string    `json:"reference,omitempty"` // e.g. order:42, adjustment:7
+	UserID     string    `json:"user_id,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type MovementRepository interface {
+	Create(ctx context.Context, movement *StockMovement) error
+	ListByItem(ctx context.Context, itemID int64) ([]*StockMovement, error)
+	// QuantityAt returns the item's stock at the given time: its quantity
+	// less the movements booked since.
+	QuantityAt(ctx context.Context, itemID int64, at time.Time) (float64, error)
+	// Each streams the movements matching the filter, oldest first.
+	Each(ctx context.Context, filter MovementFilter, fn func(*StockMovement) error) error
+}
+
+// MovementFilter narrows movement exports; zero values match everything.
+type MovementFilter struct {
+	ItemID int64
+	Period DateRange
+}
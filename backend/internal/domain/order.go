@@ -2,17 +2,43 @@ package domain
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// Order statuses. An order completes when its last line is fulfilled and
+// goes back to pending if a line is unfulfilled again.
+const (
+	OrderPending   = "pending"
+	OrderCompleted = "completed"
+	OrderCancelled = "cancelled"
+)
+
+// Fulfilled reports whether every line of the order is fulfilled.
+func (o *SalesOrder) Fulfilled() bool {
+	for _, line := range o.Items {
+		if !line.IsFulfilled {
+			return false
+		}
+	}
+	return len(o.Items) > 0
+}
+
 type SalesOrder struct {
-	ID         int64             `json:"id"`
-	UserID     string            `json:"user_id"`
-	TotalPrice float64           `json:"total_price"`
-	Status     string            `json:"status"` // pending, completed, cancelled
-	CreatedAt  time.Time         `json:"created_at"`
-	UpdatedAt  time.Time         `json:"updated_at"`
-	Items      []*SalesOrderItem `json:"items,omitempty"`
+	ID            int64   `json:"id"`
+	UserID        string  `json:"user_id"`
+	StoreCode     string  `json:"store_code"`
+	InvoiceNumber string  `json:"invoice_number,omitempty"` // Sequential per store, assigned on create
+	TotalPrice    float64 `json:"total_price"`
+	Status        string  `json:"status"` // pending, completed, cancelled
+	// HalalOverride is set when the order mixes halal and non-halal items
+	// because the user allowed it.
+	HalalOverride bool              `json:"halal_override"`
+	Version       int64             `json:"version"` // Incremented on every change to the order or its lines
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	Items         []*SalesOrderItem `json:"items,omitempty"`
 }
 
 type SalesOrderItem struct {
@@ -20,15 +46,66 @@ type SalesOrderItem struct {
 	SalesOrderID int64   `json:"sales_order_id"`
 	ItemID       int64   `json:"item_id"`
 	ItemName     string  `json:"item_name,omitempty"` // For display
-	Quantity     int     `json:"quantity"`
+	Quantity     float64 `json:"quantity"`            // In the item's base unit
 	PriceAtSale  float64 `json:"price_at_sale"`
-	IsFulfilled  bool    `json:"is_fulfilled"`
+	// CostAtSale is the unit cost of goods sold; nil for lines sold before
+	// costs were tracked.
+	CostAtSale  *float64 `json:"cost_at_sale"`
+	IsFulfilled bool     `json:"is_fulfilled"`
+}
+
+// OrderLine is one requested line of a new order. The item is given by ID
+// or by a scanned barcode, which for a product resolves to the variant
+// carrying that barcode. Quantity is in Unit, which defaults to the unit of
+// the scanned barcode and otherwise to the item's base unit; a carton
+// barcode sells the pieces in a carton.
+type OrderLine struct {
+	ItemID   int64   `json:"item_id,omitempty"`
+	Barcode  string  `json:"barcode,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Quantity float64 `json:"quantity"`
+}
+
+// ValidateOrderLines checks the lines of a new order. Field names are
+// indexed (items[2].quantity) so a form can point at the offending row.
+func ValidateOrderLines(lines []OrderLine) error {
+	verr := &ValidationError{}
+	if len(lines) == 0 {
+		verr.Add("items", CodeRequired, "order must have at least one item")
+	}
+	for i, line := range lines {
+		if line.ItemID <= 0 && strings.TrimSpace(line.Barcode) == "" {
+			verr.Add(fmt.Sprintf("items[%d].item_id", i), CodeRequired, "item_id or barcode is required")
+		}
+		if line.Quantity <= 0 {
+			verr.Add(fmt.Sprintf("items[%d].quantity", i), CodeMin, "quantity must be positive")
+		}
+	}
+	return verr.Err()
 }
 
+// Lookups and updates of a missing row return an error matching ErrNotFound.
 type OrderRepository interface {
 	Create(ctx context.Context, order *SalesOrder) error
 	GetByID(ctx context.Context, id int64) (*SalesOrder, error)
 	List(ctx context.Context) ([]*SalesOrder, error)
-	UpdateStatus(ctx context.Context, id int64, status string) error
-	UpdateItemFulfillment(ctx context.Context, itemId int64, isFulfilled bool) error
+	// EachLine streams every line of the orders created in the range, in
+	// order of creation. The order passed with a line has no Items.
+	EachLine(ctx context.Context, period DateRange, fn func(*SalesOrder, *SalesOrderItem) error) error
+	// SetLineCosts stores CostAtSale of the given lines.
+	SetLineCosts(ctx context.Context, lines []*SalesOrderItem) error
+	// UpdateStatus and UpdateItemFulfillment bump the order version and return
+	// the new one with the change. A non-zero expectedVersion must match the stored version,
+	// otherwise an error matching ErrPreconditionFailed is returned.
+	UpdateStatus(ctx context.Context, id int64, status string, expectedVersion int64) (int64, error)
+	UpdateItemFulfillment(ctx context.Context, itemId int64, isFulfilled bool, expectedVersion int64) (*FulfillmentChange, error)
+}
+
+// FulfillmentChange is an order line whose fulfillment was toggled.
+type FulfillmentChange struct {
+	OrderID     int64 `json:"order_id"`
+	LineID      int64 `json:"line_id"`
+	ItemID      int64 `json:"item_id"`
+	IsFulfilled bool  `json:"is_fulfilled"`
+	Version     int64 `json:"version"` // The order's new version
 }
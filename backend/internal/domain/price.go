@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PriceChangeStatus tracks a price change from planned to in effect.
+type PriceChangeStatus string
+
+const (
+	PriceScheduled PriceChangeStatus = "scheduled" // Waiting for its effective time
+	PriceApplied   PriceChangeStatus = "applied"   // Written to the item
+	PriceCancelled PriceChangeStatus = "cancelled" // Withdrawn before it took effect
+)
+
+// PriceChange is one entry in an item's price timeline. Changes made
+// directly are stored as applied; planned ones wait as scheduled until the
+// price scheduler applies them.
+type PriceChange struct {
+	ID       int64    `json:"id"`
+	ItemID   int64    `json:"item_id"`
+	OldPrice *float64 `json:"old_price"` // Set once applied
+	Price    float64  `json:"price"`
+	Reason   string   `json:"reason,omitempty"`
+	// ChangedBy is the user who made or scheduled the change.
+	ChangedBy   string            `json:"changed_by,omitempty"`
+	Status      PriceChangeStatus `json:"status"`
+	EffectiveAt time.Time         `json:"effective_at"`
+	AppliedAt   *time.Time        `json:"applied_at"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// MaxPriceReasonLength bounds the free-text reason of a price change.
+const MaxPriceReasonLength = 500
+
+func (c *PriceChange) Validate() error {
+	verr := &ValidationError{}
+	if c.Price < 0 {
+		verr.Add("price", CodeMin, "price must not be negative")
+	}
+	if len(c.Reason) > MaxPriceReasonLength {
+		verr.Add("reason", CodeTooLong, fmt.Sprintf("reason must be at most %d characters", MaxPriceReasonLength))
+	}
+	return verr.Err()
+}
+
+type PriceRepository interface {
+	Create(ctx context.Context, change *PriceChange) error
+	// ListByItem returns the timeline of an item, latest effective first.
+	ListByItem(ctx context.Context, itemID int64) ([]*PriceChange, error)
+	GetByID(ctx context.Context, id int64) (*PriceChange, error)
+	// Due locks up to limit scheduled changes effective at or before at,
+	// oldest first. Changes locked by another transaction are skipped, so
+	// several servers can run the scheduler. Must run in a transaction.
+	Due(ctx context.Context, at time.Time, limit int) ([]*PriceChange, error)
+	// SetStatus stores Status, OldPrice and AppliedAt of a scheduled change.
+	// It fails with a conflict when the change is no longer scheduled.
+	SetStatus(ctx context.Context, change *PriceChange) error
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Product groups variants of the same goods, such as one drink in several
+// sizes and flavours. Each variant is an Item with its own barcode, price
+// and stock; the product only holds what they share.
+type Product struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Attributes name the dimensions variants differ in, e.g. size, flavour.
+	Attributes   []string `json:"attributes"`
+	TotalStock   float64  `json:"total_stock"`   // Sum over active variants
+	VariantCount int      `json:"variant_count"` // Number of active variants
+	// Variants is only filled when a single product is fetched.
+	Variants  []*Item   `json:"variants,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MaxProductAttributes bounds the variant dimensions of a product.
+const MaxProductAttributes = 5
+
+// Validate normalizes the attribute names to trimmed lowercase and checks
+// the product.
+func (p *Product) Validate() error {
+	verr := &ValidationError{}
+	validateName(verr, p.Name)
+	attrs := make([]string, 0, len(p.Attributes))
+	seen := make(map[string]bool, len(p.Attributes))
+	for _, a := range p.Attributes {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || seen[a] {
+			verr.Add("attributes", CodeInvalid, "attribute names must be unique and not empty")
+			break
+		}
+		seen[a] = true
+		attrs = append(attrs, a)
+	}
+	if len(attrs) > MaxProductAttributes {
+		verr.Add("attributes", CodeInvalid, fmt.Sprintf("a product can have at most %d attributes", MaxProductAttributes))
+	}
+	p.Attributes = attrs
+	return verr.Err()
+}
+
+// CheckVariant checks the attribute values of a variant against the
+// product: every attribute must have a value and no others are allowed.
+func (p *Product) CheckVariant(attrs map[string]string) error {
+	verr := &ValidationError{}
+	for _, name := range p.Attributes {
+		if strings.TrimSpace(attrs[name]) == "" {
+			verr.Add("attributes."+name, CodeRequired, fmt.Sprintf("%s is required for variants of %s", name, p.Name))
+		}
+	}
+	var unknown []string
+	for name := range attrs {
+		if !p.hasAttribute(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		verr.Add("attributes."+name, CodeInvalid, fmt.Sprintf("%s has no attribute %s", p.Name, name))
+	}
+	return verr.Err()
+}
+
+func (p *Product) hasAttribute(name string) bool {
+	for _, a := range p.Attributes {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeAttributes trims the attribute names and values of a variant and
+// lowercases the names, as Product.Validate does for the product's.
+func NormalizeAttributes(attrs map[string]string) map[string]string {
+	if len(attrs) == 0 {
+		return attrs
+	}
+	normalized := make(map[string]string, len(attrs))
+	for name, value := range attrs {
+		normalized[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return normalized
+}
+
+// SameVariant reports whether two variants have the same attribute values,
+// ignoring case and surrounding space.
+func SameVariant(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(b[k])) {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookups of a missing row return an error matching ErrNotFound.
+type ProductRepository interface {
+	Create(ctx context.Context, product *Product) error
+	Update(ctx context.Context, product *Product) error
+	// Delete fails with an error matching ErrConflict while the product has
+	// variants, archived ones included.
+	Delete(ctx context.Context, id int64) error
+	// GetByID and List fill TotalStock and VariantCount.
+	GetByID(ctx context.Context, id int64) (*Product, error)
+	List(ctx context.Context) ([]*Product, error)
+	// Lock locks the product row until the transaction ends, so checks
+	// across its variants do not race.
+	Lock(ctx context.Context, id int64) error
+}
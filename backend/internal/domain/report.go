@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SalesTotals sums order lines over a period.
+type SalesTotals struct {
+	Revenue float64 `json:"revenue"`
+	Orders  int     `json:"orders"`
+}
+
+// TopSeller ranks an item by what it sold over a period.
+type TopSeller struct {
+	ItemID   int64   `json:"item_id"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// StockSummary aggregates the active items. An item is low on stock at or
+// below its effective reorder point, or the store threshold without one.
+type StockSummary struct {
+	LowStockItems int     `json:"low_stock_items"`
+	RetailValue   float64 `json:"retail_value"`
+	CostValue     float64 `json:"cost_value"`
+}
+
+// Dashboard is the overview shown on the home screen. When Location is set,
+// sales count only lines of items stored there.
+type Dashboard struct {
+	Location             string      `json:"location,omitempty"`
+	Today                SalesTotals `json:"today"`
+	Week                 SalesTotals `json:"week"`  // Since Monday
+	Month                SalesTotals `json:"month"` // Since the 1st
+	PendingOrders        int         `json:"pending_orders"`
+	LowStockItems        int         `json:"low_stock_items"`
+	LowStockThreshold    int         `json:"low_stock_threshold"` // For items without a reorder point
+	InventoryValueCost   float64     `json:"inventory_value_cost"`
+	InventoryValueRetail float64     `json:"inventory_value_retail"`
+	HalalExpiredItems    int         `json:"halal_expired_items"` // Certified items with an expired certificate
+	TopSellers           []TopSeller `json:"top_sellers"`         // This month
+	GeneratedAt          time.Time   `json:"generated_at"`
+}
+
+// ReportFilter scopes a report. An empty Location means all locations;
+// sales are then attributed to the location of the item sold.
+type ReportFilter struct {
+	Period   DateRange
+	Location string
+}
+
+// Interval is the bucket size of a time series.
+type Interval string
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week" // ISO weeks, starting Monday
+	IntervalMonth Interval = "month"
+)
+
+// MaxSeriesBuckets bounds the length of a time series.
+const MaxSeriesBuckets = 1000
+
+// ParseInterval defaults to IntervalDay.
+func ParseInterval(s string) (Interval, error) {
+	switch i := Interval(s); i {
+	case "":
+		return IntervalDay, nil
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return i, nil
+	}
+	verr := &ValidationError{}
+	verr.Add("interval", CodeInvalid, "interval must be day, week or month")
+	return "", verr
+}
+
+// approxDuration is the shortest length of one bucket.
+func (i Interval) approxDuration() time.Duration {
+	switch i {
+	case IntervalWeek:
+		return 7 * 24 * time.Hour
+	case IntervalMonth:
+		return 28 * 24 * time.Hour
+	default:
+		return 23 * time.Hour // DST days
+	}
+}
+
+// Buckets estimates how many buckets cover the period, rounding up.
+func (i Interval) Buckets(period DateRange) int {
+	return int(period.To.Sub(period.From)/i.approxDuration()) + 1
+}
+
+// SalesBucket is one point of a sales time series. Buckets without sales
+// are included with zeros.
+type SalesBucket struct {
+	Start    time.Time `json:"start"`
+	Revenue  float64   `json:"revenue"`
+	Orders   int       `json:"orders"`
+	Quantity float64   `json:"quantity"`
+}
+
+// RankBy orders top sellers.
+type RankBy string
+
+const (
+	RankByQuantity RankBy = "quantity"
+	RankByRevenue  RankBy = "revenue"
+)
+
+// DeadStockItem is an item in stock that has not sold recently.
+type DeadStockItem struct {
+	ItemID     int64      `json:"item_id"`
+	Name       string     `json:"name"`
+	Location   string     `json:"location"`
+	Quantity   float64    `json:"quantity"`
+	Value      float64    `json:"value"` // Retail value of the stock on hand
+	LastSoldAt *time.Time `json:"last_sold_at"`
+}
+
+// SellThroughItem compares what sold in a period with what was left at its
+// end: rate = sold / (sold + on hand at the end).
+type SellThroughItem struct {
+	ItemID int64   `json:"item_id"`
+	Name   string  `json:"name"`
+	Sold   float64 `json:"sold"`
+	OnHand float64 `json:"on_hand"`
+	Rate   float64 `json:"rate"`
+}
+
+// ReportRepository computes aggregates in the database. An empty location
+// means all locations.
+type ReportRepository interface {
+	// SalesTotals returns one total per period, in the same order.
+	SalesTotals(ctx context.Context, location string, periods ...DateRange) ([]SalesTotals, error)
+	// PendingOrders counts orders with at least one unfulfilled line.
+	PendingOrders(ctx context.Context, location string) (int, error)
+	StockSummary(ctx context.Context, location string, lowStockThreshold int) (StockSummary, error)
+	// HalalAlerts lists certified items whose certificate expires before the
+	// given time, soonest first.
+	HalalAlerts(ctx context.Context, before time.Time, location string) ([]HalalAlert, error)
+	TopSellers(ctx context.Context, filter ReportFilter, by RankBy, limit int) ([]TopSeller, error)
+	// SalesSeries needs a closed period.
+	SalesSeries(ctx context.Context, filter ReportFilter, interval Interval) ([]SalesBucket, error)
+	// DeadStock lists active items in stock with no sales since the given time.
+	DeadStock(ctx context.Context, since time.Time, location string) ([]DeadStockItem, error)
+	// SellThrough reconstructs the stock at the end of the period from the
+	// movement ledger. It needs a closed period.
+	SellThrough(ctx context.Context, filter ReportFilter) ([]SellThroughItem, error)
+	// Margins groups lines sold with a recorded cost by item.
+	Margins(ctx context.Context, filter ReportFilter) ([]ItemMargin, error)
+	// ValuationAt takes each item's last movement before at.
+	ValuationAt(ctx context.Context, at time.Time, location string) ([]ItemValuation, error)
+}
@@ -0,0 +1,17 @@
+package domain
+
+// Store holds the details printed on receipts and invoices.
+// Code scopes invoice numbering, so every store has its own sequence.
+type Store struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Phone   string `json:"phone"`
+	TaxID   string `json:"tax_id"`
+}
+
+// Receipt bundles an order with the store it was sold from.
+type Receipt struct {
+	Store Store       `json:"store"`
+	Order *SalesOrder `json:"order"`
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyncEntity names the kinds of rows offline clients keep a copy of.
+type SyncEntity string
+
+const (
+	SyncItem  SyncEntity = "item"
+	SyncOrder SyncEntity = "order"
+)
+
+// SyncChange is a row written since a cursor. Every write stamps the row
+// with a change sequence: the ID of the writing transaction. A change is
+// only handed out once every transaction with a lower ID has finished, so
+// a client never skips a change that committed late.
+type SyncChange struct {
+	Entity  SyncEntity
+	ID      int64
+	Seq     int64
+	Deleted bool
+}
+
+// SyncCursor is the position of the last change a client received. Changes
+// are ordered by sequence, entity and ID.
+type SyncCursor struct {
+	Seq    int64
+	Entity SyncEntity
+	ID     int64
+}
+
+// String encodes the cursor for clients, which treat it as opaque.
+func (c SyncCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%s.%d", c.Seq, c.Entity, c.ID))
+}
+
+// ParseSyncCursor decodes a cursor from String. The empty cursor starts a
+// full sync.
+func ParseSyncCursor(s string) (SyncCursor, error) {
+	if s == "" {
+		return SyncCursor{}, nil
+	}
+	invalid := fmt.Errorf("invalid sync cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return SyncCursor{}, invalid
+	}
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 3 {
+		return SyncCursor{}, invalid
+	}
+	var c SyncCursor
+	if c.Seq, err = strconv.ParseInt(parts[0], 10, 64); err != nil || c.Seq < 0 {
+		return SyncCursor{}, invalid
+	}
+	if c.ID, err = strconv.ParseInt(parts[2], 10, 64); err != nil || c.ID < 0 {
+		return SyncCursor{}, invalid
+	}
+	c.Entity = SyncEntity(parts[1])
+	return c, nil
+}
+
+// SyncDeletion tells a client to drop its copy of a row.
+type SyncDeletion struct {
+	Entity SyncEntity `json:"entity"`
+	ID     int64      `json:"id"`
+}
+
+// SyncPage is one pull of changes. Clients store Cursor and pull again
+// with it, right away while HasMore is set.
+type SyncPage struct {
+	Cursor  string         `json:"cursor"`
+	HasMore bool           `json:"has_more"`
+	Items   []*Item        `json:"items"` // Archived items included
+	Orders  []*SalesOrder  `json:"orders"`
+	Deleted []SyncDeletion `json:"deleted"`
+}
+
+// Sync limits.
+const (
+	DefaultSyncPageSize = 200
+	MaxSyncPageSize     = 1000
+	MaxSyncOps          = 200
+	MaxSyncOpIDLength   = MaxIdempotencyKeyLength
+)
+
+// SyncOpType is an operation queued on a device while it was offline.
+type SyncOpType string
+
+const (
+	SyncOpSale       SyncOpType = "sale"       // A sales order
+	SyncOpCount      SyncOpType = "count"      // A stock count, booked as an adjustment
+	SyncOpAdjustment SyncOpType = "adjustment" // A stock adjustment with a reason
+)
+
+// DefaultCountReason is the adjustment reason of counts that give none.
+const DefaultCountReason = "miscount"
+
+// SyncOp is one queued operation. Which fields apply depends on Type:
+//
+//	sale        items, allow_mixed_halal
+//	count       item_id or barcode, quantity (counted), unit, reason, note, location
+//	adjustment  item_id or barcode, delta, unit, reason, note, location
+type SyncOp struct {
+	// ID is chosen by the device, e.g. a UUID, and makes pushing the op
+	// again harmless.
+	ID   string     `json:"op_id"`
+	Type SyncOpType `json:"type"`
+	// At is when the op happened on the device. A count is compared with
+	// the stock at that time, so sales and receipts booked since are kept.
+	At time.Time `json:"at"`
+
+	Items           []OrderLine `json:"items,omitempty"`
+	AllowMixedHalal bool        `json:"allow_mixed_halal,omitempty"`
+
+	ItemID   int64   `json:"item_id,omitempty"`
+	Barcode  string  `json:"barcode,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Quantity float64 `json:"quantity,omitempty"`
+	Delta    float64 `json:"delta,omitempty"`
+	Reason   string  `json:"reason,omitempty"`
+	Note     string  `json:"note,omitempty"`
+	Location string  `json:"location,omitempty"`
+}
+
+// Validate checks the fields the op's type needs.
+func (op *SyncOp) Validate() error {
+	verr := &ValidationError{}
+	op.ID = strings.TrimSpace(op.ID)
+	op.Barcode = strings.TrimSpace(op.Barcode)
+	if op.ID == "" {
+		verr.Add("op_id", CodeRequired, "op_id is required")
+	} else if len(op.ID) > MaxSyncOpIDLength {
+		verr.Add("op_id", CodeTooLong, fmt.Sprintf("op_id must be at most %d characters", MaxSyncOpIDLength))
+	}
+	switch op.Type {
+	case SyncOpSale:
+		var lines *ValidationError
+		if errors.As(ValidateOrderLines(op.Items), &lines) {
+			verr.Fields = append(verr.Fields, lines.Fields...)
+		}
+	case SyncOpCount, SyncOpAdjustment:
+		if op.ItemID <= 0 && op.Barcode == "" {
+			verr.Add("item_id", CodeRequired, "item_id or barcode is required")
+		}
+		if op.Type == SyncOpCount && op.Quantity < 0 {
+			verr.Add("quantity", CodeMin, "quantity must not be negative")
+		}
+		if op.Type == SyncOpAdjustment && op.Delta == 0 {
+			verr.Add("delta", CodeInvalid, "delta must not be zero")
+		}
+	default:
+		verr.Add("type", CodeInvalid, "type must be sale, count or adjustment")
+	}
+	return verr.Err()
+}
+
+// SyncOpStatus is the outcome of an op.
+type SyncOpStatus string
+
+const (
+	SyncApplied  SyncOpStatus = "applied"  // Done; for adjustments and counts, stock changed
+	SyncPending  SyncOpStatus = "pending"  // Recorded, waiting for a manager's approval
+	SyncRejected SyncOpStatus = "rejected" // Refused for good, e.g. not enough stock; do not push again
+	SyncFailed   SyncOpStatus = "failed"   // Not done because of a server problem; push again later
+)
+
+// SyncOpResult reports one op. Results of ops pushed again are replayed.
+type SyncOpResult struct {
+	OpID       string           `json:"op_id"`
+	Status     SyncOpStatus     `json:"status"`
+	Replayed   bool             `json:"replayed,omitempty"`
+	Order      *SalesOrder      `json:"order,omitempty"`
+	Adjustment *StockAdjustment `json:"adjustment,omitempty"`
+	Error      *OpError         `json:"error,omitempty"`
+}
+
+type SyncRepository interface {
+	// Horizon returns the change sequence below which every write has
+	// finished.
+	Horizon(ctx context.Context) (int64, error)
+	// Changes returns up to limit changes after the cursor with a sequence
+	// below horizon, in cursor order.
+	Changes(ctx context.Context, after SyncCursor, horizon int64, limit int) ([]SyncChange, error)
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Transactor runs fn atomically: every repository call made with the
+// context passed to fn commits or rolls back together.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultUnit is the base unit of items created without one.
+const DefaultUnit = "pcs"
+
+// Unit limits. Quantities are stored with QuantityDecimals places.
+const (
+	MaxUnitLength    = 20
+	MaxItemUnits     = 10
+	QuantityDecimals = 3
+)
+
+// ItemUnit is an alternate unit an item is bought or sold in, e.g. a carton
+// of 24 pieces. A unit with its own barcode is selected by scanning it.
+type ItemUnit struct {
+	Name    string  `json:"name"`
+	Factor  float64 `json:"factor"` // Base units per unit
+	Barcode string  `json:"barcode,omitempty"`
+}
+
+// RoundQuantity rounds a quantity to the precision stored in the database.
+func RoundQuantity(q float64) float64 {
+	scale := math.Pow10(QuantityDecimals)
+	return math.Round(q*scale) / scale
+}
+
+// IsWhole reports whether q has no fractional part at stored precision.
+func IsWhole(q float64) bool {
+	q = RoundQuantity(q)
+	return q == math.Trunc(q)
+}
+
+// FormatQuantity renders a quantity without trailing zeros, e.g. 2 or 1.25.
+func FormatQuantity(q float64) string {
+	s := fmt.Sprintf("%.*f", QuantityDecimals, RoundQuantity(q))
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// normalizeUnit lower-cases and trims a unit name so "Carton " and "carton"
+// are the same unit.
+func normalizeUnit(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeUnits defaults the base unit and cleans up unit names and
+// barcodes before validation.
+func (i *Item) NormalizeUnits() {
+	i.Unit = normalizeUnit(i.Unit)
+	if i.Unit == "" {
+		i.Unit = DefaultUnit
+	}
+	for k := range i.Units {
+		i.Units[k].Name = normalizeUnit(i.Units[k].Name)
+		i.Units[k].Barcode = strings.TrimSpace(i.Units[k].Barcode)
+	}
+}
+
+// validateUnits checks the base unit and the alternate units. Without
+// AllowDecimal every factor must be a whole number of base units.
+func (i *Item) validateUnits(verr *ValidationError) {
+	if len([]rune(i.Unit)) > MaxUnitLength {
+		verr.Add("unit", CodeTooLong, fmt.Sprintf("unit must be at most %d characters", MaxUnitLength))
+	}
+	if len(i.Units) > MaxItemUnits {
+		verr.Add("units", CodeInvalid, fmt.Sprintf("an item can have at most %d units", MaxItemUnits))
+	}
+	names := map[string]bool{i.Unit: true}
+	barcodes := map[string]bool{}
+	if i.Barcode != "" {
+		barcodes[NormalizeGTIN(i.Barcode)] = true
+	}
+	for k, u := range i.Units {
+		field := fmt.Sprintf("units[%d]", k)
+		switch {
+		case u.Name == "":
+			verr.Add(field+".name", CodeRequired, "unit name is required")
+		case len([]rune(u.Name)) > MaxUnitLength:
+			verr.Add(field+".name", CodeTooLong, fmt.Sprintf("unit name must be at most %d characters", MaxUnitLength))
+		case names[u.Name]:
+			verr.Add(field+".name", CodeInvalid, fmt.Sprintf("unit %q is defined twice", u.Name))
+		}
+		names[u.Name] = true
+
+		switch {
+		case u.Factor <= 0:
+			verr.Add(field+".factor", CodeMin, "factor must be positive")
+		case RoundQuantity(u.Factor) != u.Factor:
+			verr.Add(field+".factor", CodeInvalid, fmt.Sprintf("factor must have at most %d decimals", QuantityDecimals))
+		case !i.AllowDecimal && !IsWhole(u.Factor):
+			verr.Add(field+".factor", CodeInvalid, "factor must be a whole number unless allow_decimal is set")
+		}
+
+		if u.Barcode == "" {
+			continue
+		}
+		if err := checkBarcodeCharacters(u.Barcode); err != nil {
+			verr.Add(field+".barcode", CodeInvalid, err.Error())
+			continue
+		}
+		key := NormalizeGTIN(u.Barcode)
+		if barcodes[key] {
+			verr.Add(field+".barcode", CodeInvalid, "barcode is already used by this item")
+		}
+		barcodes[key] = true
+	}
+}
+
+// validateQuantity checks that q fits the stored precision and, unless the
+// item allows decimals, is a whole number.
+func (i *Item) validateQuantity(verr *ValidationError, field string, q float64) {
+	switch {
+	case RoundQuantity(q) != q:
+		verr.Add(field, CodeInvalid, fmt.Sprintf("quantity must have at most %d decimals", QuantityDecimals))
+	case !i.AllowDecimal && !IsWhole(q):
+		verr.Add(field, CodeInvalid, fmt.Sprintf("quantity must be a whole number of %s", i.Unit))
+	}
+}
+
+// UnitFactor returns the base units per unit. The empty name and the base
+// unit's name give 1.
+func (i *Item) UnitFactor(name string) (float64, bool) {
+	name = normalizeUnit(name)
+	if name == "" || name == i.Unit {
+		return 1, true
+	}
+	for _, u := range i.Units {
+		if u.Name == name {
+			return u.Factor, true
+		}
+	}
+	return 0, false
+}
+
+// ValidateNewUnitBarcodes enforces the GTIN check digit on unit barcodes
+// that none of the previous units carried, like ValidateNewBarcode does for
+// the item's own barcode.
+func (i *Item) ValidateNewUnitBarcodes(previous []ItemUnit) error {
+	stored := make(map[string]bool, len(previous))
+	for _, u := range previous {
+		stored[u.Barcode] = true
+	}
+	verr := &ValidationError{}
+	for k, u := range i.Units {
+		if u.Barcode == "" || stored[u.Barcode] {
+			continue
+		}
+		if _, err := ValidateBarcode(u.Barcode); err != nil {
+			verr.Add(fmt.Sprintf("units[%d].barcode", k), CodeInvalid, err.Error())
+		}
+	}
+	return verr.Err()
+}
+
+// UnitForBarcode returns the name of the alternate unit carrying the
+// barcode, or "" for the base unit.
+func (i *Item) UnitForBarcode(barcode string) string {
+	key := NormalizeGTIN(strings.TrimSpace(barcode))
+	for _, u := range i.Units {
+		if u.Barcode != "" && NormalizeGTIN(u.Barcode) == key {
+			return u.Name
+		}
+	}
+	return ""
+}
+
+// ToBase converts a quantity in the named unit to base units. field names
+// the quantity in validation errors.
+func (i *Item) ToBase(field string, quantity float64, unit string) (float64, error) {
+	verr := &ValidationError{}
+	factor, ok := i.UnitFactor(unit)
+	if !ok {
+		verr.Add(field, CodeInvalid, fmt.Sprintf("%s has no unit %q", i.Name, unit))
+		return 0, verr
+	}
+	if RoundQuantity(quantity) != quantity {
+		i.validateQuantity(verr, field, quantity)
+		return 0, verr
+	}
+	base := RoundQuantity(quantity * factor)
+	if base == 0 && quantity != 0 {
+		verr.Add(field, CodeInvalid, fmt.Sprintf("quantity is less than the smallest amount of %s stored", i.Unit))
+		return 0, verr
+	}
+	i.validateQuantity(verr, field, base)
+	if err := verr.Err(); err != nil {
+		return 0, err
+	}
+	return base, nil
+}
+
+// CheckUnitChange refuses to change the base unit of an item with stock on
+// hand: its quantity, movements, cost layers and unit factors are all
+// counted in that unit, and would silently change meaning.
+func (i *Item) CheckUnitChange(unit string) error {
+	if unit == i.Unit || i.Quantity == 0 {
+		return nil
+	}
+	verr := &ValidationError{}
+	verr.Add("unit", CodeInvalid, "unit cannot change while the item has stock")
+	return verr
+}
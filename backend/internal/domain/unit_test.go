@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func cartonItem() *Item {
+	return &Item{Name: "Cola", Unit: "pcs", Units: []ItemUnit{{Name: "carton", Factor: 24}}}
+}
+
+func weighedItem() *Item {
+	return &Item{Name: "Rice", Unit: "kg", AllowDecimal: true, Units: []ItemUnit{{Name: "g", Factor: 0.001}, {Name: "sack", Factor: 25}}}
+}
+
+// fieldCodes lists a validation error as "field:code" pairs.
+func fieldCodes(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("want a validation error, got %v", err)
+	}
+	var out []string
+	for _, f := range verr.Fields {
+		out = append(out, f.Field+":"+f.Code)
+	}
+	return out
+}
+
+func TestUnitFactor(t *testing.T) {
+	tests := []struct {
+		unit   string
+		factor float64
+		ok     bool
+	}{
+		{"", 1, true},
+		{"pcs", 1, true},
+		{" PCS ", 1, true},
+		{"carton", 24, true},
+		{"Carton", 24, true},
+		{"box", 0, false},
+	}
+	item := cartonItem()
+	for _, tt := range tests {
+		factor, ok := item.UnitFactor(tt.unit)
+		if factor != tt.factor || ok != tt.ok {
+			t.Errorf("UnitFactor(%q) = %v, %v; want %v, %v", tt.unit, factor, ok, tt.factor, tt.ok)
+		}
+	}
+}
+
+func TestToBase(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     *Item
+		quantity float64
+		unit     string
+		want     float64
+		errs     []string
+	}{
+		{"base unit", cartonItem(), 2, "", 2, nil},
+		{"alternate unit", cartonItem(), 2, "carton", 48, nil},
+		{"fraction of a unit giving whole pieces", cartonItem(), 1.5, "carton", 36, nil},
+		{"fraction of a unit giving part of a piece", cartonItem(), 0.1, "carton", 0, []string{"quantity:invalid"}},
+		{"fractional pieces", cartonItem(), 0.5, "pcs", 0, []string{"quantity:invalid"}},
+		{"too many decimals", cartonItem(), 1.0001, "pcs", 0, []string{"quantity:invalid"}},
+		{"unknown unit", cartonItem(), 1, "box", 0, []string{"quantity:invalid"}},
+		{"grams to kilograms", weighedItem(), 250, "g", 0.25, nil},
+		{"sacks to kilograms", weighedItem(), 0.5, "sack", 12.5, nil},
+		{"kilograms with decimals", weighedItem(), 1.125, "kg", 1.125, nil},
+		{"kilograms with too many decimals", weighedItem(), 0.1234, "kg", 0, []string{"quantity:invalid"}},
+		{"grams below stored precision", weighedItem(), 0.4, "g", 0, []string{"quantity:invalid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.item.ToBase("quantity", tt.quantity, tt.unit)
+			if errs := fieldCodes(t, err); !slices.Equal(errs, tt.errs) {
+				t.Fatalf("errors = %v, want %v", errs, tt.errs)
+			}
+			if got != tt.want {
+				t.Errorf("ToBase(%v, %q) = %v, want %v", tt.quantity, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUnits(t *testing.T) {
+	manyUnits := make([]ItemUnit, MaxItemUnits+1)
+	for k := range manyUnits {
+		manyUnits[k] = ItemUnit{Name: fmt.Sprintf("pack%d", k), Factor: float64(k + 2)}
+	}
+	tests := []struct {
+		name string
+		item Item
+		errs []string
+	}{
+		{"valid", Item{Unit: "pcs", Barcode: "4006381333931", Units: []ItemUnit{{Name: "carton", Factor: 24, Barcode: "96385074"}}}, nil},
+		{"base unit too long", Item{Unit: strings.Repeat("x", MaxUnitLength+1)}, []string{"unit:too_long"}},
+		{"too many units", Item{Unit: "pcs", Units: manyUnits}, []string{"units:invalid"}},
+		{"missing name", Item{Unit: "pcs", Units: []ItemUnit{{Factor: 2}}}, []string{"units[0].name:required"}},
+		{"name of the base unit", Item{Unit: "pcs", Units: []ItemUnit{{Name: "pcs", Factor: 2}}}, []string{"units[0].name:invalid"}},
+		{"name defined twice", Item{Unit: "pcs", Units: []ItemUnit{{Name: "box", Factor: 6}, {Name: "box", Factor: 12}}}, []string{"units[1].name:invalid"}},
+		{"zero factor", Item{Unit: "pcs", Units: []ItemUnit{{Name: "box", Factor: 0}}}, []string{"units[0].factor:min"}},
+		{"fractional factor", Item{Unit: "pcs", Units: []ItemUnit{{Name: "box", Factor: 1.5}}}, []string{"units[0].factor:invalid"}},
+		{"fractional factor with decimals allowed", Item{Unit: "kg", AllowDecimal: true, Units: []ItemUnit{{Name: "bag", Factor: 1.5}}}, nil},
+		{"factor below stored precision", Item{Unit: "kg", AllowDecimal: true, Units: []ItemUnit{{Name: "mg", Factor: 0.000001}}}, []string{"units[0].factor:invalid"}},
+		{"space in barcode", Item{Unit: "pcs", Units: []ItemUnit{{Name: "box", Factor: 6, Barcode: "AB 123"}}}, []string{"units[0].barcode:invalid"}},
+		{"bad check digit is left to new barcodes", Item{Unit: "pcs", Units: []ItemUnit{{Name: "box", Factor: 6, Barcode: "4006381333932"}}}, nil},
+		{"item barcode with leading zero", Item{Unit: "pcs", Barcode: "4006381333931", Units: []ItemUnit{{Name: "box", Factor: 6, Barcode: "04006381333931"}}}, []string{"units[0].barcode:invalid"}},
+		{"barcode on two units", Item{Unit: "pcs", Units: []ItemUnit{{Name: "box", Factor: 6, Barcode: "96385074"}, {Name: "crate", Factor: 12, Barcode: "96385074"}}}, []string{"units[1].barcode:invalid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verr := &ValidationError{}
+			tt.item.validateUnits(verr)
+			if errs := fieldCodes(t, verr.Err()); !slices.Equal(errs, tt.errs) {
+				t.Errorf("errors = %v, want %v", errs, tt.errs)
+			}
+		})
+	}
+}
+
+func TestValidateNewUnitBarcodes(t *testing.T) {
+	stored := []ItemUnit{{Name: "box", Factor: 6, Barcode: "4006381333932"}}
+	tests := []struct {
+		name  string
+		units []ItemUnit
+		errs  []string
+	}{
+		{"stored barcode is kept as is", stored, nil},
+		{"new barcode with a bad check digit", []ItemUnit{{Name: "box", Factor: 6, Barcode: "96385075"}}, []string{"units[0].barcode:invalid"}},
+		{"new valid barcode", []ItemUnit{stored[0], {Name: "crate", Factor: 12, Barcode: "96385074"}}, nil},
+		{"no barcode", []ItemUnit{{Name: "crate", Factor: 12}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Item{Unit: "pcs", Units: tt.units}
+			if errs := fieldCodes(t, item.ValidateNewUnitBarcodes(stored)); !slices.Equal(errs, tt.errs) {
+				t.Errorf("errors = %v, want %v", errs, tt.errs)
+			}
+		})
+	}
+}
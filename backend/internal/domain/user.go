@@ -14,8 +14,29 @@ type User struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Lookups and updates of a missing row return an error matching ErrNotFound.
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	GetByID(ctx context.Context, id string) (*User, error)
 }
+
+// Roles, from least to most privileged.
+const (
+	RoleUser    = "user"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+// HasRole reports whether the user has one of the given roles.
+func (u *User) HasRole(roles ...string) bool {
+	if u == nil {
+		return false
+	}
+	for _, r := range roles {
+		if u.Role == r {
+			return true
+		}
+	}
+	return false
+}
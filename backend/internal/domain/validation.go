@@ -0,0 +1,52 @@
+package domain
+
+import "strings"
+
+// Field error codes returned to clients. They are stable so the frontend
+// can map them to its own messages.
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+	CodeMin      = "min"
+	CodeTooLong  = "too_long"
+)
+
+// FieldError describes one invalid field of an entity or request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every field error found, so clients can show them
+// all at once instead of one per round trip.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Is makes errors.Is(err, ErrValidation) true for field validation errors.
+func (e *ValidationError) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == KindValidation
+}
+
+// Add records a field error.
+func (e *ValidationError) Add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err returns e when at least one field error was added, otherwise nil.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OutboxEntry is a committed event waiting to be handed to webhooks. The
+// outbox is written in the same transaction as the change, so an event is
+// recorded if and only if the change committed.
+type OutboxEntry struct {
+	ID    int64
+	Event Event
+}
+
+type OutboxRepository interface {
+	// Append stores the events; call it inside the change's transaction.
+	Append(ctx context.Context, events []Event) error
+	// Pending returns up to limit undispatched entries, oldest first, and
+	// locks them against other dispatchers until the transaction ends.
+	Pending(ctx context.Context, limit int) ([]OutboxEntry, error)
+	MarkDispatched(ctx context.Context, ids []int64) error
+	// DeleteDispatched deletes the entries dispatched before the given time
+	// that no delivery refers to any more, and returns how many it deleted.
+	DeleteDispatched(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Webhook is a subscriber URL that receives events as signed POSTs.
+type Webhook struct {
+	ID  int64  `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the deliveries. It is only returned when the webhook is
+	// created or the secret is rotated.
+	Secret string `json:"secret,omitempty"`
+	// Topics and Location filter the events like a stream subscription.
+	Topics      []EventTopic `json:"topics"`
+	Location    string       `json:"location,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Active      bool         `json:"active"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Webhook limits.
+const (
+	MaxWebhookURLLength = 2000
+	MinWebhookSecret    = 16
+)
+
+func (w *Webhook) Filter() EventFilter {
+	return EventFilter{Topics: w.Topics, Location: w.Location}
+}
+
+func (w *Webhook) Validate() error {
+	verr := &ValidationError{}
+	w.URL = strings.TrimSpace(w.URL)
+	w.Location = strings.TrimSpace(w.Location)
+	if w.Topics == nil {
+		w.Topics = []EventTopic{}
+	}
+	u, err := url.Parse(w.URL)
+	switch {
+	case w.URL == "":
+		verr.Add("url", CodeRequired, "url is required")
+	case len(w.URL) > MaxWebhookURLLength:
+		verr.Add("url", CodeTooLong, fmt.Sprintf("url must be at most %d characters", MaxWebhookURLLength))
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		verr.Add("url", CodeInvalid, "url must be an absolute http or https URL")
+	case u.User != nil:
+		verr.Add("url", CodeInvalid, "url must not contain credentials")
+	}
+	if w.Secret != "" && len(w.Secret) < MinWebhookSecret {
+		verr.Add("secret", CodeMin, fmt.Sprintf("secret must be at least %d characters", MinWebhookSecret))
+	}
+	for i, t := range w.Topics {
+		if !slices.Contains(EventTopics, t) {
+			verr.Add(fmt.Sprintf("topics[%d]", i), CodeInvalid, "topic must be item, stock or order")
+		}
+	}
+	return verr.Err()
+}
+
+// DeliveryStatus tracks a webhook delivery.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"   // Waiting for its first or next attempt
+	DeliveryDelivered DeliveryStatus = "delivered" // Answered with 2xx
+	DeliveryDead      DeliveryStatus = "dead"      // Out of attempts; retried only by hand
+)
+
+func ParseDeliveryStatus(s string) (DeliveryStatus, error) {
+	switch st := DeliveryStatus(s); st {
+	case "", DeliveryPending, DeliveryDelivered, DeliveryDead:
+		return st, nil
+	}
+	return "", fmt.Errorf("unknown delivery status %q, use pending, delivered or dead", s)
+}
+
+// WebhookDelivery is one event on its way to one webhook.
+type WebhookDelivery struct {
+	ID            int64          `json:"id"`
+	WebhookID     int64          `json:"webhook_id"`
+	EventID       int64          `json:"event_id"` // The outbox entry
+	EventType     string         `json:"event_type"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt *time.Time     `json:"next_attempt_at,omitempty"`
+	LastError     string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	DeliveredAt   *time.Time     `json:"delivered_at,omitempty"`
+	// Log lists the attempts, newest first, on single deliveries only.
+	Log []WebhookAttempt `json:"log,omitempty"`
+
+	// Set on claimed deliveries for sending. Event.ID is the outbox ID.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+	Event  Event  `json:"-"`
+}
+
+// WebhookAttempt is one entry of the delivery log.
+type WebhookAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"` // 0 when no response arrived
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	At         time.Time `json:"at"`
+}
+
+// DeliveryFilter narrows delivery listings. Zero values match everything.
+type DeliveryFilter struct {
+	WebhookID int64
+	Status    DeliveryStatus
+	Limit     int
+}
+
+// WebhookBackoff is the wait before retrying after the given number of
+// failed attempts: 30s doubling up to 6h.
+func WebhookBackoff(attempts int) time.Duration {
+	const base, limit = 30 * time.Second, 6 * time.Hour
+	d := base
+	for i := 1; i < attempts && d < limit; i++ {
+		d *= 2
+	}
+	return min(d, limit)
+}
+
+// Lookups of a missing webhook or delivery return an error matching
+// ErrNotFound.
+type WebhookRepository interface {
+	// Create and Update store the secret; the others never return it.
+	Create(ctx context.Context, w *Webhook) error
+	Update(ctx context.Context, w *Webhook) error
+	// Delete removes the webhook with its deliveries and their log.
+	Delete(ctx context.Context, id int64) error
+	GetByID(ctx context.Context, id int64) (*Webhook, error)
+	List(ctx context.Context) ([]*Webhook, error)
+
+	// CreateDeliveries queues the outbox entry for each webhook.
+	CreateDeliveries(ctx context.Context, entry OutboxEntry, webhookIDs []int64) error
+	// ClaimDeliveries returns up to limit pending deliveries that are due,
+	// with URL, Secret and Event set, and pushes their next attempt back
+	// by lease so no other dispatcher takes them meanwhile.
+	ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) ([]*WebhookDelivery, error)
+	// RecordAttempt stores d's new status, attempts, next attempt and error
+	// and appends the attempt to the log.
+	RecordAttempt(ctx context.Context, d *WebhookDelivery, attempt WebhookAttempt) error
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*WebhookDelivery, error)
+	// GetDelivery returns the delivery with its log.
+	GetDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+	// Requeue makes a dead delivery pending again with a fresh set of
+	// attempts. Other deliveries fail with an error matching ErrConflict.
+	Requeue(ctx context.Context, id int64) (*WebhookDelivery, error)
+	// DeleteFinished deletes, with their log, the delivered and dead
+	// deliveries last attempted before the given time and those of deleted
+	// webhooks, and returns how many it deleted.
+	DeleteFinished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebhookSender posts a delivery's event, signed with its secret, and
+// returns the response status. Any error or non-2xx status is a failure.
+type WebhookSender interface {
+	Send(ctx context.Context, d *WebhookDelivery) (status int, err error)
+}
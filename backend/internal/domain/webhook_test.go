@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour}, // 512m is capped
+		{12, 6 * time.Hour},
+		{1000, 6 * time.Hour}, // Stops doubling at the cap instead of overflowing
+	}
+	for _, tt := range tests {
+		if got := WebhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("WebhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AdjustmentHandler struct {
+	adjustmentService *application.AdjustmentService
+	idempotency       *Idempotency
+}
+
+func NewAdjustmentHandler(adjustmentService *application.AdjustmentService, idempotency *Idempotency) *AdjustmentHandler {
+	return &AdjustmentHandler{adjustmentService: adjustmentService, idempotency: idempotency}
+}
+
+type CreateAdjustmentRequest struct {
+	Delta    float64 `json:"delta"`
+	Reason   string  `json:"reason"`
+	Note     string  `json:"note"`
+	Location string  `json:"location"`
+}
+
+// CreateAdjustment answers 201 when the adjustment was applied and 202 when
+// it is waiting for approval.
+func (h *AdjustmentHandler) CreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	var req CreateAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	adj := &domain.StockAdjustment{
+		ItemID:   itemID,
+		Delta:    req.Delta,
+		Reason:   req.Reason,
+		Note:     req.Note,
+		Location: req.Location,
+	}
+	if err := h.adjustmentService.RequestAdjustment(r.Context(), currentUser(r), adj); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if adj.Status == domain.AdjustmentPending {
+		w.WriteHeader(http.StatusAccepted)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(adj)
+}
+
+func (h *AdjustmentHandler) ListItemAdjustments(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	h.list(w, r, itemID)
+}
+
+// ListAdjustments is the approval queue when called with ?status=pending.
+func (h *AdjustmentHandler) ListAdjustments(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, 0)
+}
+
+func (h *AdjustmentHandler) list(w http.ResponseWriter, r *http.Request, itemID int64) {
+	status := domain.AdjustmentStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", domain.AdjustmentPending, domain.AdjustmentApplied, domain.AdjustmentRejected:
+	default:
+		writeBadRequest(w, r, "Invalid status")
+		return
+	}
+
+	adjustments, err := h.adjustmentService.ListAdjustments(r.Context(), itemID, status)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adjustments)
+}
+
+func (h *AdjustmentHandler) ListMovements(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+
+	movements, err := h.adjustmentService.ListMovements(r.Context(), itemID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// GetPolicy lists the reason codes and approval thresholds so clients can
+// build the adjustment form.
+func (h *AdjustmentHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.adjustmentService.Policy())
+}
+
+type ReviewAdjustmentRequest struct {
+	Note string `json:"note"`
+}
+
+func (h *AdjustmentHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.adjustmentService.ApproveAdjustment)
+}
+
+func (h *AdjustmentHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.adjustmentService.RejectAdjustment)
+}
+
+func (h *AdjustmentHandler) review(w http.ResponseWriter, r *http.Request, decide func(context.Context, *domain.User, int64, string) (*domain.StockAdjustment, error)) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	var req ReviewAdjustmentRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, r, "Invalid request body")
+			return
+		}
+	}
+
+	adj, err := decide(r.Context(), currentUser(r), id, req.Note)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adj)
+}
+
+// ReceiveGoods books a supplier delivery with its purchase cost. Without an
+// item ID in the path the item is found by the receipt's barcode, so a
+// scanned carton barcode receives cartons.
+func (h *AdjustmentHandler) ReceiveGoods(w http.ResponseWriter, r *http.Request) {
+	var itemID int64
+	if param := chi.URLParam(r, "id"); param != "" {
+		var err error
+		if itemID, err = strconv.ParseInt(param, 10, 64); err != nil {
+			writeBadRequest(w, r, "Invalid ID")
+			return
+		}
+	}
+	var receipt domain.GoodsReceipt
+	if err := json.NewDecoder(r.Body).Decode(&receipt); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	movement, err := h.adjustmentService.ReceiveGoods(r.Context(), currentUser(r), itemID, &receipt)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// RegisterItemRoutes adds the per-item endpoints to the inventory router.
+func (h *AdjustmentHandler) RegisterItemRoutes(r chi.Router) {
+	r.Get("/{id}/movements", h.ListMovements)
+	r.With(RequireUser).Get("/{id}/adjustments", h.ListItemAdjustments)
+	r.With(RequireUser, h.idempotency.Middleware).Post("/{id}/adjustments", h.CreateAdjustment)
+	r.With(RequireUser, h.idempotency.Middleware).Post("/{id}/receipts", h.ReceiveGoods)
+	r.With(RequireUser, h.idempotency.Middleware).Post("/receipts", h.ReceiveGoods)
+}
+
+func (h *AdjustmentHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(RequireUser)
+	r.Get("/", h.ListAdjustments)
+	r.Get("/policy", h.GetPolicy)
+	r.Group(func(r chi.Router) {
+		r.Use(RequireRole(domain.RoleManager, domain.RoleAdmin))
+		r.Post("/{id}/approve", h.Approve)
+		r.Post("/{id}/reject", h.Reject)
+	})
+	return r
+}
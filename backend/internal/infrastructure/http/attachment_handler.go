@@ -0,0 +1,154 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+type AttachmentHandler struct {
+	attachmentService *application.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService *application.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: attachmentService}
+}
+
+func (h *AttachmentHandler) ListItemAttachments(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	list, err := h.attachmentService.ListAttachments(r.Context(), itemID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// UploadAttachment accepts a multipart upload with a single "file" field.
+// Images (JPEG, PNG, GIF) get a thumbnail; other files are kept as
+// documents.
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	// Leave room for the multipart framing around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxAttachmentSize+1<<20)
+	if err := r.ParseMultipartForm(domain.MaxAttachmentSize); err != nil {
+		writeBadRequest(w, r, "Invalid multipart form or file too large")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeBadRequest(w, r, "file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeBadRequest(w, r, "Failed to read file")
+		return
+	}
+
+	a, err := h.attachmentService.Upload(r.Context(), currentUser(r), itemID, header.Filename, data)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", a.URL)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+// GetAttachment serves the file, or with ?meta=true its description.
+func (h *AttachmentHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	if r.URL.Query().Get("meta") == "true" {
+		a, err := h.attachmentService.GetAttachment(r.Context(), id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a)
+		return
+	}
+	h.serve(w, r, id, false)
+}
+
+func (h *AttachmentHandler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	h.serve(w, r, id, true)
+}
+
+// serve writes the attachment's data. Stored files never change, so they
+// can be cached for long. Only images are shown inline; everything else is
+// downloaded so an uploaded page cannot run in the API's origin.
+func (h *AttachmentHandler) serve(w http.ResponseWriter, r *http.Request, id int64, thumbnail bool) {
+	a, data, err := h.attachmentService.Content(r.Context(), id, thumbnail)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	disposition := "attachment"
+	if a.Kind == domain.AttachmentImage && strings.HasPrefix(a.ContentType, "image/") {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Header().Set("Last-Modified", a.UploadedAt.UTC().Format(http.TimeFormat))
+	w.Write(data)
+}
+
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	if err := h.attachmentService.DeleteAttachment(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegisterItemRoutes adds the per-item endpoints to the inventory router.
+func (h *AttachmentHandler) RegisterItemRoutes(r chi.Router) {
+	r.Get("/{id}/attachments", h.ListItemAttachments)
+	r.With(RequireUser).Post("/{id}/attachments", h.UploadAttachment)
+}
+
+func (h *AttachmentHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/{id}", h.GetAttachment)
+	r.Get("/{id}/thumbnail", h.GetThumbnail)
+	r.With(RequireUser).Delete("/{id}", h.DeleteAttachment)
+	return r
+}
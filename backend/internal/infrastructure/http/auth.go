@@ -0,0 +1,124 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"multi-inventory/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type userKey struct{}
+
+// Authenticator issues and verifies the HS256 bearer tokens returned by login.
+type Authenticator struct {
+	secret []byte
+	ttl    time.Duration
+	users  domain.UserRepository
+}
+
+func NewAuthenticator(secret []byte, ttl time.Duration, users domain.UserRepository) *Authenticator {
+	return &Authenticator{secret: secret, ttl: ttl, users: users}
+}
+
+// Issue signs a token for the user. Only the user id is embedded; the role is
+// looked up on every request so demotions take effect immediately.
+func (a *Authenticator) Issue(user *domain.User) (string, time.Time, error) {
+	expires := time.Now().Add(a.ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   user.ID,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expires),
+	})
+	signed, err := token.SignedString(a.secret)
+	return signed, expires, err
+}
+
+// Middleware attaches the user to the request context when a bearer token is
+// present. Requests without a token pass through anonymously; a token that is
+// invalid or expired is rejected.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := a.verify(r.Context(), raw)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// QueryToken accepts the token as ?access_token= on requests without an
+// Authorization header. It is meant for event streams only: the browser's
+// EventSource cannot set headers, and URLs end up in logs. Logger masks the
+// token in ours; proxies in front of the server need the same care.
+func (a *Authenticator) QueryToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.URL.Query().Get("access_token")
+		if currentUser(r) != nil || raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := a.verify(r.Context(), raw)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+func (a *Authenticator) verify(ctx context.Context, raw string) (*domain.User, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, domain.NewUnauthorized("invalid or expired token")
+	}
+	user, err := a.users.GetByID(ctx, claims.Subject)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.NewUnauthorized("invalid or expired token")
+	}
+	return user, err
+}
+
+// currentUser returns the authenticated user, or nil for anonymous requests.
+func currentUser(r *http.Request) *domain.User {
+	user, _ := r.Context().Value(userKey{}).(*domain.User)
+	return user
+}
+
+// RequireUser rejects anonymous requests with 401.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if currentUser(r) == nil {
+			writeError(w, r, domain.NewUnauthorized("authentication required"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole rejects anonymous requests with 401 and users without one of
+// the roles with 403.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !currentUser(r).HasRole(roles...) {
+				writeError(w, r, domain.NewForbidden("insufficient role"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
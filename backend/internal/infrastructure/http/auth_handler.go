@@ -2,18 +2,22 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type AuthHandler struct {
 	authService *application.AuthService
+	auth        *Authenticator
 }
 
-func NewAuthHandler(authService *application.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *application.AuthService, auth *Authenticator) *AuthHandler {
+	return &AuthHandler{authService: authService, auth: auth}
 }
 
 type RegisterRequest struct {
@@ -21,16 +25,35 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 }
 
+// MinPasswordLength is enforced on registration only, so existing accounts
+// keep working.
+const MinPasswordLength = 8
+
+func (req *RegisterRequest) Validate() error {
+	verr := &domain.ValidationError{}
+	if strings.TrimSpace(req.Username) == "" {
+		verr.Add("username", domain.CodeRequired, "username is required")
+	}
+	if len(req.Password) < MinPasswordLength {
+		verr.Add("password", domain.CodeMin, fmt.Sprintf("password must be at least %d characters", MinPasswordLength))
+	}
+	return verr.Err()
+}
+
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	user, err := h.authService.Register(r.Context(), req.Username, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -50,23 +73,28 @@ type LoginRequest struct {
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
 	user, err := h.authService.Login(r.Context(), req.Username, req.Password)
 	if err != nil {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		writeError(w, r, err)
+		return
+	}
+
+	token, expires, err := h.auth.Issue(user)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	// TODO: Generate JWT token here
-	// For MVP, return user info and a dummy token
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
-		"message": "login successful",
-		"user":    user,
-		"token":   "dummy-token",
+		"message":    "login successful",
+		"user":       user,
+		"token":      token,
+		"expires_at": expires,
 	})
 }
 
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+type BatchHandler struct {
+	batchService *application.BatchService
+}
+
+func NewBatchHandler(batchService *application.BatchService) *BatchHandler {
+	return &BatchHandler{batchService: batchService}
+}
+
+// RunBatch answers 200 with a result per operation, also when some failed
+// or an atomic batch was rolled back.
+func (h *BatchHandler) RunBatch(w http.ResponseWriter, r *http.Request) {
+	var req domain.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	result, err := h.batchService.Run(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *BatchHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(RequireUser)
+	r.Post("/", h.RunBatch)
+	return r
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CatalogHandler struct {
+	catalogService *application.CatalogService
+}
+
+func NewCatalogHandler(catalogService *application.CatalogService) *CatalogHandler {
+	return &CatalogHandler{catalogService: catalogService}
+}
+
+// ListCategories returns the tree as a flat list; clients nest it by
+// parent_id.
+func (h *CatalogHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.catalogService.ListCategories(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+func (h *CatalogHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	category, err := h.catalogService.GetCategory(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CatalogHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var category domain.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	if err := h.catalogService.CreateCategory(r.Context(), &category); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CatalogHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	var category domain.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	category.ID = id
+	if err := h.catalogService.UpdateCategory(r.Context(), &category); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CatalogHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	if err := h.catalogService.DeleteCategory(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CatalogHandler) ListBrands(w http.ResponseWriter, r *http.Request) {
+	brands, err := h.catalogService.ListBrands(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brands)
+}
+
+func (h *CatalogHandler) CreateBrand(w http.ResponseWriter, r *http.Request) {
+	var brand domain.Brand
+	if err := json.NewDecoder(r.Body).Decode(&brand); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	if err := h.catalogService.CreateBrand(r.Context(), &brand); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(brand)
+}
+
+func (h *CatalogHandler) UpdateBrand(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	var brand domain.Brand
+	if err := json.NewDecoder(r.Body).Decode(&brand); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	brand.ID = id
+	if err := h.catalogService.UpdateBrand(r.Context(), &brand); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brand)
+}
+
+func (h *CatalogHandler) DeleteBrand(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	if err := h.catalogService.DeleteBrand(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CatalogHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.catalogService.ListTags(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// CategoryRoutes is mounted at /api/categories.
+func (h *CatalogHandler) CategoryRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.ListCategories)
+	r.Get("/{id}", h.GetCategory)
+	r.Group(func(r chi.Router) {
+		r.Use(RequireUser)
+		r.Post("/", h.CreateCategory)
+		r.Put("/{id}", h.UpdateCategory)
+		r.Delete("/{id}", h.DeleteCategory)
+	})
+	return r
+}
+
+// BrandRoutes is mounted at /api/brands.
+func (h *CatalogHandler) BrandRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.ListBrands)
+	r.Group(func(r chi.Router) {
+		r.Use(RequireUser)
+		r.Post("/", h.CreateBrand)
+		r.Put("/{id}", h.UpdateBrand)
+		r.Delete("/{id}", h.DeleteBrand)
+	})
+	return r
+}
+
+// TagRoutes is mounted at /api/tags. Tags are created by setting them on
+// items.
+func (h *CatalogHandler) TagRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.ListTags)
+	return r
+}
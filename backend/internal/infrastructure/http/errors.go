@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"multi-inventory/internal/domain"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Error codes for failures detected in the HTTP layer itself. Domain errors
+// use their domain.ErrorKind as the code.
+const (
+	codeBadRequest = "bad_request"
+	codeInternal   = "internal_error"
+)
+
+// errorBody is the stable JSON envelope for every error response:
+//
+//	{"error": {"code": "not_found", "message": "item not found", "request_id": "..."}}
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code      string              `json:"code"`
+	Message   string              `json:"message"`
+	RequestID string              `json:"request_id,omitempty"`
+	Fields    []domain.FieldError `json:"fields,omitempty"`
+}
+
+var kindStatus = map[domain.ErrorKind]int{
+	domain.KindNotFound:           http.StatusNotFound,
+	domain.KindConflict:           http.StatusConflict,
+	domain.KindValidation:         http.StatusUnprocessableEntity,
+	domain.KindInsufficientStock:  http.StatusConflict,
+	domain.KindForbidden:          http.StatusForbidden,
+	domain.KindUnauthorized:       http.StatusUnauthorized,
+	domain.KindPreconditionFailed: http.StatusPreconditionFailed,
+}
+
+// writeError maps err to a status code and writes the JSON envelope.
+// Errors that are not domain errors are logged and reported as a generic
+// 500 so internals do not leak to clients.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	detail := errorDetail{RequestID: requestID(r)}
+
+	var verr *domain.ValidationError
+	var derr *domain.Error
+	status := http.StatusInternalServerError
+	switch {
+	case errors.As(err, &verr):
+		status = http.StatusUnprocessableEntity
+		detail.Code = string(domain.KindValidation)
+		detail.Message = "validation failed"
+		detail.Fields = verr.Fields
+	case errors.As(err, &derr):
+		if s, ok := kindStatus[derr.Kind]; ok {
+			status = s
+		}
+		detail.Code = string(derr.Kind)
+		detail.Message = derr.Message
+	default:
+		log.Printf("request %s: %v", detail.RequestID, err)
+		detail.Code = codeInternal
+		detail.Message = "internal server error"
+	}
+	writeErrorBody(w, status, detail)
+}
+
+// writeBadRequest reports malformed input such as an unparsable body or id.
+func writeBadRequest(w http.ResponseWriter, r *http.Request, message string) {
+	writeErrorBody(w, http.StatusBadRequest, errorDetail{
+		Code:      codeBadRequest,
+		Message:   message,
+		RequestID: requestID(r),
+	})
+}
+
+func requestID(r *http.Request) string {
+	return middleware.GetReqID(r.Context())
+}
+
+func writeErrorBody(w http.ResponseWriter, status int, detail errorDetail) {
+	w.Header().Set("Content-Type", "application/json")
+	if detail.RequestID != "" {
+		w.Header().Set("X-Request-Id", detail.RequestID)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorBody{Error: detail})
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"multi-inventory/internal/domain"
+)
+
+// codePreconditionRequired is sent when a write that needs If-Match has none.
+const codePreconditionRequired = "precondition_required"
+
+// setETag exposes a row version as a strong entity tag.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ifMatchVersion parses the If-Match header produced from setETag. It returns
+// 0 and no error when the header is absent. Weak tags are accepted because
+// proxies may weaken them; lists and "*" are not supported.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, nil
+	}
+	tag := strings.TrimPrefix(header, "W/")
+	version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+	if err != nil || version <= 0 || !strings.HasPrefix(tag, `"`) {
+		return 0, domain.NewPreconditionFailed("If-Match must be an ETag returned by GET")
+	}
+	return version, nil
+}
+
+// requireIfMatch is ifMatchVersion for writes that must be conditional. It
+// writes the error response itself and reports whether to continue.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return 0, false
+	}
+	if version == 0 {
+		writeErrorBody(w, http.StatusPreconditionRequired, errorDetail{
+			Code:      codePreconditionRequired,
+			Message:   "If-Match header is required",
+			RequestID: requestID(r),
+		})
+		return 0, false
+	}
+	return version, true
+}
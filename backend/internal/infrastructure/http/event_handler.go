@@ -0,0 +1,126 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+// eventHeartbeat keeps idle streams from being closed by proxies.
+const eventHeartbeat = 25 * time.Second
+
+// eventReset tells a resuming client that events were lost and its data
+// should be reloaded.
+const eventReset = "reset"
+
+type EventHandler struct {
+	hub *application.EventHub
+}
+
+func NewEventHandler(hub *application.EventHub) *EventHandler {
+	return &EventHandler{hub: hub}
+}
+
+// Stream sends events as Server-Sent Events:
+//
+//	topics         comma-separated item, stock, order (default all)
+//	location       only events concerning this location
+//	Last-Event-ID  resume after this event; last_event_id in the query
+//	               does the same for clients that cannot set the header
+//
+// Each message has the event's ID, its type as the SSE event name and the
+// event as JSON data. When a resume is not possible a "reset" event is
+// sent first and the client should reload before applying further events.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	topics, err := domain.ParseEventTopics(r.URL.Query().Get("topics"))
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	filter := domain.EventFilter{Topics: topics, Location: strings.TrimSpace(r.URL.Query().Get("location"))}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+
+	rc := http.NewResponseController(w)
+	sub, missed, complete := h.hub.Subscribe(filter, lastID)
+	defer h.hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering in nginx
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if !complete {
+		// Point the client at the latest event so a reconnect after the
+		// reload resumes from here.
+		reset, _ := json.Marshal(map[string]string{"reason": "events since Last-Event-ID are no longer available"})
+		writeSSE(w, h.hub.LastEventID(), eventReset, reset)
+	} else {
+		for _, ev := range missed {
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client reconnects with
+				// Last-Event-ID and catches up from the backlog.
+				return
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, ev domain.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return writeSSE(w, ev.ID, ev.Type, data)
+}
+
+// writeSSE writes one message. JSON has no raw newlines, so data fits on
+// one line. An empty id clears the client's Last-Event-ID.
+func writeSSE(w io.Writer, id, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
+
+func (h *EventHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.With(RequireUser).Get("/", h.Stream)
+	return r
+}
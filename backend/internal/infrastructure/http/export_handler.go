@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+	"multi-inventory/internal/infrastructure/tabular"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ExportHandler struct {
+	exportService *application.ExportService
+	zone          *time.Location
+}
+
+func NewExportHandler(exportService *application.ExportService, zone *time.Location) *ExportHandler {
+	return &ExportHandler{exportService: exportService, zone: zone}
+}
+
+// ExportItems takes the same filters as the item list.
+func (h *ExportHandler) ExportItems(w http.ResponseWriter, r *http.Request) {
+	filter, err := itemFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.export(w, r, "items", application.ItemExportHeader, func(ctx context.Context, rw application.RowWriter) error {
+		return h.exportService.ExportItems(ctx, filter, rw)
+	})
+}
+
+// ExportSales takes a from/to range over the order creation time.
+func (h *ExportHandler) ExportSales(w http.ResponseWriter, r *http.Request) {
+	period, err := parseDateRange(r, h.zone)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.export(w, r, "sales", application.SalesExportHeader, func(ctx context.Context, rw application.RowWriter) error {
+		return h.exportService.ExportSales(ctx, period, rw)
+	})
+}
+
+// ExportMovements takes a from/to range and an optional item_id.
+func (h *ExportHandler) ExportMovements(w http.ResponseWriter, r *http.Request) {
+	period, err := parseDateRange(r, h.zone)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := queryInt64(r, "item_id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	filter := domain.MovementFilter{ItemID: itemID, Period: period}
+	h.export(w, r, "movements", application.MovementExportHeader, func(ctx context.Context, rw application.RowWriter) error {
+		return h.exportService.ExportMovements(ctx, filter, rw)
+	})
+}
+
+// export streams rows in the format from ?format=csv|xlsx|ndjson. Once the
+// first bytes are sent the status cannot change, so a later failure aborts
+// the connection instead of leaving a truncated file that looks complete.
+func (h *ExportHandler) export(w http.ResponseWriter, r *http.Request, name string, header []string, run func(context.Context, application.RowWriter) error) {
+	format, err := tabular.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	out := &lazyHeaderWriter{ResponseWriter: w, setHeaders: func() {
+		filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format.Extension())
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}}
+	tw, err := tabular.NewWriter(format, out, header)
+	if err == nil {
+		err = run(r.Context(), tw)
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		if !out.started {
+			out.setHeaders()
+			w.WriteHeader(http.StatusOK)
+		}
+		return
+	}
+	if !out.started {
+		writeError(w, r, err)
+		return
+	}
+	log.Printf("request %s: export %s aborted: %v", requestID(r), name, err)
+	panic(http.ErrAbortHandler)
+}
+
+// lazyHeaderWriter sets the download headers on the first write, so errors
+// found before any output can still be sent as a JSON error.
+type lazyHeaderWriter struct {
+	http.ResponseWriter
+	setHeaders func()
+	started    bool
+}
+
+func (l *lazyHeaderWriter) Write(p []byte) (int, error) {
+	if !l.started {
+		l.started = true
+		l.setHeaders()
+	}
+	return l.ResponseWriter.Write(p)
+}
+
+func (h *ExportHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(RequireUser)
+	r.Get("/items", h.ExportItems)
+	r.Get("/sales", h.ExportSales)
+	r.Get("/movements", h.ExportMovements)
+	return r
+}
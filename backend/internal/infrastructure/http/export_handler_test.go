@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+func TestExportHeadersAndAbort(t *testing.T) {
+	// Larger than the writers' buffers, so the row reaches the response
+	// before the export fails.
+	longName := strings.Repeat("x", 8192)
+	tests := []struct {
+		name       string
+		query      string
+		run        func(ctx context.Context, rw application.RowWriter) error
+		wantStatus int
+		wantAbort  bool
+		wantFile   bool // Download headers set
+		wantCode   string
+	}{
+		{
+			name:       "empty export still downloads",
+			run:        func(ctx context.Context, rw application.RowWriter) error { return nil },
+			wantStatus: http.StatusOK,
+			wantFile:   true,
+		},
+		{
+			name: "rows are streamed",
+			run: func(ctx context.Context, rw application.RowWriter) error {
+				return rw.Write("Tea", 2)
+			},
+			wantStatus: http.StatusOK,
+			wantFile:   true,
+		},
+		{
+			name:       "unknown format",
+			query:      "?format=pdf",
+			run:        func(ctx context.Context, rw application.RowWriter) error { return nil },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeBadRequest,
+		},
+		{
+			name: "error before any output is a JSON error",
+			run: func(ctx context.Context, rw application.RowWriter) error {
+				return domain.NewNotFound("item not found")
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   string(domain.KindNotFound),
+		},
+		{
+			name: "error after output aborts the response",
+			run: func(ctx context.Context, rw application.RowWriter) error {
+				if err := rw.Write(longName, 1); err != nil {
+					return err
+				}
+				return errors.New("connection to database lost")
+			},
+			wantStatus: http.StatusOK,
+			wantAbort:  true,
+			wantFile:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/exports/items"+tt.query, nil)
+
+			aborted := func() (aborted bool) {
+				defer func() {
+					if p := recover(); p != nil {
+						if p != http.ErrAbortHandler {
+							panic(p)
+						}
+						aborted = true
+					}
+				}()
+				(&ExportHandler{}).export(rec, req, "items", []string{"name", "quantity"}, tt.run)
+				return false
+			}()
+
+			if aborted != tt.wantAbort {
+				t.Errorf("aborted = %v, want %v", aborted, tt.wantAbort)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			disposition := rec.Header().Get("Content-Disposition")
+			if got := strings.HasPrefix(disposition, `attachment; filename="items-`); got != tt.wantFile {
+				t.Errorf("Content-Disposition = %q, want download %v", disposition, tt.wantFile)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error.Code != tt.wantCode {
+				t.Errorf("body = %s, want error code %q", rec.Body, tt.wantCode)
+			}
+		})
+	}
+}
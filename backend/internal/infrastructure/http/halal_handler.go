@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"multi-inventory/internal/domain"
+)
+
+// UploadHalalCertificate accepts a multipart upload with a single "file"
+// field holding the certificate as PDF, JPEG or PNG.
+func (h *InventoryHandler) UploadHalalCertificate(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	// Leave room for the multipart framing around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxHalalDocumentSize+1<<20)
+	if err := r.ParseMultipartForm(domain.MaxHalalDocumentSize); err != nil {
+		writeBadRequest(w, r, "Invalid multipart form or file too large")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeBadRequest(w, r, "file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeBadRequest(w, r, "Failed to read file")
+		return
+	}
+
+	doc := &domain.HalalDocument{ItemID: itemID, FileName: header.Filename, Data: data}
+	if err := h.inventoryService.UploadHalalDocument(r.Context(), currentUser(r), doc); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+// GetHalalCertificate downloads the uploaded certificate.
+func (h *InventoryHandler) GetHalalCertificate(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	doc, err := h.inventoryService.HalalDocument(r.Context(), itemID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(doc.Data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": doc.FileName}))
+	w.Header().Set("Last-Modified", doc.UploadedAt.UTC().Format(http.TimeFormat))
+	w.Write(doc.Data)
+}
+
+func (h *InventoryHandler) DeleteHalalCertificate(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	if err := h.inventoryService.DeleteHalalDocument(r.Context(), itemID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+// maxIdempotentBody bounds the bodies of requests made with a key, which
+// are read up front to be hashed.
+const maxIdempotentBody = 1 << 20
+
+// replayedHeaders are the response headers kept for replays.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Idempotency honours the Idempotency-Key header on the POSTs it wraps.
+type Idempotency struct {
+	service *application.IdempotencyService
+}
+
+func NewIdempotency(service *application.IdempotencyService) *Idempotency {
+	return &Idempotency{service: service}
+}
+
+// Middleware runs the first request with a key and records its response;
+// a retry with the same key and body gets the recorded response, marked
+// with Idempotent-Replayed: true. Keys are per user, so a request with a key
+// must be authenticated: anonymous clients would share one namespace and
+// get each other's responses. Requests without a key pass through.
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := domain.ValidateIdempotencyKey(key); err != nil {
+			writeBadRequest(w, r, err.Error())
+			return
+		}
+		user := currentUser(r)
+		if user == nil {
+			writeError(w, r, domain.NewUnauthorized("authentication required to use an Idempotency-Key"))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		if err != nil {
+			writeBadRequest(w, r, "Request body too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		io.WriteString(hash, r.Method+" "+r.URL.Path+"\n")
+		hash.Write(body)
+		reserved, replay, err := i.service.Begin(r.Context(), user.ID, key, hex.EncodeToString(hash.Sum(nil)))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if replay != nil {
+			for name, value := range replay.ResponseHeaders {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(replay.ResponseStatus)
+			w.Write(replay.ResponseBody)
+			return
+		}
+
+		// The response is stored even if the client went away meanwhile;
+		// that is exactly when it retries.
+		ctx := context.WithoutCancel(r.Context())
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			if p := recover(); p != nil {
+				if err := i.service.Abandon(ctx, reserved); err != nil {
+					log.Printf("idempotency: failed to release key: %v", err)
+				}
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+
+		headers := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if v := w.Header().Get(name); v != "" {
+				headers[name] = v
+			}
+		}
+		if err := i.service.Finish(ctx, reserved, rec.status, headers, rec.body.Bytes()); err != nil {
+			log.Printf("idempotency: failed to store response: %v", err)
+		}
+	})
+}
+
+// responseRecorder passes the response through and keeps a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status, rec.wroteHeader = status, true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+// memoryIdempotency is an in-memory IdempotencyRepository.
+type memoryIdempotency struct {
+	records map[string]domain.IdempotencyRecord
+}
+
+func (m *memoryIdempotency) Reserve(ctx context.Context, rec *domain.IdempotencyRecord, staleBefore time.Time) (*domain.IdempotencyRecord, error) {
+	id := rec.Scope + "\x00" + rec.Key
+	if existing, ok := m.records[id]; ok && existing.ExpiresAt.After(time.Now()) &&
+		(existing.Status == domain.IdempotencyCompleted || existing.CreatedAt.After(staleBefore)) {
+		return &existing, nil
+	}
+	rec.Status, rec.CreatedAt = domain.IdempotencyInProgress, time.Now()
+	m.records[id] = *rec
+	return nil, nil
+}
+
+func (m *memoryIdempotency) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	rec.Status = domain.IdempotencyCompleted
+	m.records[rec.Scope+"\x00"+rec.Key] = *rec
+	return nil
+}
+
+func (m *memoryIdempotency) Release(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	delete(m.records, rec.Scope+"\x00"+rec.Key)
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	alice := &domain.User{ID: "alice"}
+	bob := &domain.User{ID: "bob"}
+	type call struct {
+		user         *domain.User
+		key          string
+		body         string
+		handlerCode  int // Status the wrapped handler answers with
+		wantStatus   int
+		wantBody     string
+		wantReplayed bool
+		wantRun      bool // The wrapped handler ran
+	}
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "first request runs and is stored, retry is replayed",
+			calls: []call{
+				{user: alice, key: "k1", body: `{"qty":1}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 1", wantRun: true},
+				{user: alice, key: "k1", body: `{"qty":1}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 1", wantReplayed: true},
+			},
+		},
+		{
+			name: "key reused with a different body",
+			calls: []call{
+				{user: alice, key: "k1", body: `{"qty":1}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 1", wantRun: true},
+				{user: alice, key: "k1", body: `{"qty":2}`, handlerCode: http.StatusCreated, wantStatus: http.StatusConflict},
+			},
+		},
+		{
+			name: "client errors are stored",
+			calls: []call{
+				{user: alice, key: "k1", body: `{}`, handlerCode: http.StatusUnprocessableEntity, wantStatus: http.StatusUnprocessableEntity, wantBody: "run 1", wantRun: true},
+				{user: alice, key: "k1", body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusUnprocessableEntity, wantBody: "run 1", wantReplayed: true},
+			},
+		},
+		{
+			name: "server errors release the key",
+			calls: []call{
+				{user: alice, key: "k1", body: `{}`, handlerCode: http.StatusInternalServerError, wantStatus: http.StatusInternalServerError, wantBody: "run 1", wantRun: true},
+				{user: alice, key: "k1", body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 2", wantRun: true},
+			},
+		},
+		{
+			name: "keys are per user",
+			calls: []call{
+				{user: alice, key: "k1", body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 1", wantRun: true},
+				{user: bob, key: "k1", body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 2", wantRun: true},
+			},
+		},
+		{
+			name: "anonymous request with a key",
+			calls: []call{
+				{key: "k1", body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusUnauthorized},
+			},
+		},
+		{
+			name: "request without a key is not recorded",
+			calls: []call{
+				{body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 1", wantRun: true},
+				{body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusCreated, wantBody: "run 2", wantRun: true},
+			},
+		},
+		{
+			name: "invalid key",
+			calls: []call{
+				{user: alice, key: "has space", body: `{}`, handlerCode: http.StatusCreated, wantStatus: http.StatusBadRequest},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := application.NewIdempotencyService(&memoryIdempotency{records: map[string]domain.IdempotencyRecord{}}, time.Hour)
+			runs, handlerCode := 0, 0
+			mw := NewIdempotency(service).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				runs++
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(handlerCode)
+				w.Write([]byte("run " + strconv.Itoa(runs)))
+			}))
+			for i, c := range tt.calls {
+				handlerCode = c.handlerCode
+				before := runs
+				req := httptest.NewRequest(http.MethodPost, "/api/sales", strings.NewReader(c.body))
+				if c.key != "" {
+					req.Header.Set("Idempotency-Key", c.key)
+				}
+				if c.user != nil {
+					req = req.WithContext(context.WithValue(req.Context(), userKey{}, c.user))
+				}
+				rec := httptest.NewRecorder()
+				mw.ServeHTTP(rec, req)
+
+				if rec.Code != c.wantStatus {
+					t.Errorf("call %d: status = %d, want %d", i, rec.Code, c.wantStatus)
+				}
+				if c.wantBody != "" && rec.Body.String() != c.wantBody {
+					t.Errorf("call %d: body = %q, want %q", i, rec.Body, c.wantBody)
+				}
+				if got := rec.Header().Get("Idempotent-Replayed") == "true"; got != c.wantReplayed {
+					t.Errorf("call %d: replayed = %v, want %v", i, got, c.wantReplayed)
+				}
+				if got := runs > before; got != c.wantRun {
+					t.Errorf("call %d: handler ran = %v, want %v", i, got, c.wantRun)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareInProgress(t *testing.T) {
+	service := application.NewIdempotencyService(&memoryIdempotency{records: map[string]domain.IdempotencyRecord{}}, time.Hour)
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/sales", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		return req.WithContext(context.WithValue(req.Context(), userKey{}, &domain.User{ID: "alice"}))
+	}
+
+	var mw http.Handler
+	var retry *httptest.ResponseRecorder
+	mw = NewIdempotency(service).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retry == nil {
+			// The client retries while the first request is still running.
+			retry = httptest.NewRecorder()
+			mw.ServeHTTP(retry, newRequest())
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	first := httptest.NewRecorder()
+	mw.ServeHTTP(first, newRequest())
+
+	if first.Code != http.StatusCreated {
+		t.Errorf("first status = %d, want %d", first.Code, http.StatusCreated)
+	}
+	if retry.Code != http.StatusConflict {
+		t.Errorf("retry status = %d, want %d", retry.Code, http.StatusConflict)
+	}
+}
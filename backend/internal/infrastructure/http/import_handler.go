@@ -0,0 +1,180 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+	"multi-inventory/internal/infrastructure/tabular"
+)
+
+// maxImportSize bounds uploaded import files.
+const maxImportSize = 20 << 20
+
+// ImportItems accepts a multipart upload with the fields:
+//
+//	file          CSV or XLSX (first sheet); the first row is the header
+//	format        csv or xlsx, detected from the file name when omitted
+//	mapping       JSON object of item field -> column header
+//	dry_run       true to only validate and preview
+//	skip_invalid  true to import valid rows and skip the others
+//	report        csv or xlsx to download the per-row results as a file
+//
+// Without skip_invalid an invalid row rolls back the whole import, and the
+// result says committed: false.
+func (h *InventoryHandler) ImportItems(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		writeBadRequest(w, r, "Invalid multipart form or file too large")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeBadRequest(w, r, "file is required")
+		return
+	}
+	defer file.Close()
+
+	format := tabular.DetectFormat(header.Filename)
+	if v := r.FormValue("format"); v != "" {
+		if format, err = tabular.ParseFormat(v); err != nil {
+			writeBadRequest(w, r, err.Error())
+			return
+		}
+	}
+	var mapping domain.ImportMapping
+	if v := r.FormValue("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &mapping); err != nil {
+			writeBadRequest(w, r, "mapping must be a JSON object of field to column")
+			return
+		}
+	}
+	var opts application.ImportOptions
+	if opts.DryRun, err = formBool(r, "dry_run"); err != nil {
+		writeBadRequest(w, r, "dry_run must be true or false")
+		return
+	}
+	if opts.SkipInvalid, err = formBool(r, "skip_invalid"); err != nil {
+		writeBadRequest(w, r, "skip_invalid must be true or false")
+		return
+	}
+	var reportFormat tabular.Format
+	if v := r.FormValue("report"); v != "" {
+		if reportFormat, err = tabular.ParseFormat(v); err != nil {
+			writeBadRequest(w, r, err.Error())
+			return
+		}
+	}
+
+	reader, err := tabular.NewReader(format, file)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	defer reader.Close()
+	rows, err := readImportRows(reader, mapping)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	result, err := h.inventoryService.ImportItems(r.Context(), currentUser(r), rows, opts)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if reportFormat != "" {
+		writeImportReport(w, r, reportFormat, result)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func formBool(r *http.Request, key string) (bool, error) {
+	v := r.FormValue(key)
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+// readImportRows maps the columns of every data row to item fields. Row
+// numbers match the spreadsheet, with the header on row 1.
+func readImportRows(reader tabular.Reader, mapping domain.ImportMapping) ([]domain.ImportRow, error) {
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		verr := &domain.ValidationError{}
+		verr.Add("file", domain.CodeRequired, "file is empty")
+		return nil, verr
+	}
+	if err != nil {
+		return nil, invalidFile(err)
+	}
+	columns, err := mapping.Columns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []domain.ImportRow
+	for n := 2; ; n++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, invalidFile(err)
+		}
+		// One row past the limit is enough for the service to reject it.
+		if len(rows) > domain.MaxImportRows {
+			return rows, nil
+		}
+		row := domain.ImportRow{Row: n, Values: make(map[string]string, len(columns))}
+		for field, i := range columns {
+			if i < len(record) {
+				row.Values[field] = record[i]
+			} else {
+				row.Values[field] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+func invalidFile(err error) error {
+	verr := &domain.ValidationError{}
+	verr.Add("file", domain.CodeInvalid, err.Error())
+	return verr
+}
+
+// writeImportReport sends the per-row results as a spreadsheet, one line per
+// row, so errors can be fixed next to the original file.
+func writeImportReport(w http.ResponseWriter, r *http.Request, format tabular.Format, result *domain.ImportResult) {
+	tw, err := tabular.NewWriter(format, w, []string{"row", "action", "item_id", "barcode", "errors"})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="import-report.`+format.Extension()+`"`)
+	for _, row := range result.Rows {
+		msgs := make([]string, len(row.Errors))
+		for i, e := range row.Errors {
+			msgs[i] = e.Field + ": " + e.Message
+		}
+		var itemID any
+		if row.ItemID != 0 {
+			itemID = row.ItemID
+		}
+		if err := tw.Write(row.Row, string(row.Action), itemID, row.Barcode, strings.Join(msgs, "; ")); err != nil {
+			return
+		}
+	}
+	tw.Close()
+}
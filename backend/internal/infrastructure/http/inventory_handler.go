@@ -1,12 +1,17 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"multi-inventory/internal/application"
 	"multi-inventory/internal/domain"
+	"multi-inventory/internal/infrastructure/printing"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -22,12 +27,12 @@ func NewInventoryHandler(inventoryService *application.InventoryService) *Invent
 func (h *InventoryHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
 	var item domain.Item
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
 	if err := h.inventoryService.CreateItem(r.Context(), &item); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -35,31 +40,101 @@ func (h *InventoryHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(item)
 }
 
+// itemFilter reads ?archived=include|only, ?category= (subcategories
+// included), ?brand=, ?product= and ?tag=.
+func itemFilter(r *http.Request) (domain.ItemFilter, error) {
+	q := r.URL.Query()
+	filter := domain.ItemFilter{
+		Archived: domain.ArchiveFilter(q.Get("archived")),
+		Tag:      strings.ToLower(strings.TrimSpace(q.Get("tag"))),
+	}
+	switch filter.Archived {
+	case domain.ArchiveExclude, domain.ArchiveInclude, domain.ArchiveOnly:
+	default:
+		verr := &domain.ValidationError{}
+		verr.Add("archived", domain.CodeInvalid, "archived must be include or only")
+		return filter, verr
+	}
+	var err error
+	if filter.CategoryID, err = queryInt64(r, "category"); err != nil {
+		return filter, err
+	}
+	if filter.BrandID, err = queryInt64(r, "brand"); err != nil {
+		return filter, err
+	}
+	if filter.ProductID, err = queryInt64(r, "product"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// ListItems hides archived items unless ?archived=include or ?archived=only.
+// With ?barcodes=a,b,c it looks those barcodes up instead.
 func (h *InventoryHandler) ListItems(w http.ResponseWriter, r *http.Request) {
-	items, err := h.inventoryService.ListItems(r.Context())
+	if r.URL.Query().Has("barcodes") {
+		h.lookupBarcodes(w, r)
+		return
+	}
+	filter, err := itemFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	items, err := h.inventoryService.ListItems(r.Context(), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(items)
 }
 
+// lookupBarcodes answers one match per barcode, in the order given, with
+// a null item for barcodes that are not found.
+func (h *InventoryHandler) lookupBarcodes(w http.ResponseWriter, r *http.Request) {
+	var codes []string
+	for _, code := range strings.Split(r.URL.Query().Get("barcodes"), ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		writeBadRequest(w, r, "barcodes must list at least one barcode")
+		return
+	}
+	matches, err := h.inventoryService.LookupBarcodes(r.Context(), codes)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(matches)
+}
+
 func (h *InventoryHandler) GetItem(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid ID")
 		return
 	}
 
 	item, err := h.inventoryService.GetItem(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
-	if item == nil {
-		http.Error(w, "Item not found", http.StatusNotFound)
+
+	setETag(w, item.Version)
+	json.NewEncoder(w).Encode(item)
+}
+
+func (h *InventoryHandler) GetItemByBarcode(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+
+	item, err := h.inventoryService.GetItemByBarcode(r.Context(), code)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
@@ -70,47 +145,198 @@ func (h *InventoryHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
 		return
 	}
 
 	var item domain.Item
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 	item.ID = id
+	item.Version = version
 
-	if err := h.inventoryService.UpdateItem(r.Context(), &item); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.inventoryService.UpdateItem(r.Context(), currentUser(r), &item, r.Header.Get(changeReasonHeader)); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, item.Version)
 	json.NewEncoder(w).Encode(item)
 }
 
+// PatchItem applies a JSON Merge Patch (RFC 7396): only the fields sent are
+// changed and validated, null clears optional fields. Quantity is read-only.
+func (h *InventoryHandler) PatchItem(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		writeErrorBody(w, http.StatusUnsupportedMediaType, errorDetail{
+			Code:      codeBadRequest,
+			Message:   "Content-Type must be application/merge-patch+json",
+			RequestID: requestID(r),
+		})
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var patch domain.ItemPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		writeBadRequest(w, r, "Request body must be a JSON object")
+		return
+	}
+
+	item, err := h.inventoryService.PatchItem(r.Context(), currentUser(r), id, version, patch, r.Header.Get(changeReasonHeader))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, item.Version)
+	json.NewEncoder(w).Encode(item)
+}
+
+// DeleteItem archives the item; sales history keeps pointing at it.
 func (h *InventoryHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.inventoryService.ArchiveItem)
+}
+
+func (h *InventoryHandler) RestoreItem(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.inventoryService.RestoreItem)
+}
+
+func (h *InventoryHandler) setArchived(w http.ResponseWriter, r *http.Request, change func(context.Context, int64) (*domain.Item, error)) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid ID")
 		return
 	}
 
-	if err := h.inventoryService.DeleteItem(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	item, err := change(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, item.Version)
+	json.NewEncoder(w).Encode(item)
+}
+
+// PurgeItem permanently deletes an item that has no sales or stock history.
+func (h *InventoryHandler) PurgeItem(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+
+	if err := h.inventoryService.PurgeItem(r.Context(), currentUser(r), id); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type PrintLabelsRequest struct {
+	Format    string                `json:"format"`    // pdf (A4 label sheet) or zpl
+	Symbology string                `json:"symbology"` // code128, ean13 or qr
+	Items     []domain.LabelRequest `json:"items"`
+}
+
+// Validate checks the output options; the items are validated by the service.
+func (req *PrintLabelsRequest) Validate() error {
+	verr := &domain.ValidationError{}
+	if req.Format != "pdf" && req.Format != "zpl" {
+		verr.Add("format", domain.CodeInvalid, "format must be pdf or zpl")
+	}
+	if _, err := printing.ParseSymbology(req.Symbology); err != nil {
+		verr.Add("symbology", domain.CodeInvalid, "symbology must be code128, ean13 or qr")
+	}
+	return verr.Err()
+}
+
+func (h *InventoryHandler) PrintLabels(w http.ResponseWriter, r *http.Request) {
+	var req PrintLabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	if req.Format == "" {
+		req.Format = "pdf"
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sym, _ := printing.ParseSymbology(req.Symbology)
+
+	labels, err := h.inventoryService.PrepareLabels(r.Context(), req.Items)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	for _, label := range labels {
+		if err := sym.CheckContent(label.Item.Barcode); err != nil {
+			writeBadRequest(w, r, err.Error())
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	contentType, disposition := "application/pdf", `inline; filename="labels.pdf"`
+	if req.Format == "zpl" {
+		err = printing.WriteLabelsZPL(&buf, labels, sym)
+		contentType, disposition = "application/x-zpl", `attachment; filename="labels.zpl"`
+	} else {
+		err = printing.WriteLabelsPDF(&buf, labels, sym)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Write(buf.Bytes())
+}
+
 func (h *InventoryHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 	r.Get("/", h.ListItems)
 	r.Post("/", h.CreateItem)
+	r.With(RequireUser).Post("/labels", h.PrintLabels)
+	r.With(RequireUser).Post("/import", h.ImportItems)
+	r.Get("/barcode/{code}", h.GetItemByBarcode)
 	r.Get("/{id}", h.GetItem)
 	r.Put("/{id}", h.UpdateItem)
-	r.Delete("/{id}", h.DeleteItem)
+	r.Patch("/{id}", h.PatchItem)
+	r.With(RequireRole(domain.RoleManager, domain.RoleAdmin)).Delete("/{id}", h.DeleteItem)
+	r.With(RequireRole(domain.RoleManager, domain.RoleAdmin)).Post("/{id}/restore", h.RestoreItem)
+	r.Get("/{id}/prices", h.PriceHistory)
+	r.With(RequireUser).Post("/{id}/prices", h.ChangePrice)
+	r.With(RequireUser).Delete("/{id}/prices/{changeId}", h.CancelPriceChange)
+	r.Get("/{id}/halal-certificate", h.GetHalalCertificate)
+	r.With(RequireUser).Put("/{id}/halal-certificate", h.UploadHalalCertificate)
+	r.With(RequireUser).Delete("/{id}/halal-certificate", h.DeleteHalalCertificate)
+	r.With(RequireRole(domain.RoleAdmin)).Delete("/{id}/purge", h.PurgeItem)
 	return r
 }
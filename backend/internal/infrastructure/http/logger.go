@@ -0,0 +1,30 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Logger logs requests like chi's middleware.Logger, but masks the tokens
+// QueryToken accepts so they do not end up in the logs.
+var Logger = middleware.RequestLogger(redactingFormatter{
+	&middleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags), NoColor: true},
+})
+
+type redactingFormatter struct {
+	middleware.LogFormatter
+}
+
+func (f redactingFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	q := r.URL.Query()
+	if !q.Has("access_token") {
+		return f.LogFormatter.NewLogEntry(r)
+	}
+	q.Set("access_token", "REDACTED")
+	logged := *r
+	logged.RequestURI = r.URL.EscapedPath() + "?" + q.Encode()
+	return f.LogFormatter.NewLogEntry(&logged)
+}
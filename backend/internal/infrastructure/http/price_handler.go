@@ -0,0 +1,90 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"multi-inventory/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// changeReasonHeader carries the reason for a price edit made through PUT
+// or PATCH, stored in the price history.
+const changeReasonHeader = "X-Change-Reason"
+
+type ChangePriceRequest struct {
+	Price  float64 `json:"price"`
+	Reason string  `json:"reason"`
+	// EffectiveAt schedules the change; omitted or past means now.
+	EffectiveAt *time.Time `json:"effective_at"`
+}
+
+// ChangePrice answers 201 when the price changed and 202 when it was
+// scheduled.
+func (h *InventoryHandler) ChangePrice(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	var req ChangePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	change := &domain.PriceChange{Price: req.Price, Reason: req.Reason}
+	if req.EffectiveAt != nil {
+		change.EffectiveAt = *req.EffectiveAt
+	}
+	if err := h.inventoryService.ChangePrice(r.Context(), currentUser(r), itemID, change); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if change.Status == domain.PriceScheduled {
+		w.WriteHeader(http.StatusAccepted)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(change)
+}
+
+func (h *InventoryHandler) PriceHistory(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	changes, err := h.inventoryService.PriceHistory(r.Context(), itemID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+func (h *InventoryHandler) CancelPriceChange(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	changeID, err := strconv.ParseInt(chi.URLParam(r, "changeId"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid price change ID")
+		return
+	}
+	change, err := h.inventoryService.CancelPriceChange(r.Context(), itemID, changeID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(change)
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"multi-inventory/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *InventoryHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.inventoryService.ListProducts(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
+
+// GetProduct includes the active variants and their total stock.
+func (h *InventoryHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	product, err := h.inventoryService.GetProduct(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *InventoryHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var product domain.Product
+	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	if err := h.inventoryService.CreateProduct(r.Context(), &product); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *InventoryHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	var product domain.Product
+	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	product.ID = id
+	updated, err := h.inventoryService.UpdateProduct(r.Context(), &product)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (h *InventoryHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	if err := h.inventoryService.DeleteProduct(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ProductRoutes is mounted at /api/products. Variants are items with a
+// product_id and are managed through /api/inventory.
+func (h *InventoryHandler) ProductRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.ListProducts)
+	r.Get("/{id}", h.GetProduct)
+	r.Group(func(r chi.Router) {
+		r.Use(RequireUser)
+		r.Post("/", h.CreateProduct)
+		r.Put("/{id}", h.UpdateProduct)
+		r.Delete("/{id}", h.DeleteProduct)
+	})
+	return r
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseDateRange reads the from and to query parameters. Each is either a
+// date, where to includes the whole day, or an RFC 3339 timestamp, where to
+// is exclusive. Dates are taken in zone, the configured REPORT_TIMEZONE.
+func parseDateRange(r *http.Request, zone *time.Location) (domain.DateRange, error) {
+	var period domain.DateRange
+	verr := &domain.ValidationError{}
+	for _, p := range []struct {
+		key    string
+		bound  *time.Time
+		isLast bool
+	}{{"from", &period.From, false}, {"to", &period.To, true}} {
+		v := r.URL.Query().Get(p.key)
+		if v == "" {
+			continue
+		}
+		if t, err := time.ParseInLocation(dateLayout, v, zone); err == nil {
+			if p.isLast {
+				t = t.AddDate(0, 0, 1)
+			}
+			*p.bound = t
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			verr.Add(p.key, domain.CodeInvalid, p.key+" must be YYYY-MM-DD or an RFC 3339 timestamp")
+			continue
+		}
+		*p.bound = t
+	}
+	if err := verr.Err(); err != nil {
+		return period, err
+	}
+	return period, period.Validate()
+}
+
+// queryTime reads an optional point in time. A date means the end of that
+// day in zone.
+func queryTime(r *http.Request, key string, zone *time.Location) (time.Time, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, v, zone); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		verr := &domain.ValidationError{}
+		verr.Add(key, domain.CodeInvalid, key+" must be YYYY-MM-DD or an RFC 3339 timestamp")
+		return time.Time{}, verr
+	}
+	return t, nil
+}
+
+// queryInt64 reads an optional positive integer query parameter.
+func queryInt64(r *http.Request, key string) (int64, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		verr := &domain.ValidationError{}
+		verr.Add(key, domain.CodeInvalid, key+" must be a positive integer")
+		return 0, verr
+	}
+	return n, nil
+}
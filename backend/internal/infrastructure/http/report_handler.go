@@ -0,0 +1,214 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReportHandler struct {
+	reportService *application.ReportService
+	zone          *time.Location
+}
+
+func NewReportHandler(reportService *application.ReportService, zone *time.Location) *ReportHandler {
+	return &ReportHandler{reportService: reportService, zone: zone}
+}
+
+// GetDashboard takes an optional ?location= to scope the figures.
+func (h *ReportHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard, err := h.reportService.Dashboard(r.Context(), strings.TrimSpace(r.URL.Query().Get("location")))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dashboard)
+}
+
+// reportFilter reads the from, to and location parameters shared by reports.
+func (h *ReportHandler) reportFilter(r *http.Request) (domain.ReportFilter, error) {
+	period, err := parseDateRange(r, h.zone)
+	if err != nil {
+		return domain.ReportFilter{}, err
+	}
+	return domain.ReportFilter{Period: period, Location: strings.TrimSpace(r.URL.Query().Get("location"))}, nil
+}
+
+// queryLimit reads an optional positive integer capped at max.
+func queryLimit(r *http.Request, key string, fallback, max int) (int, error) {
+	n, err := queryInt64(r, key)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return fallback, nil
+	}
+	if n > int64(max) {
+		verr := &domain.ValidationError{}
+		verr.Add(key, domain.CodeInvalid, fmt.Sprintf("%s must be at most %d", key, max))
+		return 0, verr
+	}
+	return int(n), nil
+}
+
+// SalesSeries: ?interval=day|week|month
+func (h *ReportHandler) SalesSeries(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.reportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	interval, err := domain.ParseInterval(r.URL.Query().Get("interval"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	series, err := h.reportService.SalesSeries(r.Context(), filter, interval)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeReport(w, series)
+}
+
+// TopSellers: ?by=quantity|revenue&limit=10
+func (h *ReportHandler) TopSellers(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.reportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	by := domain.RankBy(r.URL.Query().Get("by"))
+	switch by {
+	case "":
+		by = domain.RankByQuantity
+	case domain.RankByQuantity, domain.RankByRevenue:
+	default:
+		writeBadRequest(w, r, "by must be quantity or revenue")
+		return
+	}
+	limit, err := queryLimit(r, "limit", 10, 100)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sellers, err := h.reportService.TopSellers(r.Context(), filter, by, limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeReport(w, sellers)
+}
+
+// DeadStock: ?days=90
+func (h *ReportHandler) DeadStock(w http.ResponseWriter, r *http.Request) {
+	days, err := queryLimit(r, "days", 90, 3650)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	items, err := h.reportService.DeadStock(r.Context(), days, strings.TrimSpace(r.URL.Query().Get("location")))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeReport(w, items)
+}
+
+func (h *ReportHandler) SellThrough(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.reportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	items, err := h.reportService.SellThrough(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeReport(w, items)
+}
+
+// Margins reports gross margin per item from the cost recorded at sale.
+func (h *ReportHandler) Margins(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.reportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	items, err := h.reportService.Margins(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeReport(w, items)
+}
+
+// Valuation: ?at=2024-06-30 values stock at the end of that day, default now.
+func (h *ReportHandler) Valuation(w http.ResponseWriter, r *http.Request) {
+	at, err := queryTime(r, "at", h.zone)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	valuation, err := h.reportService.Valuation(r.Context(), at, strings.TrimSpace(r.URL.Query().Get("location")))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeReport(w, valuation)
+}
+
+// HalalAlerts: ?days=30 lists certified items whose certificate has expired
+// or expires within that many days.
+func (h *ReportHandler) HalalAlerts(w http.ResponseWriter, r *http.Request) {
+	days, err := queryLimit(r, "days", 30, domain.MaxHalalAlertDays)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	alerts, err := h.reportService.HalalAlerts(r.Context(), days, strings.TrimSpace(r.URL.Query().Get("location")))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeReport(w, alerts)
+}
+
+func writeReport(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+// Routes is mounted at /api/reports. Every report takes from, to and
+// location, except dead-stock and halal-certificates which look back or
+// ahead a number of days and valuation which is taken at one point in time.
+func (h *ReportHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(RequireUser)
+	r.Get("/sales", h.SalesSeries)
+	r.Get("/top-sellers", h.TopSellers)
+	r.Get("/dead-stock", h.DeadStock)
+	r.Get("/sell-through", h.SellThrough)
+	// Costs and margins are for managers.
+	r.With(RequireRole(domain.RoleManager, domain.RoleAdmin)).Get("/margin", h.Margins)
+	r.With(RequireRole(domain.RoleManager, domain.RoleAdmin)).Get("/valuation", h.Valuation)
+	r.Get("/halal-certificates", h.HalalAlerts)
+	return r
+}
+
+// DashboardRoutes is mounted at /api/dashboard.
+func (h *ReportHandler) DashboardRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(RequireUser)
+	r.Get("/", h.GetDashboard)
+	return r
+}
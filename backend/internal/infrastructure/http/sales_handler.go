@@ -1,44 +1,54 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+	"multi-inventory/internal/infrastructure/printing"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type SalesHandler struct {
 	salesService *application.SalesService
+	idempotency  *Idempotency
 }
 
-func NewSalesHandler(salesService *application.SalesService) *SalesHandler {
-	return &SalesHandler{salesService: salesService}
+func NewSalesHandler(salesService *application.SalesService, idempotency *Idempotency) *SalesHandler {
+	return &SalesHandler{salesService: salesService, idempotency: idempotency}
 }
 
 type CreateOrderRequest struct {
-	UserID string `json:"user_id"` // In real app, get from context/token (UUID or numeric as string)
-	Items  []struct {
-		ItemID   int64 `json:"item_id"`
-		Quantity int   `json:"quantity"`
-	} `json:"items"`
+	UserID string             `json:"user_id"` // Ignored when the request carries a token
+	Items  []domain.OrderLine `json:"items"`
+	// AllowMixedHalal confirms that halal and non-halal items may be sold
+	// together; without it such an order is rejected with 409.
+	AllowMixedHalal bool `json:"allow_mixed_halal"`
 }
 
 func (h *SalesHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var req CreateOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
-	// For MVP, if UserID is missing, use a dummy ID or 1
-	// If missing, leave empty to allow NULL FK (column is nullable)
+	// The token's user wins over the body. Without either the order is
+	// stored with a NULL user.
+	if user := currentUser(r); user != nil {
+		req.UserID = user.ID
+	}
 
-	order, err := h.salesService.CreateOrder(r.Context(), req.UserID, req.Items)
+	order, err := h.salesService.CreateOrder(r.Context(), req.UserID, req.Items, application.OrderOptions{
+		AllowMixedHalal: req.AllowMixedHalal,
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -49,7 +59,7 @@ func (h *SalesHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 func (h *SalesHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	orders, err := h.salesService.ListOrders(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -60,21 +70,73 @@ func (h *SalesHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid ID")
 		return
 	}
 
 	order, err := h.salesService.GetOrder(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
-	if order == nil {
-		http.Error(w, "Order not found", http.StatusNotFound)
+
+	setETag(w, order.Version)
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetReceipt renders the order as a printable receipt.
+// Query parameters: format=pdf|escpos (default pdf) and paper=a4|58|80
+// (default a4 for pdf, 80 for escpos).
+func (h *SalesHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
 		return
 	}
 
-	json.NewEncoder(w).Encode(order)
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	defaultPaper := printing.PaperA4
+	if format == "escpos" {
+		defaultPaper = printing.PaperThermal80
+	}
+	paper, err := printing.ParsePaper(r.URL.Query().Get("paper"), defaultPaper)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	receipt, err := h.salesService.GetReceipt(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Render into a buffer so a failure can still be reported as an error status.
+	var buf bytes.Buffer
+	var contentType, ext string
+	switch format {
+	case "pdf":
+		err = printing.WriteReceiptPDF(&buf, receipt, paper)
+		contentType, ext = "application/pdf", "pdf"
+	case "escpos":
+		err = printing.WriteReceiptESCPOS(&buf, receipt, paper)
+		contentType, ext = "application/octet-stream", "bin"
+	default:
+		writeBadRequest(w, r, "Unsupported format")
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="receipt-%d.%s"`, id, ext))
+	w.Write(buf.Bytes())
 }
 
 type UpdateFulfillmentRequest struct {
@@ -85,29 +147,40 @@ func (h *SalesHandler) UpdateItemFulfillment(w http.ResponseWriter, r *http.Requ
 	idStr := chi.URLParam(r, "itemId")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid ID")
 		return
 	}
 
 	var req UpdateFulfillmentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
-	if err := h.salesService.UpdateItemFulfillment(r.Context(), id, req.IsFulfilled); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// If-Match is optional here: the checker toggles single lines and may not
+	// hold the order's ETag. When sent, it must match the order version.
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := h.salesService.UpdateItemFulfillment(r.Context(), id, req.IsFulfilled, expectedVersion)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, version)
 	w.WriteHeader(http.StatusOK)
 }
 
 func (h *SalesHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 	r.Get("/", h.ListOrders)
-	r.Post("/", h.CreateOrder)
+	r.With(h.idempotency.Middleware).Post("/", h.CreateOrder)
 	r.Get("/{id}", h.GetOrder)
+	r.Get("/{id}/receipt", h.GetReceipt)
 	r.Put("/items/{itemId}/fulfillment", h.UpdateItemFulfillment)
 	return r
 }
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+type SyncHandler struct {
+	syncService *application.SyncService
+}
+
+func NewSyncHandler(syncService *application.SyncService) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// Changes: ?cursor=&limit=. Without a cursor it starts a full sync.
+func (h *SyncHandler) Changes(w http.ResponseWriter, r *http.Request) {
+	cursor, err := domain.ParseSyncCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeBadRequest(w, r, "Invalid cursor")
+		return
+	}
+	limit, err := queryLimit(r, "limit", domain.DefaultSyncPageSize, domain.MaxSyncPageSize)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	page, err := h.syncService.Pull(r.Context(), cursor, limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+type PushRequest struct {
+	Ops []domain.SyncOp `json:"ops"`
+}
+
+type PushResponse struct {
+	Results []domain.SyncOpResult `json:"results"`
+}
+
+// Push applies queued ops in order and answers 200 with a result per op,
+// even when some were rejected.
+func (h *SyncHandler) Push(w http.ResponseWriter, r *http.Request) {
+	var req PushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	results, err := h.syncService.Push(r.Context(), currentUser(r), req.Ops)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PushResponse{Results: results})
+}
+
+func (h *SyncHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(RequireUser)
+	r.Get("/changes", h.Changes)
+	r.Post("/push", h.Push)
+	return r
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"multi-inventory/internal/application"
+	"multi-inventory/internal/domain"
+)
+
+type WebhookHandler struct {
+	webhookService *application.WebhookService
+}
+
+func NewWebhookHandler(webhookService *application.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	list, err := h.webhookService.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	hook, err := h.webhookService.GetWebhook(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// CreateWebhook registers a webhook, active unless "active" is false. The
+// response carries the secret, generated when none was given; it is not
+// shown again.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	hook := domain.Webhook{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	if err := h.webhookService.CreateWebhook(r.Context(), &hook); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// UpdateWebhook replaces the settings; the secret is kept unless one is
+// given.
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	hook := domain.Webhook{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+	hook.ID = id
+	if err := h.webhookService.UpdateWebhook(r.Context(), &hook); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	hook, err := h.webhookService.RotateSecret(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	if err := h.webhookService.DeleteWebhook(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries returns the latest deliveries, newest first, optionally
+// narrowed by webhook_id and status (pending, delivered or dead).
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var filter domain.DeliveryFilter
+	if v := q.Get("webhook_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeBadRequest(w, r, "Invalid webhook_id")
+			return
+		}
+		filter.WebhookID = id
+	}
+	status, err := domain.ParseDeliveryStatus(q.Get("status"))
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	filter.Status = status
+	if filter.Limit, err = queryLimit(r, "limit", 50, 500); err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	list, err := h.webhookService.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetDelivery includes the log of attempts.
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	d, err := h.webhookService.GetDelivery(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// RetryDelivery sends a dead delivery again.
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid ID")
+		return
+	}
+	d, err := h.webhookService.RetryDelivery(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+func (h *WebhookHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(RequireRole(domain.RoleAdmin))
+	r.Get("/", h.ListWebhooks)
+	r.Post("/", h.CreateWebhook)
+	r.Get("/deliveries", h.ListDeliveries)
+	r.Get("/deliveries/{id}", h.GetDelivery)
+	r.Post("/deliveries/{id}/retry", h.RetryDelivery)
+	r.Get("/{id}", h.GetWebhook)
+	r.Put("/{id}", h.UpdateWebhook)
+	r.Delete("/{id}", h.DeleteWebhook)
+	r.Post("/{id}/rotate-secret", h.RotateSecret)
+	return r
+}
@@ -0,0 +1,123 @@
+// Package imaging makes thumbnails of uploaded item photos.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Register the decoders for image.Decode
+	"image/jpeg"
+	"image/png"
+)
+
+// MaxPixels bounds the images accepted for thumbnails, so a small file
+// declaring a huge canvas cannot exhaust memory when decoded. 16 MP covers
+// phone photos; decoded as RGBA it still takes 64 MB.
+const MaxPixels = 16_000_000
+
+// Thumbnailer scales images down with a box filter. Opaque images become
+// JPEGs; images with transparency stay PNGs.
+type Thumbnailer struct{}
+
+func NewThumbnailer() *Thumbnailer {
+	return &Thumbnailer{}
+}
+
+func (Thumbnailer) Thumbnail(data []byte, size int) ([]byte, string, int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", 0, 0, fmt.Errorf("image of %dx%d pixels is too large", cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	b := src.Bounds()
+	thumb := scaleDown(src, size)
+	var buf bytes.Buffer
+	contentType := "image/jpeg"
+	if thumb.Opaque() {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	return buf.Bytes(), contentType, b.Dx(), b.Dy(), nil
+}
+
+// scaleDown fits src into size x size keeping the aspect ratio, averaging
+// the source pixels covered by each thumbnail pixel. It reads the decoded
+// image in place, so only the thumbnail is allocated on top of it. Smaller
+// images are copied as they are.
+func scaleDown(src image.Image, size int) *image.RGBA {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dw, dh := sw, sh
+	if sw > size || sh > size {
+		dw, dh = size, size
+		if sw > sh {
+			dh = max(1, sh*size/sw)
+		} else {
+			dw = max(1, sw*size/sh)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	if dw == sw && dh == sh {
+		draw.Draw(dst, dst.Bounds(), src, sb.Min, draw.Src)
+		return dst
+	}
+	pixel := pixelReader(src)
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var r, g, b, a, n uint64
+			for sy := sb.Min.Y + y0; sy < sb.Min.Y+y1; sy++ {
+				for sx := sb.Min.X + x0; sx < sb.Min.X+x1; sx++ {
+					pr, pg, pb, pa := pixel(sx, sy)
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			// The pixels are premultiplied, so plain averages blend
+			// transparent edges correctly.
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n>>8), uint8(g/n>>8), uint8(b/n>>8), uint8(a/n>>8)
+		}
+	}
+	return dst
+}
+
+// pixelReader returns a function reading src's premultiplied 16-bit color
+// at x, y. JPEGs and RGBA images are read directly, others through At.
+func pixelReader(src image.Image) func(x, y int) (r, g, b, a uint32) {
+	switch s := src.(type) {
+	case *image.YCbCr:
+		return func(x, y int) (uint32, uint32, uint32, uint32) {
+			yi, ci := s.YOffset(x, y), s.COffset(x, y)
+			r, g, b := color.YCbCrToRGB(s.Y[yi], s.Cb[ci], s.Cr[ci])
+			return uint32(r) * 0x101, uint32(g) * 0x101, uint32(b) * 0x101, 0xffff
+		}
+	case *image.RGBA:
+		return func(x, y int) (uint32, uint32, uint32, uint32) {
+			p := s.Pix[s.PixOffset(x, y):]
+			return uint32(p[0]) * 0x101, uint32(p[1]) * 0x101, uint32(p[2]) * 0x101, uint32(p[3]) * 0x101
+		}
+	default:
+		return func(x, y int) (uint32, uint32, uint32, uint32) {
+			return src.At(x, y).RGBA()
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+	"strings"
+)
+
+const adjustmentColumns = `id, item_id, delta, reason, note, location, value, status,
+	COALESCE(requested_by::text, ''), COALESCE(reviewed_by::text, ''), review_note, created_at, reviewed_at`
+
+func scanAdjustment(row rowScanner) (*domain.StockAdjustment, error) {
+	var a domain.StockAdjustment
+	err := row.Scan(&a.ID, &a.ItemID, &a.Delta, &a.Reason, &a.Note, &a.Location, &a.Value, &a.Status,
+		&a.RequestedBy, &a.ReviewedBy, &a.ReviewNote, &a.CreatedAt, &a.ReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+type AdjustmentRepository struct {
+	db *DB
+}
+
+func NewAdjustmentRepository(db *DB) *AdjustmentRepository {
+	return &AdjustmentRepository{db: db}
+}
+
+func (r *AdjustmentRepository) Create(ctx context.Context, adj *domain.StockAdjustment) error {
+	adj.Delta = domain.RoundQuantity(adj.Delta)
+	adjustmentsTable := fmt.Sprintf("%s.stock_adjustments", r.db.Schema)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (item_id, delta, reason, note, location, value, status, requested_by, reviewed_by, reviewed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING id, created_at
+	`, adjustmentsTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query,
+		adj.ItemID, adj.Delta, adj.Reason, adj.Note, adj.Location, adj.Value, adj.Status,
+		nullableUUID(adj.RequestedBy), nullableUUID(adj.ReviewedBy), adj.ReviewedAt,
+	).Scan(&adj.ID, &adj.CreatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to create adjustment: %w", err), "adjustment")
+	}
+	return nil
+}
+
+func (r *AdjustmentRepository) GetByID(ctx context.Context, id int64) (*domain.StockAdjustment, error) {
+	adjustmentsTable := fmt.Sprintf("%s.stock_adjustments", r.db.Schema)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, adjustmentColumns, adjustmentsTable)
+	adj, err := scanAdjustment(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get adjustment: %w", err), "adjustment")
+	}
+	return adj, nil
+}
+
+func (r *AdjustmentRepository) List(ctx context.Context, itemID int64, status domain.AdjustmentStatus) ([]*domain.StockAdjustment, error) {
+	adjustmentsTable := fmt.Sprintf("%s.stock_adjustments", r.db.Schema)
+	var (
+		where []string
+		args  []any
+	)
+	if itemID != 0 {
+		args = append(args, itemID)
+		where = append(where, fmt.Sprintf("item_id = $%d", len(args)))
+	}
+	if status != "" {
+		args = append(args, status)
+		where = append(where, fmt.Sprintf("status = $%d", len(args)))
+	}
+	query := fmt.Sprintf(`SELECT %s FROM %s`, adjustmentColumns, adjustmentsTable)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []*domain.StockAdjustment
+	for rows.Next() {
+		adj, err := scanAdjustment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan adjustment: %w", err)
+		}
+		adjustments = append(adjustments, adj)
+	}
+	return adjustments, nil
+}
+
+func (r *AdjustmentRepository) Review(ctx context.Context, adj *domain.StockAdjustment) error {
+	adjustmentsTable := fmt.Sprintf("%s.stock_adjustments", r.db.Schema)
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET status = $1, reviewed_by = $2, review_note = $3, reviewed_at = NOW()
+		WHERE id = $4 AND status = $5
+		RETURNING reviewed_at
+	`, adjustmentsTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query,
+		adj.Status, nullableUUID(adj.ReviewedBy), adj.ReviewNote, adj.ID, domain.AdjustmentPending,
+	).Scan(&adj.ReviewedAt)
+	if err != nil {
+		err = translateError(fmt.Errorf("failed to review adjustment: %w", err), "adjustment")
+		if domain.KindOf(err) == domain.KindNotFound {
+			return domain.NewConflict("adjustment is no longer pending")
+		}
+		return err
+	}
+	return nil
+}
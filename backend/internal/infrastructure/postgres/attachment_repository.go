@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+)
+
+const attachmentColumns = `id, item_id, kind, file_name, content_type, size, width, height, storage_key,
+	COALESCE(thumbnail_key, ''), COALESCE(uploaded_by::text, ''), uploaded_at`
+
+func scanAttachment(row rowScanner) (*domain.Attachment, error) {
+	var a domain.Attachment
+	err := row.Scan(&a.ID, &a.ItemID, &a.Kind, &a.FileName, &a.ContentType, &a.Size, &a.Width, &a.Height,
+		&a.StorageKey, &a.ThumbnailKey, &a.UploadedBy, &a.UploadedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+type AttachmentRepository struct {
+	db *DB
+}
+
+func NewAttachmentRepository(db *DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, a *domain.Attachment) error {
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the item so concurrent uploads cannot exceed the limit.
+	var count int
+	check := fmt.Sprintf(`
+		SELECT (SELECT COUNT(*) FROM %[1]s.item_attachments WHERE item_id = items.id)
+		FROM %[1]s.items WHERE id = $1 FOR UPDATE
+	`, r.db.Schema)
+	if err := tx.QueryRow(ctx, check, a.ItemID).Scan(&count); err != nil {
+		return translateError(fmt.Errorf("failed to lock item: %w", err), "item")
+	}
+	if count >= domain.MaxItemAttachments {
+		return domain.NewConflict("item already has %d attachments", domain.MaxItemAttachments)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s.item_attachments (item_id, kind, file_name, content_type, size, width, height, storage_key,
+			thumbnail_key, uploaded_by, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, NOW())
+		RETURNING id, uploaded_at
+	`, r.db.Schema)
+	err = tx.QueryRow(ctx, query,
+		a.ItemID, a.Kind, a.FileName, a.ContentType, a.Size, a.Width, a.Height, a.StorageKey,
+		a.ThumbnailKey, nullableUUID(a.UploadedBy),
+	).Scan(&a.ID, &a.UploadedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to create attachment: %w", err), "attachment")
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *AttachmentRepository) GetByID(ctx context.Context, id int64) (*domain.Attachment, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s.item_attachments WHERE id = $1`, attachmentColumns, r.db.Schema)
+	a, err := scanAttachment(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get attachment: %w", err), "attachment")
+	}
+	return a, nil
+}
+
+func (r *AttachmentRepository) ListByItem(ctx context.Context, itemID int64) ([]domain.Attachment, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s.item_attachments WHERE item_id = $1 ORDER BY id`, attachmentColumns, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, query, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	list := []domain.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		list = append(list, *a)
+	}
+	return list, rows.Err()
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s.item_attachments WHERE id = $1`, r.db.Schema)
+	cmdTag, err := r.db.conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NewNotFound("attachment not found")
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+)
+
+const categoryColumns = `id, parent_id, name, tax_rate, reorder_point, created_at, updated_at`
+
+func scanCategory(row rowScanner) (*domain.Category, error) {
+	var c domain.Category
+	if err := row.Scan(&c.ID, &c.ParentID, &c.Name, &c.TaxRate, &c.ReorderPoint, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+type CategoryRepository struct {
+	db *DB
+}
+
+func NewCategoryRepository(db *DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+func (r *CategoryRepository) Create(ctx context.Context, c *domain.Category) error {
+	categoriesTable := fmt.Sprintf("%s.categories", r.db.Schema)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (parent_id, name, tax_rate, reorder_point, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, categoriesTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query, c.ParentID, c.Name, c.TaxRate, c.ReorderPoint).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to create category: %w", err), "category")
+	}
+	return nil
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, c *domain.Category) error {
+	categoriesTable := fmt.Sprintf("%s.categories", r.db.Schema)
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET parent_id = $2, name = $3, tax_rate = $4, reorder_point = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, categoriesTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query, c.ID, c.ParentID, c.Name, c.TaxRate, c.ReorderPoint).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to update category: %w", err), "category")
+	}
+	return nil
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
+	schema := r.db.Schema
+	var children, items bool
+	check := fmt.Sprintf(`
+		SELECT EXISTS (SELECT 1 FROM %[1]s.categories WHERE parent_id = $1),
+			EXISTS (SELECT 1 FROM %[1]s.item_categories WHERE category_id = $1)
+	`, schema)
+	if err := r.db.conn(ctx).QueryRow(ctx, check, id).Scan(&children, &items); err != nil {
+		return fmt.Errorf("failed to check category references: %w", err)
+	}
+	switch {
+	case children:
+		return domain.NewConflict("category has subcategories")
+	case items:
+		return domain.NewConflict("category has items")
+	}
+
+	del := fmt.Sprintf(`DELETE FROM %s.categories WHERE id = $1`, schema)
+	tag, err := r.db.conn(ctx).Exec(ctx, del, id)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to delete category: %w", err), "category")
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFound("category not found")
+	}
+	return nil
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id int64) (*domain.Category, error) {
+	categoriesTable := fmt.Sprintf("%s.categories", r.db.Schema)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, categoryColumns, categoriesTable)
+	c, err := scanCategory(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get category: %w", err), "category")
+	}
+	return c, nil
+}
+
+func (r *CategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	categoriesTable := fmt.Sprintf("%s.categories", r.db.Schema)
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY name, id`, categoryColumns, categoriesTable)
+	rows, err := r.db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []*domain.Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+type BrandRepository struct {
+	db *DB
+}
+
+func NewBrandRepository(db *DB) *BrandRepository {
+	return &BrandRepository{db: db}
+}
+
+func (r *BrandRepository) Create(ctx context.Context, b *domain.Brand) error {
+	brandsTable := fmt.Sprintf("%s.brands", r.db.Schema)
+	query := fmt.Sprintf(`INSERT INTO %s (name, created_at) VALUES ($1, NOW()) RETURNING id, created_at`, brandsTable)
+	if err := r.db.conn(ctx).QueryRow(ctx, query, b.Name).Scan(&b.ID, &b.CreatedAt); err != nil {
+		return translateError(fmt.Errorf("failed to create brand: %w", err), "brand")
+	}
+	return nil
+}
+
+func (r *BrandRepository) Update(ctx context.Context, b *domain.Brand) error {
+	brandsTable := fmt.Sprintf("%s.brands", r.db.Schema)
+	query := fmt.Sprintf(`UPDATE %s SET name = $2 WHERE id = $1 RETURNING created_at`, brandsTable)
+	if err := r.db.conn(ctx).QueryRow(ctx, query, b.ID, b.Name).Scan(&b.CreatedAt); err != nil {
+		return translateError(fmt.Errorf("failed to update brand: %w", err), "brand")
+	}
+	return nil
+}
+
+func (r *BrandRepository) Delete(ctx context.Context, id int64) error {
+	schema := r.db.Schema
+	var used bool
+	check := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s.items WHERE brand_id = $1)`, schema)
+	if err := r.db.conn(ctx).QueryRow(ctx, check, id).Scan(&used); err != nil {
+		return fmt.Errorf("failed to check brand references: %w", err)
+	}
+	if used {
+		return domain.NewConflict("brand is used by items")
+	}
+
+	del := fmt.Sprintf(`DELETE FROM %s.brands WHERE id = $1`, schema)
+	tag, err := r.db.conn(ctx).Exec(ctx, del, id)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to delete brand: %w", err), "brand")
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFound("brand not found")
+	}
+	return nil
+}
+
+func (r *BrandRepository) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
+	brandsTable := fmt.Sprintf("%s.brands", r.db.Schema)
+	query := fmt.Sprintf(`SELECT id, name, created_at FROM %s WHERE id = $1`, brandsTable)
+	var b domain.Brand
+	if err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(&b.ID, &b.Name, &b.CreatedAt); err != nil {
+		return nil, translateError(fmt.Errorf("failed to get brand: %w", err), "brand")
+	}
+	return &b, nil
+}
+
+func (r *BrandRepository) List(ctx context.Context) ([]*domain.Brand, error) {
+	brandsTable := fmt.Sprintf("%s.brands", r.db.Schema)
+	query := fmt.Sprintf(`SELECT id, name, created_at FROM %s ORDER BY name, id`, brandsTable)
+	rows, err := r.db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brands: %w", err)
+	}
+	defer rows.Close()
+
+	brands := []*domain.Brand{}
+	for rows.Next() {
+		var b domain.Brand
+		if err := rows.Scan(&b.ID, &b.Name, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan brand: %w", err)
+		}
+		brands = append(brands, &b)
+	}
+	return brands, rows.Err()
+}
+
+type TagRepository struct {
+	db *DB
+}
+
+func NewTagRepository(db *DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) List(ctx context.Context) ([]domain.Tag, error) {
+	query := fmt.Sprintf(`
+		SELECT t.name, COUNT(it.item_id)
+		FROM %[1]s.tags t
+		JOIN %[1]s.item_tags it ON it.tag_id = t.id
+		GROUP BY t.name
+		ORDER BY COUNT(it.item_id) DESC, t.name
+	`, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []domain.Tag{}
+	for rows.Next() {
+		var t domain.Tag
+		if err := rows.Scan(&t.Name, &t.Items); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
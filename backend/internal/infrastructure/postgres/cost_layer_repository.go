@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+)
+
+type CostLayerRepository struct {
+	db *DB
+}
+
+func NewCostLayerRepository(db *DB) *CostLayerRepository {
+	return &CostLayerRepository{db: db}
+}
+
+func (r *CostLayerRepository) Add(ctx context.Context, layer *domain.CostLayer) error {
+	layer.Quantity = domain.RoundQuantity(layer.Quantity)
+	layersTable := fmt.Sprintf("%s.cost_layers", r.db.Schema)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (item_id, movement_id, quantity, unit_cost, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`, layersTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query, layer.ItemID, layer.MovementID, layer.Quantity, layer.UnitCost).Scan(&layer.ID, &layer.CreatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to add cost layer: %w", err), "cost layer")
+	}
+	return nil
+}
+
+// Consume locks the open layers oldest first and draws them down until the
+// quantity is covered. Emptied layers are deleted.
+func (r *CostLayerRepository) Consume(ctx context.Context, itemID int64, quantity float64) (float64, float64, error) {
+	quantity = domain.RoundQuantity(quantity)
+	layersTable := fmt.Sprintf("%s.cost_layers", r.db.Schema)
+	q := r.db.conn(ctx)
+	query := fmt.Sprintf(`
+		SELECT id, quantity, unit_cost
+		FROM %s
+		WHERE item_id = $1 AND quantity > 0
+		ORDER BY created_at, id
+		FOR UPDATE
+	`, layersTable)
+	rows, err := q.Query(ctx, query, itemID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read cost layers: %w", err)
+	}
+	type draw struct {
+		id        int64
+		remaining float64
+	}
+	var draws []draw
+	covered, cost := 0.0, 0.0
+	for rows.Next() && covered < quantity {
+		var id int64
+		var available float64
+		var unitCost float64
+		if err := rows.Scan(&id, &available, &unitCost); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan cost layer: %w", err)
+		}
+		take := min(available, domain.RoundQuantity(quantity-covered))
+		covered = domain.RoundQuantity(covered + take)
+		cost += take * unitCost
+		draws = append(draws, draw{id: id, remaining: domain.RoundQuantity(available - take)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to read cost layers: %w", err)
+	}
+
+	update := fmt.Sprintf(`UPDATE %s SET quantity = $2 WHERE id = $1`, layersTable)
+	del := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, layersTable)
+	for _, d := range draws {
+		var err error
+		if d.remaining == 0 {
+			_, err = q.Exec(ctx, del, d.id)
+		} else {
+			_, err = q.Exec(ctx, update, d.id, d.remaining)
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to consume cost layer: %w", err)
+		}
+	}
+	return covered, cost, nil
+}
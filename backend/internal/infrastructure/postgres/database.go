@@ -6,19 +6,30 @@ import (
 	"os"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	pgdriver "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormschema "gorm.io/gorm/schema"
 )
 
+// querier is implemented by both the pool and a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type DB struct {
 	Pool   *pgxpool.Pool
 	Schema string
 	Gorm   *gorm.DB
 }
 
-func NewDB() (*DB, error) {
+// NewDB connects to the database. Sessions use timeZone, so SQL that
+// truncates timestamps to days agrees with the application.
+func NewDB(timeZone string) (*DB, error) {
 	dbHost := os.Getenv("DB_HOST")
 	dbUser := os.Getenv("DB_USER")
 	dbPassword := os.Getenv("DB_PASSWORD")
@@ -36,6 +47,8 @@ func NewDB() (*DB, error) {
 		return nil, fmt.Errorf("unable to parse database config: %w", err)
 	}
 
+	config.ConnConfig.RuntimeParams["timezone"] = timeZone
+
 	// Connection pool settings
 	config.MaxConns = 10
 	config.MinConns = 2
@@ -85,6 +98,11 @@ func (db *DB) AutoMigrate(ctx context.Context) error {
 		return fmt.Errorf("failed to ensure pgcrypto: %w", err)
 	}
 
+	// Sequences are not managed by GORM; create them on every start.
+	if _, err := db.Pool.Exec(ctx, fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s.internal_barcode_seq", db.Schema)); err != nil {
+		return fmt.Errorf("failed to ensure barcode sequence: %w", err)
+	}
+
 	// GORM automigrate with config to skip constraint name changes
 	migrator := db.Gorm.WithContext(ctx).Migrator()
 
@@ -100,9 +118,64 @@ func (db *DB) AutoMigrate(ctx context.Context) error {
 		&ItemModel{},
 		&SalesOrderModel{},
 		&SalesOrderItemModel{},
+		&InvoiceSequenceModel{},
+		&StockMovementModel{},
+		&StockAdjustmentModel{},
+		&CostLayerModel{},
+		&PriceHistoryModel{},
+		&CategoryModel{},
+		&BrandModel{},
+		&ItemCategoryModel{},
+		&TagModel{},
+		&ItemTagModel{},
+		&ProductModel{},
+		&ItemUnitModel{},
+		&HalalDocumentModel{},
+		&ItemAttachmentModel{},
+		&OutboxModel{},
+		&WebhookModel{},
+		&WebhookDeliveryModel{},
+		&WebhookAttemptModel{},
+		&IdempotencyKeyModel{},
+		&SyncTombstoneModel{},
 	); err != nil {
 		return fmt.Errorf("gorm automigrate failed: %w", err)
 	}
+	return db.ensureSyncTriggers(ctx)
+}
+
+// ensureSyncTriggers installs the triggers stamping item and order writes
+// for offline sync, which GORM cannot create. Existing databases get them
+// from migrations/016_sync.sql.
+func (db *DB) ensureSyncTriggers(ctx context.Context) error {
+	schema := db.Schema
+	stmts := []string{
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s.sync_stamp() RETURNS trigger LANGUAGE plpgsql AS $$
+		BEGIN
+			NEW.change_seq := pg_current_xact_id()::text::bigint;
+			RETURN NEW;
+		END $$`, schema),
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s.sync_tombstone() RETURNS trigger LANGUAGE plpgsql AS $$
+		BEGIN
+			EXECUTE format('INSERT INTO %%I.sync_tombstones (entity, entity_id, change_seq) VALUES ($1, $2, $3)
+				ON CONFLICT (entity, entity_id) DO UPDATE SET change_seq = EXCLUDED.change_seq, deleted_at = NOW()', TG_TABLE_SCHEMA)
+				USING TG_ARGV[0], OLD.id, pg_current_xact_id()::text::bigint;
+			RETURN OLD;
+		END $$`, schema),
+	}
+	for table, entity := range map[string]string{"items": "item", "sales_orders": "order"} {
+		stmts = append(stmts,
+			fmt.Sprintf(`CREATE OR REPLACE TRIGGER %[2]s_sync_stamp BEFORE INSERT OR UPDATE ON %[1]s.%[2]s
+				FOR EACH ROW EXECUTE FUNCTION %[1]s.sync_stamp()`, schema, table),
+			fmt.Sprintf(`CREATE OR REPLACE TRIGGER %[2]s_sync_tombstone AFTER DELETE ON %[1]s.%[2]s
+				FOR EACH ROW EXECUTE FUNCTION %[1]s.sync_tombstone('%[3]s')`, schema, table, entity),
+		)
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to ensure sync triggers: %w", err)
+		}
+	}
 	return nil
 }
 
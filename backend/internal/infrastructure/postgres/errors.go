@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"multi-inventory/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres SQLSTATE codes we translate.
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeCheckViolation       = "23514"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// uniqueMessages names the field behind each unique constraint. Both the
+// names from the SQL migrations and the ones GORM generates are listed.
+var uniqueMessages = map[string]string{
+	"items_barcode_key":          "barcode already exists",
+	"idx_items_barcode":          "barcode already exists",
+	"users_username_key":         "username already exists",
+	"idx_users_username":         "username already exists",
+	"idx_sales_orders_invoice":   "invoice number already exists",
+	"idx_categories_parent_name": "a category with this name already exists under the same parent",
+	"idx_brands_name":            "brand already exists",
+	"idx_item_units_barcode":     "barcode already exists",
+	"idx_item_units_item_name":   "unit already exists",
+}
+
+// translateError maps pgx and Postgres errors to domain errors. entity names
+// the row in messages, e.g. "item not found". The original error is kept as
+// the cause; errors that have no domain meaning are returned unchanged.
+func translateError(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &domain.Error{Kind: domain.KindNotFound, Message: entity + " not found", Err: err}
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		msg, ok := uniqueMessages[pgErr.ConstraintName]
+		if !ok {
+			msg = entity + " already exists"
+		}
+		return &domain.Error{Kind: domain.KindConflict, Message: msg, Err: err}
+	case codeForeignKeyViolation:
+		return &domain.Error{Kind: domain.KindConflict, Message: entity + " is referenced by other records", Err: err}
+	case codeCheckViolation:
+		return &domain.Error{Kind: domain.KindValidation, Message: fmt.Sprintf("%s violates constraint %s", entity, pgErr.ConstraintName), Err: err}
+	case codeSerializationFailure, codeDeadlockDetected:
+		return &domain.Error{Kind: domain.KindConflict, Message: "concurrent update, please retry", Err: err}
+	}
+	return err
+}
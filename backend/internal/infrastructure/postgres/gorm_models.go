@@ -14,37 +14,291 @@ type UserModel struct {
 func (UserModel) TableName() string { return "users" }
 
 type ItemModel struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement"`
-	Name      string    `gorm:"type:text;not null"`
-	Barcode   string    `gorm:"type:text;uniqueIndex;not null"`
-	Price     float64   `gorm:"type:decimal(10,2);not null"`
-	Location  string    `gorm:"type:text"`
-	IsHalal   bool      `gorm:"not null;default:true"`
-	Quantity  int       `gorm:"not null;default:0"`
-	CreatedAt time.Time `gorm:"not null;default:now()"`
-	UpdatedAt time.Time `gorm:"not null;default:now()"`
+	ID           int64      `gorm:"primaryKey;autoIncrement"`
+	Name         string     `gorm:"type:text;not null"`
+	Barcode      string     `gorm:"type:text;uniqueIndex;not null"`
+	Price        float64    `gorm:"type:decimal(10,2);not null"`
+	Location     string     `gorm:"type:text"`
+	HalalStatus  string     `gorm:"type:text;not null;default:unknown"`
+	HalalBody    string     `gorm:"column:halal_certifying_body;type:text;not null;default:''"`
+	HalalCertNo  string     `gorm:"column:halal_certificate_number;type:text;not null;default:''"`
+	HalalExpires *time.Time `gorm:"column:halal_expires_at;index"`
+	Quantity     float64    `gorm:"type:decimal(14,3);not null;default:0"`
+	Unit         string     `gorm:"type:text;not null;default:pcs"`
+	AllowDecimal bool       `gorm:"not null;default:false"`
+	StockValue   float64    `gorm:"type:decimal(14,4);not null;default:0"`
+	BrandID      *int64     `gorm:"index"`
+	ProductID    *int64     `gorm:"index"`
+	Attributes   string     `gorm:"type:jsonb;not null;default:'{}'"`
+	TaxRate      *float64   `gorm:"type:decimal(5,2)"`
+	ReorderPoint *int
+	Version      int64      `gorm:"not null;default:1"`
+	ChangeSeq    int64      `gorm:"not null;default:0;index:idx_items_change_seq"`
+	CreatedAt    time.Time  `gorm:"not null;default:now()"`
+	UpdatedAt    time.Time  `gorm:"not null;default:now()"`
+	ArchivedAt   *time.Time `gorm:"index"`
 }
 
 func (ItemModel) TableName() string { return "items" }
 
 type SalesOrderModel struct {
-	ID         int64     `gorm:"primaryKey;autoIncrement"`
-	UserID     *string   `gorm:"type:uuid"`
-	TotalPrice float64   `gorm:"type:decimal(10,2);not null"`
-	Status     string    `gorm:"type:text;not null;default:pending"`
-	CreatedAt  time.Time `gorm:"not null;default:now()"`
-	UpdatedAt  time.Time `gorm:"not null;default:now()"`
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	UserID        *string   `gorm:"type:uuid"`
+	StoreCode     string    `gorm:"type:text;not null;default:MAIN;uniqueIndex:idx_sales_orders_invoice,priority:1"`
+	InvoiceNumber *string   `gorm:"type:text;uniqueIndex:idx_sales_orders_invoice,priority:2"`
+	TotalPrice    float64   `gorm:"type:decimal(10,2);not null"`
+	Status        string    `gorm:"type:text;not null;default:pending"`
+	HalalOverride bool      `gorm:"not null;default:false"`
+	Version       int64     `gorm:"not null;default:1"`
+	ChangeSeq     int64     `gorm:"not null;default:0;index:idx_sales_orders_change_seq"`
+	CreatedAt     time.Time `gorm:"not null;default:now()"`
+	UpdatedAt     time.Time `gorm:"not null;default:now()"`
 }
 
 func (SalesOrderModel) TableName() string { return "sales_orders" }
 
 type SalesOrderItemModel struct {
-	ID           int64   `gorm:"primaryKey;autoIncrement"`
-	SalesOrderID int64   `gorm:"not null"`
-	ItemID       int64   `gorm:"not null"`
-	Quantity     int     `gorm:"not null"`
-	PriceAtSale  float64 `gorm:"type:decimal(10,2);not null"`
-	IsFulfilled  bool    `gorm:"not null;default:false"`
+	ID           int64    `gorm:"primaryKey;autoIncrement"`
+	SalesOrderID int64    `gorm:"not null"`
+	ItemID       int64    `gorm:"not null"`
+	Quantity     float64  `gorm:"type:decimal(14,3);not null"`
+	PriceAtSale  float64  `gorm:"type:decimal(10,2);not null"`
+	CostAtSale   *float64 `gorm:"type:decimal(14,4)"`
+	IsFulfilled  bool     `gorm:"not null;default:false"`
 }
 
 func (SalesOrderItemModel) TableName() string { return "sales_order_items" }
+
+type InvoiceSequenceModel struct {
+	StoreCode  string `gorm:"type:text;primaryKey"`
+	LastNumber int64  `gorm:"not null;default:0"`
+}
+
+func (InvoiceSequenceModel) TableName() string { return "invoice_sequences" }
+
+type StockMovementModel struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	ItemID        int64     `gorm:"not null;index"`
+	Type          string    `gorm:"type:text;not null"`
+	Delta         float64   `gorm:"type:decimal(14,3);not null"`
+	QuantityAfter float64   `gorm:"type:decimal(14,3);not null"`
+	UnitCost      float64   `gorm:"type:decimal(14,4);not null;default:0"`
+	ValueAfter    float64   `gorm:"type:decimal(14,4);not null;default:0"`
+	Location      string    `gorm:"type:text;not null;default:''"`
+	Reference     string    `gorm:"type:text;not null;default:''"`
+	UserID        *string   `gorm:"type:uuid"`
+	Note          string    `gorm:"type:text;not null;default:''"`
+	CreatedAt     time.Time `gorm:"not null;default:now();index"`
+}
+
+func (StockMovementModel) TableName() string { return "stock_movements" }
+
+type StockAdjustmentModel struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	ItemID      int64     `gorm:"not null;index"`
+	Delta       float64   `gorm:"type:decimal(14,3);not null"`
+	Reason      string    `gorm:"type:text;not null"`
+	Note        string    `gorm:"type:text;not null;default:''"`
+	Location    string    `gorm:"type:text;not null;default:''"`
+	Value       float64   `gorm:"type:decimal(12,2);not null;default:0"`
+	Status      string    `gorm:"type:text;not null;index"`
+	RequestedBy *string   `gorm:"type:uuid"`
+	ReviewedBy  *string   `gorm:"type:uuid"`
+	ReviewNote  string    `gorm:"type:text;not null;default:''"`
+	CreatedAt   time.Time `gorm:"not null;default:now()"`
+	ReviewedAt  *time.Time
+}
+
+func (StockAdjustmentModel) TableName() string { return "stock_adjustments" }
+
+type CostLayerModel struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	ItemID     int64     `gorm:"not null;index"`
+	MovementID int64     `gorm:"not null"`
+	Quantity   float64   `gorm:"type:decimal(14,3);not null"`
+	UnitCost   float64   `gorm:"type:decimal(14,4);not null"`
+	CreatedAt  time.Time `gorm:"not null;default:now()"`
+}
+
+func (CostLayerModel) TableName() string { return "cost_layers" }
+
+type PriceHistoryModel struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	ItemID      int64     `gorm:"not null;index"`
+	OldPrice    *float64  `gorm:"type:decimal(10,2)"`
+	Price       float64   `gorm:"type:decimal(10,2);not null"`
+	Reason      string    `gorm:"type:text;not null;default:''"`
+	ChangedBy   *string   `gorm:"type:uuid"`
+	Status      string    `gorm:"type:text;not null"`
+	EffectiveAt time.Time `gorm:"not null"`
+	AppliedAt   *time.Time
+	CreatedAt   time.Time `gorm:"not null;default:now()"`
+}
+
+func (PriceHistoryModel) TableName() string { return "price_history" }
+
+type CategoryModel struct {
+	ID           int64    `gorm:"primaryKey;autoIncrement"`
+	ParentID     *int64   `gorm:"index"`
+	Name         string   `gorm:"type:text;not null"`
+	TaxRate      *float64 `gorm:"type:decimal(5,2)"`
+	ReorderPoint *int
+	CreatedAt    time.Time `gorm:"not null;default:now()"`
+	UpdatedAt    time.Time `gorm:"not null;default:now()"`
+}
+
+func (CategoryModel) TableName() string { return "categories" }
+
+type BrandModel struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	Name      string    `gorm:"type:text;not null;uniqueIndex:idx_brands_name"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (BrandModel) TableName() string { return "brands" }
+
+type ItemCategoryModel struct {
+	ItemID     int64 `gorm:"primaryKey"`
+	CategoryID int64 `gorm:"primaryKey;index"`
+	Position   int   `gorm:"not null;default:0"`
+}
+
+func (ItemCategoryModel) TableName() string { return "item_categories" }
+
+type TagModel struct {
+	ID   int64  `gorm:"primaryKey;autoIncrement"`
+	Name string `gorm:"type:text;not null;uniqueIndex:idx_tags_name"`
+}
+
+func (TagModel) TableName() string { return "tags" }
+
+type ItemTagModel struct {
+	ItemID int64 `gorm:"primaryKey"`
+	TagID  int64 `gorm:"primaryKey;index"`
+}
+
+func (ItemTagModel) TableName() string { return "item_tags" }
+
+type ProductModel struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	Name       string    `gorm:"type:text;not null"`
+	Attributes string    `gorm:"type:text[];not null;default:'{}'"`
+	CreatedAt  time.Time `gorm:"not null;default:now()"`
+	UpdatedAt  time.Time `gorm:"not null;default:now()"`
+}
+
+func (ProductModel) TableName() string { return "products" }
+
+type ItemUnitModel struct {
+	ID       int64   `gorm:"primaryKey;autoIncrement"`
+	ItemID   int64   `gorm:"not null;uniqueIndex:idx_item_units_item_name,priority:1"`
+	Name     string  `gorm:"type:text;not null;uniqueIndex:idx_item_units_item_name,priority:2"`
+	Factor   float64 `gorm:"type:decimal(14,3);not null"`
+	Barcode  *string `gorm:"type:text;uniqueIndex:idx_item_units_barcode"`
+	Position int     `gorm:"not null;default:0"`
+}
+
+func (ItemUnitModel) TableName() string { return "item_units" }
+
+type HalalDocumentModel struct {
+	ItemID      int64     `gorm:"primaryKey"`
+	FileName    string    `gorm:"type:text;not null"`
+	ContentType string    `gorm:"type:text;not null"`
+	Data        []byte    `gorm:"type:bytea;not null"`
+	UploadedBy  *string   `gorm:"type:uuid"`
+	UploadedAt  time.Time `gorm:"not null;default:now()"`
+}
+
+func (HalalDocumentModel) TableName() string { return "halal_documents" }
+
+type ItemAttachmentModel struct {
+	ID           int64     `gorm:"primaryKey"`
+	ItemID       int64     `gorm:"not null;index"`
+	Kind         string    `gorm:"type:text;not null"`
+	FileName     string    `gorm:"type:text;not null"`
+	ContentType  string    `gorm:"type:text;not null"`
+	Size         int64     `gorm:"not null"`
+	Width        int       `gorm:"not null;default:0"`
+	Height       int       `gorm:"not null;default:0"`
+	StorageKey   string    `gorm:"type:text;not null;uniqueIndex"`
+	ThumbnailKey *string   `gorm:"type:text"`
+	UploadedBy   *string   `gorm:"type:uuid"`
+	UploadedAt   time.Time `gorm:"not null;default:now()"`
+}
+
+func (ItemAttachmentModel) TableName() string { return "item_attachments" }
+
+type OutboxModel struct {
+	ID           int64     `gorm:"primaryKey"`
+	Topic        string    `gorm:"type:text;not null"`
+	Type         string    `gorm:"type:text;not null"`
+	Locations    string    `gorm:"type:text[];not null;default:'{}'"`
+	Data         string    `gorm:"type:jsonb;not null"`
+	CreatedAt    time.Time `gorm:"not null;default:now()"`
+	DispatchedAt *time.Time
+}
+
+func (OutboxModel) TableName() string { return "outbox" }
+
+type WebhookModel struct {
+	ID          int64     `gorm:"primaryKey"`
+	URL         string    `gorm:"type:text;not null"`
+	Secret      string    `gorm:"type:text;not null"`
+	Topics      string    `gorm:"type:text[];not null;default:'{}'"`
+	Location    string    `gorm:"type:text;not null;default:''"`
+	Description string    `gorm:"type:text;not null;default:''"`
+	Active      bool      `gorm:"not null;default:true"`
+	CreatedAt   time.Time `gorm:"not null;default:now()"`
+	UpdatedAt   time.Time `gorm:"not null;default:now()"`
+}
+
+func (WebhookModel) TableName() string { return "webhooks" }
+
+type WebhookDeliveryModel struct {
+	ID            int64      `gorm:"primaryKey"`
+	WebhookID     int64      `gorm:"not null;index"`
+	EventID       int64      `gorm:"not null"`
+	Status        string     `gorm:"type:text;not null;default:pending;index"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `gorm:"index"`
+	LastError     string     `gorm:"type:text;not null;default:''"`
+	CreatedAt     time.Time  `gorm:"not null;default:now()"`
+	DeliveredAt   *time.Time
+}
+
+func (WebhookDeliveryModel) TableName() string { return "webhook_deliveries" }
+
+type WebhookAttemptModel struct {
+	ID         int64     `gorm:"primaryKey"`
+	DeliveryID int64     `gorm:"not null;index"`
+	Attempt    int       `gorm:"not null"`
+	StatusCode int       `gorm:"not null;default:0"`
+	Error      string    `gorm:"type:text;not null;default:''"`
+	DurationMS int64     `gorm:"column:duration_ms;not null;default:0"`
+	At         time.Time `gorm:"not null;default:now()"`
+}
+
+func (WebhookAttemptModel) TableName() string { return "webhook_attempts" }
+
+type IdempotencyKeyModel struct {
+	Scope           string    `gorm:"primaryKey;type:text"`
+	Key             string    `gorm:"primaryKey;type:text"`
+	RequestHash     string    `gorm:"type:text;not null"`
+	Status          string    `gorm:"type:text;not null;default:in_progress"`
+	ResponseStatus  int       `gorm:"not null;default:0"`
+	ResponseHeaders string    `gorm:"type:jsonb;not null;default:'{}'"`
+	ResponseBody    []byte    `gorm:"type:bytea"`
+	CreatedAt       time.Time `gorm:"not null;default:now()"`
+	ExpiresAt       time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyKeyModel) TableName() string { return "idempotency_keys" }
+
+type SyncTombstoneModel struct {
+	Entity    string    `gorm:"primaryKey;type:text"`
+	EntityID  int64     `gorm:"primaryKey"`
+	ChangeSeq int64     `gorm:"not null;index"`
+	DeletedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (SyncTombstoneModel) TableName() string { return "sync_tombstones" }
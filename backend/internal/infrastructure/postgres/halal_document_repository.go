@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+)
+
+type HalalDocumentRepository struct {
+	db *DB
+}
+
+func NewHalalDocumentRepository(db *DB) *HalalDocumentRepository {
+	return &HalalDocumentRepository{db: db}
+}
+
+func (r *HalalDocumentRepository) Put(ctx context.Context, doc *domain.HalalDocument) error {
+	docsTable := fmt.Sprintf("%s.halal_documents", r.db.Schema)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (item_id, file_name, content_type, data, uploaded_by, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (item_id) DO UPDATE
+		SET file_name = EXCLUDED.file_name, content_type = EXCLUDED.content_type, data = EXCLUDED.data,
+			uploaded_by = EXCLUDED.uploaded_by, uploaded_at = EXCLUDED.uploaded_at
+		RETURNING uploaded_at
+	`, docsTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query,
+		doc.ItemID, doc.FileName, doc.ContentType, doc.Data, nullableUUID(doc.UploadedBy),
+	).Scan(&doc.UploadedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to store halal document: %w", err), "item")
+	}
+	return nil
+}
+
+func (r *HalalDocumentRepository) Get(ctx context.Context, itemID int64) (*domain.HalalDocument, error) {
+	docsTable := fmt.Sprintf("%s.halal_documents", r.db.Schema)
+	query := fmt.Sprintf(`
+		SELECT item_id, file_name, content_type, data, COALESCE(uploaded_by::text, ''), uploaded_at
+		FROM %s
+		WHERE item_id = $1
+	`, docsTable)
+	var doc domain.HalalDocument
+	err := r.db.conn(ctx).QueryRow(ctx, query, itemID).Scan(
+		&doc.ItemID, &doc.FileName, &doc.ContentType, &doc.Data, &doc.UploadedBy, &doc.UploadedAt,
+	)
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get halal document: %w", err), "halal document")
+	}
+	return &doc, nil
+}
+
+func (r *HalalDocumentRepository) Delete(ctx context.Context, itemID int64) error {
+	docsTable := fmt.Sprintf("%s.halal_documents", r.db.Schema)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE item_id = $1`, docsTable)
+	cmdTag, err := r.db.conn(ctx).Exec(ctx, query, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to delete halal document: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NewNotFound("halal document not found")
+	}
+	return nil
+}
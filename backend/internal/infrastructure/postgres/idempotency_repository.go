@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"multi-inventory/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type IdempotencyRepository struct {
+	db *DB
+}
+
+func NewIdempotencyRepository(db *DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Reserve(ctx context.Context, rec *domain.IdempotencyRecord, staleBefore time.Time) (*domain.IdempotencyRecord, error) {
+	schema := r.db.Schema
+	// Take over expired and abandoned keys in place; the WHERE leaves live
+	// ones alone, and then nothing is returned.
+	insert := fmt.Sprintf(`
+		INSERT INTO %[1]s.idempotency_keys (scope, key, request_hash, status, response_status, response_headers,
+			response_body, created_at, expires_at)
+		VALUES ($1, $2, $3, 'in_progress', 0, '{}', NULL, NOW(), $4)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status = 'in_progress', response_status = 0,
+			response_headers = '{}', response_body = NULL, created_at = NOW(), expires_at = EXCLUDED.expires_at
+		WHERE %[1]s.idempotency_keys.expires_at <= NOW()
+			OR (%[1]s.idempotency_keys.status = 'in_progress' AND %[1]s.idempotency_keys.created_at < $5)
+		RETURNING created_at
+	`, schema)
+	err := r.db.conn(ctx).QueryRow(ctx, insert, rec.Scope, rec.Key, rec.RequestHash, rec.ExpiresAt, staleBefore).Scan(&rec.CreatedAt)
+	if err == nil {
+		rec.Status = domain.IdempotencyInProgress
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT request_hash, status, response_status, response_headers::text, COALESCE(response_body, ''::bytea),
+			created_at, expires_at
+		FROM %s.idempotency_keys WHERE scope = $1 AND key = $2
+	`, schema)
+	existing := domain.IdempotencyRecord{Scope: rec.Scope, Key: rec.Key}
+	var headers string
+	err = r.db.conn(ctx).QueryRow(ctx, query, rec.Scope, rec.Key).Scan(&existing.RequestHash, &existing.Status,
+		&existing.ResponseStatus, &headers, &existing.ResponseBody, &existing.CreatedAt, &existing.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if err := json.Unmarshal([]byte(headers), &existing.ResponseHeaders); err != nil {
+		return nil, fmt.Errorf("failed to decode stored headers: %w", err)
+	}
+	return &existing, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	headers, err := json.Marshal(rec.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s.idempotency_keys
+		SET status = 'completed', response_status = $3, response_headers = $4, response_body = $5
+		WHERE scope = $1 AND key = $2 AND request_hash = $6
+	`, r.db.Schema)
+	_, err = r.db.conn(ctx).Exec(ctx, query, rec.Scope, rec.Key, rec.ResponseStatus, string(headers), rec.ResponseBody, rec.RequestHash)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	rec.Status = domain.IdempotencyCompleted
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.idempotency_keys
+		WHERE scope = $1 AND key = $2 AND request_hash = $3 AND status = 'in_progress'
+	`, r.db.Schema)
+	if _, err := r.db.conn(ctx).Exec(ctx, query, rec.Scope, rec.Key, rec.RequestHash); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s.idempotency_keys WHERE expires_at <= NOW()`, r.db.Schema)
+	tag, err := r.db.conn(ctx).Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
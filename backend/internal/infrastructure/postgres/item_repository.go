@@ -7,9 +7,49 @@ import (
 	"multi-inventory/internal/domain"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// itemColumns is the column list scanned by scanItem, formatted with the
+// schema. Category and tag links and the units are aggregated so one row
+// holds the item.
+const itemColumns = `items.id, items.name, items.barcode, items.price, items.location,
+	items.halal_status, items.halal_certifying_body, items.halal_certificate_number, items.halal_expires_at,
+	COALESCE(items.halal_expires_at < NOW(), FALSE),
+	COALESCE((SELECT hd.file_name FROM %[1]s.halal_documents hd WHERE hd.item_id = items.id), ''),
+	(SELECT ia.id FROM %[1]s.item_attachments ia WHERE ia.item_id = items.id AND ia.kind = 'image' ORDER BY ia.id LIMIT 1),
+	items.quantity,
+	items.unit, items.allow_decimal,
+	COALESCE((SELECT jsonb_agg(jsonb_build_object('name', u.name, 'factor', u.factor, 'barcode', COALESCE(u.barcode, '')) ORDER BY u.position)
+		FROM %[1]s.item_units u WHERE u.item_id = items.id), '[]'),
+	items.stock_value, items.brand_id, items.product_id, items.attributes, items.tax_rate, items.reorder_point,
+	ARRAY(SELECT ic.category_id FROM %[1]s.item_categories ic WHERE ic.item_id = items.id ORDER BY ic.position),
+	ARRAY(SELECT t.name FROM %[1]s.item_tags it JOIN %[1]s.tags t ON t.id = it.tag_id WHERE it.item_id = items.id ORDER BY t.name),
+	items.version, items.created_at, items.updated_at, items.archived_at`
+
+// rowScanner is satisfied by both pgx.Row and pgx.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItem(row rowScanner) (*domain.Item, error) {
+	var item domain.Item
+	var imageID *int64
+	err := row.Scan(&item.ID, &item.Name, &item.Barcode, &item.Price, &item.Location,
+		&item.Halal.Status, &item.Halal.CertifyingBody, &item.Halal.CertificateNumber, &item.Halal.ExpiresAt,
+		&item.Halal.Expired, &item.Halal.Document, &imageID, &item.Quantity,
+		&item.Unit, &item.AllowDecimal, &item.Units, &item.StockValue, &item.BrandID, &item.ProductID, &item.Attributes, &item.TaxRate, &item.ReorderPoint, &item.CategoryIDs, &item.Tags,
+		&item.Version, &item.CreatedAt, &item.UpdatedAt, &item.ArchivedAt)
+	if err != nil {
+		return nil, err
+	}
+	if item.Quantity > 0 {
+		item.UnitCost = domain.RoundCost(item.StockValue / item.Quantity)
+	}
+	item.IsHalal = item.Halal.Valid()
+	item.SetImage(imageID)
+	return &item, nil
+}
+
 type ItemRepository struct {
 	db *DB
 }
@@ -18,54 +58,288 @@ func NewItemRepository(db *DB) *ItemRepository {
 	return &ItemRepository{db: db}
 }
 
+func (r *ItemRepository) columns() string {
+	return fmt.Sprintf(itemColumns, r.db.Schema)
+}
+
 func (r *ItemRepository) Create(ctx context.Context, item *domain.Item) error {
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Round like the DECIMAL(14,3) column so the item matches what is stored.
+	item.Quantity = domain.RoundQuantity(item.Quantity)
 	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
 	query := fmt.Sprintf(`
-		INSERT INTO %s (name, barcode, price, location, is_halal, quantity, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		INSERT INTO %s (name, barcode, price, location, halal_status, halal_certifying_body, halal_certificate_number,
+			halal_expires_at, quantity, stock_value, brand_id, product_id, attributes, tax_rate, reorder_point, unit,
+			allow_decimal, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13::jsonb, '{}'), $14, $15, $16, $17, NOW(), NOW())
+		RETURNING id, version, created_at, updated_at, COALESCE(halal_expires_at < NOW(), FALSE)
 	`, itemsTable)
-	err := r.db.Pool.QueryRow(ctx, query, item.Name, item.Barcode, item.Price, item.Location, item.IsHalal, item.Quantity).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	h := item.Halal
+	err = tx.QueryRow(ctx, query,
+		item.Name, item.Barcode, item.Price, item.Location, h.Status, h.CertifyingBody, h.CertificateNumber,
+		h.ExpiresAt, item.Quantity, item.StockValue, item.BrandID, item.ProductID, item.Attributes, item.TaxRate,
+		item.ReorderPoint, item.Unit, item.AllowDecimal,
+	).Scan(&item.ID, &item.Version, &item.CreatedAt, &item.UpdatedAt, &item.Halal.Expired)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
-			return fmt.Errorf("barcode already exists")
+		return translateError(fmt.Errorf("failed to create item: %w", err), "item")
+	}
+	if err := r.setLinks(ctx, tx, item); err != nil {
+		return err
+	}
+	item.Halal.Document = ""
+	item.IsHalal = item.Halal.Valid()
+	item.SetImage(nil)
+	return tx.Commit(ctx)
+}
+
+// setLinks replaces the category and tag links and the units of the item,
+// creating tags that are new.
+func (r *ItemRepository) setLinks(ctx context.Context, q querier, item *domain.Item) error {
+	schema := r.db.Schema
+	units := fmt.Sprintf(`DELETE FROM %s.item_units WHERE item_id = $1`, schema)
+	if _, err := q.Exec(ctx, units, item.ID); err != nil {
+		return fmt.Errorf("failed to clear item units: %w", err)
+	}
+	if len(item.Units) > 0 {
+		names := make([]string, len(item.Units))
+		factors := make([]float64, len(item.Units))
+		barcodes := make([]string, len(item.Units))
+		for i, u := range item.Units {
+			names[i], factors[i], barcodes[i] = u.Name, domain.RoundQuantity(u.Factor), u.Barcode
+		}
+		add := fmt.Sprintf(`
+			INSERT INTO %s.item_units (item_id, name, factor, barcode, position)
+			SELECT $1, u.name, u.factor, NULLIF(u.barcode, ''), u.pos - 1
+			FROM unnest($2::text[], $3::numeric[], $4::text[]) WITH ORDINALITY AS u(name, factor, barcode, pos)
+		`, schema)
+		if _, err := q.Exec(ctx, add, item.ID, names, factors, barcodes); err != nil {
+			return translateError(fmt.Errorf("failed to store item units: %w", err), "unit")
+		}
+	}
+
+	categories := fmt.Sprintf(`DELETE FROM %s.item_categories WHERE item_id = $1`, schema)
+	if _, err := q.Exec(ctx, categories, item.ID); err != nil {
+		return fmt.Errorf("failed to clear item categories: %w", err)
+	}
+	if len(item.CategoryIDs) > 0 {
+		link := fmt.Sprintf(`
+			INSERT INTO %s.item_categories (item_id, category_id, position)
+			SELECT $1, c.id, c.pos - 1 FROM unnest($2::bigint[]) WITH ORDINALITY AS c(id, pos)
+		`, schema)
+		if _, err := q.Exec(ctx, link, item.ID, item.CategoryIDs); err != nil {
+			return translateError(fmt.Errorf("failed to link item categories: %w", err), "category")
+		}
+	}
+
+	tags := fmt.Sprintf(`DELETE FROM %s.item_tags WHERE item_id = $1`, schema)
+	if _, err := q.Exec(ctx, tags, item.ID); err != nil {
+		return fmt.Errorf("failed to clear item tags: %w", err)
+	}
+	if len(item.Tags) > 0 {
+		create := fmt.Sprintf(`INSERT INTO %s.tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`, schema)
+		if _, err := q.Exec(ctx, create, item.Tags); err != nil {
+			return fmt.Errorf("failed to create tags: %w", err)
+		}
+		link := fmt.Sprintf(`
+			INSERT INTO %[1]s.item_tags (item_id, tag_id)
+			SELECT $1, id FROM %[1]s.tags WHERE name = ANY($2)
+		`, schema)
+		if _, err := q.Exec(ctx, link, item.ID, item.Tags); err != nil {
+			return fmt.Errorf("failed to link item tags: %w", err)
 		}
-		return fmt.Errorf("failed to create item: %w", err)
 	}
 	return nil
 }
 
 func (r *ItemRepository) Update(ctx context.Context, item *domain.Item) error {
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// A certificate only belongs to a certified item.
+	if item.Halal.Status != domain.HalalCertified {
+		docs := fmt.Sprintf(`DELETE FROM %s.halal_documents WHERE item_id = $1`, r.db.Schema)
+		if _, err := tx.Exec(ctx, docs, item.ID); err != nil {
+			return fmt.Errorf("failed to delete halal document: %w", err)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %[1]s.items
+		SET name = $1, barcode = $2, price = $3, location = $4, halal_status = $5, halal_certifying_body = $6,
+			halal_certificate_number = $7, halal_expires_at = $8, brand_id = $9, product_id = $10,
+			attributes = COALESCE($11::jsonb, '{}'), tax_rate = $12, reorder_point = $13,
+			unit = $14, allow_decimal = $15, version = version + 1, updated_at = NOW()
+		WHERE id = $16 AND version = $17
+		RETURNING quantity, version, updated_at, COALESCE(halal_expires_at < NOW(), FALSE),
+			COALESCE((SELECT hd.file_name FROM %[1]s.halal_documents hd WHERE hd.item_id = items.id), ''),
+			(SELECT ia.id FROM %[1]s.item_attachments ia WHERE ia.item_id = items.id AND ia.kind = 'image' ORDER BY ia.id LIMIT 1)
+	`, r.db.Schema)
+	var imageID *int64
+	h := item.Halal
+	err = tx.QueryRow(ctx, query,
+		item.Name, item.Barcode, item.Price, item.Location, h.Status, h.CertifyingBody, h.CertificateNumber, h.ExpiresAt,
+		item.BrandID, item.ProductID, item.Attributes, item.TaxRate, item.ReorderPoint,
+		item.Unit, item.AllowDecimal, item.ID, item.Version,
+	).Scan(&item.Quantity, &item.Version, &item.UpdatedAt, &item.Halal.Expired, &item.Halal.Document, &imageID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.staleOrMissing(ctx, item.ID)
+	}
+	if err != nil {
+		return translateError(fmt.Errorf("failed to update item: %w", err), "item")
+	}
+	if err := r.setLinks(ctx, tx, item); err != nil {
+		return err
+	}
+	item.IsHalal = item.Halal.Valid()
+	item.SetImage(imageID)
+	return tx.Commit(ctx)
+}
+
+func (r *ItemRepository) SetPrice(ctx context.Context, id int64, price float64) (float64, error) {
 	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
 	query := fmt.Sprintf(`
-		UPDATE %s
-		SET name = $1, barcode = $2, price = $3, location = $4, is_halal = $5, quantity = $6, updated_at = NOW()
-		WHERE id = $7
+		UPDATE %[1]s i
+		SET price = $2, version = i.version + 1, updated_at = NOW()
+		FROM (SELECT id, price FROM %[1]s WHERE id = $1 FOR UPDATE) old
+		WHERE i.id = old.id
+		RETURNING old.price
 	`, itemsTable)
-	cmdTag, err := r.db.Pool.Exec(ctx, query, item.Name, item.Barcode, item.Price, item.Location, item.IsHalal, item.Quantity, item.ID)
+	var old float64
+	err := r.db.conn(ctx).QueryRow(ctx, query, id, price).Scan(&old)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
-			return fmt.Errorf("barcode already exists")
+		return 0, translateError(fmt.Errorf("failed to set item price: %w", err), "item")
+	}
+	return old, nil
+}
+
+func (r *ItemRepository) AdjustQuantity(ctx context.Context, id int64, delta float64) (*domain.Item, error) {
+	delta = domain.RoundQuantity(delta)
+	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET quantity = quantity + $2, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND quantity + $2 >= 0
+		RETURNING %s
+	`, itemsTable, r.columns())
+	item, err := scanItem(r.db.conn(ctx).QueryRow(ctx, query, id, delta))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Either the item is missing or the stock would go negative.
+		current, getErr := r.GetByID(ctx, id)
+		if getErr != nil {
+			return nil, getErr
 		}
-		return fmt.Errorf("failed to update item: %w", err)
+		return nil, domain.NewInsufficientStock("insufficient quantity for item %s: %s %s in stock",
+			current.Name, domain.FormatQuantity(current.Quantity), current.Unit)
+	}
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to adjust quantity: %w", err), "item")
+	}
+	return item, nil
+}
+
+func (r *ItemRepository) SetStockValue(ctx context.Context, id int64, value float64) error {
+	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
+	query := fmt.Sprintf(`UPDATE %s SET stock_value = $2 WHERE id = $1`, itemsTable)
+	cmdTag, err := r.db.conn(ctx).Exec(ctx, query, id, value)
+	if err != nil {
+		return fmt.Errorf("failed to set stock value: %w", err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return errors.New("item not found")
+		return domain.NewNotFound("item not found")
 	}
 	return nil
 }
 
-func (r *ItemRepository) Delete(ctx context.Context, id int64) error {
+// staleOrMissing tells apart the two reasons a versioned update matches no row.
+func (r *ItemRepository) staleOrMissing(ctx context.Context, id int64) error {
+	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, itemsTable)
+	if err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check item: %w", err)
+	}
+	if !exists {
+		return domain.NewNotFound("item not found")
+	}
+	return domain.NewPreconditionFailed("item was modified by someone else")
+}
+
+func (r *ItemRepository) SetArchived(ctx context.Context, id int64, archived bool) (*domain.Item, error) {
 	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
-	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, itemsTable)
-	cmdTag, err := r.db.Pool.Exec(ctx, query, id)
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, NOW()) END,
+			version = version + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING %s
+	`, itemsTable, r.columns())
+	item, err := scanItem(r.db.conn(ctx).QueryRow(ctx, query, id, archived))
 	if err != nil {
-		return fmt.Errorf("failed to delete item: %w", err)
+		return nil, translateError(fmt.Errorf("failed to archive item: %w", err), "item")
 	}
-	if cmdTag.RowsAffected() == 0 {
-		return errors.New("item not found")
+	return item, nil
+}
+
+// Purge must run inside a transaction so the reference check and the
+// deletes see the same data.
+func (r *ItemRepository) Purge(ctx context.Context, id int64) error {
+	schema := r.db.Schema
+	q := r.db.conn(ctx)
+
+	// Lock the row so no sale or adjustment can reference it meanwhile.
+	lock := fmt.Sprintf(`SELECT id FROM %s.items WHERE id = $1 FOR UPDATE`, schema)
+	if err := q.QueryRow(ctx, lock, id).Scan(&id); err != nil {
+		return translateError(fmt.Errorf("failed to lock item: %w", err), "item")
+	}
+
+	var referenced bool
+	check := fmt.Sprintf(`
+		SELECT EXISTS (SELECT 1 FROM %[1]s.sales_order_items WHERE item_id = $1)
+			OR EXISTS (SELECT 1 FROM %[1]s.stock_adjustments WHERE item_id = $1)
+			OR EXISTS (SELECT 1 FROM %[1]s.stock_movements WHERE item_id = $1 AND type <> $2)
+	`, schema)
+	if err := q.QueryRow(ctx, check, id, domain.MovementInitial).Scan(&referenced); err != nil {
+		return fmt.Errorf("failed to check item references: %w", err)
+	}
+	if referenced {
+		return domain.NewConflict("item has sales or stock history and can only be archived")
+	}
+	// Attachments have blobs outside the database, so they are removed
+	// through the attachment API rather than here.
+	var attached bool
+	attachments := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s.item_attachments WHERE item_id = $1)`, schema)
+	if err := q.QueryRow(ctx, attachments, id).Scan(&attached); err != nil {
+		return fmt.Errorf("failed to check item attachments: %w", err)
+	}
+	if attached {
+		return domain.NewConflict("item has attachments; delete them first")
+	}
+
+	for _, table := range []string{"price_history", "item_categories", "item_tags", "item_units", "halal_documents"} {
+		del := fmt.Sprintf(`DELETE FROM %s.%s WHERE item_id = $1`, schema, table)
+		if _, err := q.Exec(ctx, del, id); err != nil {
+			return fmt.Errorf("failed to delete item %s: %w", table, err)
+		}
+	}
+	layers := fmt.Sprintf(`DELETE FROM %s.cost_layers WHERE item_id = $1`, schema)
+	if _, err := q.Exec(ctx, layers, id); err != nil {
+		return fmt.Errorf("failed to delete item cost layers: %w", err)
+	}
+	movements := fmt.Sprintf(`DELETE FROM %s.stock_movements WHERE item_id = $1`, schema)
+	if _, err := q.Exec(ctx, movements, id); err != nil {
+		return fmt.Errorf("failed to delete item movements: %w", err)
+	}
+	del := fmt.Sprintf(`DELETE FROM %s.items WHERE id = $1`, schema)
+	if _, err := q.Exec(ctx, del, id); err != nil {
+		return translateError(fmt.Errorf("failed to delete item: %w", err), "item")
 	}
 	return nil
 }
@@ -73,63 +347,124 @@ func (r *ItemRepository) Delete(ctx context.Context, id int64) error {
 func (r *ItemRepository) GetByID(ctx context.Context, id int64) (*domain.Item, error) {
 	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
 	query := fmt.Sprintf(`
-		SELECT id, name, barcode, price, location, is_halal, quantity, created_at, updated_at
+		SELECT %s
 		FROM %s
 		WHERE id = $1
-	`, itemsTable)
-	var item domain.Item
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&item.ID, &item.Name, &item.Barcode, &item.Price, &item.Location, &item.IsHalal, &item.Quantity, &item.CreatedAt, &item.UpdatedAt,
-	)
+	`, r.columns(), itemsTable)
+	item, err := scanItem(r.db.conn(ctx).QueryRow(ctx, query, id))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil // Not found
-		}
-		return nil, fmt.Errorf("failed to get item by id: %w", err)
+		return nil, translateError(fmt.Errorf("failed to get item by id: %w", err), "item")
 	}
-	return &item, nil
+	return item, nil
 }
 
+// GetByBarcode matches the barcode and its leading-zero GTIN variants
+// against items and their units, preferring an exact match.
 func (r *ItemRepository) GetByBarcode(ctx context.Context, barcode string) (*domain.Item, error) {
-	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
 	query := fmt.Sprintf(`
-		SELECT id, name, barcode, price, location, is_halal, quantity, created_at, updated_at
-		FROM %s
-		WHERE barcode = $1
-	`, itemsTable)
-	var item domain.Item
-	err := r.db.Pool.QueryRow(ctx, query, barcode).Scan(
-		&item.ID, &item.Name, &item.Barcode, &item.Price, &item.Location, &item.IsHalal, &item.Quantity, &item.CreatedAt, &item.UpdatedAt,
-	)
+		SELECT %[2]s
+		FROM %[1]s.items
+		WHERE items.barcode = ANY($1)
+			OR EXISTS (SELECT 1 FROM %[1]s.item_units u WHERE u.item_id = items.id AND u.barcode = ANY($1))
+		ORDER BY items.barcode = $2 DESC
+		LIMIT 1
+	`, r.db.Schema, r.columns())
+	item, err := scanItem(r.db.conn(ctx).QueryRow(ctx, query, domain.BarcodeVariants(barcode), barcode))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil // Not found
-		}
-		return nil, fmt.Errorf("failed to get item by barcode: %w", err)
+		return nil, translateError(fmt.Errorf("failed to get item by barcode: %w", err), "item")
 	}
-	return &item, nil
+	return item, nil
 }
 
-func (r *ItemRepository) List(ctx context.Context) ([]*domain.Item, error) {
-	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
+func (r *ItemRepository) List(ctx context.Context, filter domain.ItemFilter) ([]*domain.Item, error) {
+	var items []*domain.Item
+	err := r.Each(ctx, filter, func(item *domain.Item) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Each reads rows as they arrive from the server, so memory use does not
+// grow with the number of items.
+func (r *ItemRepository) Each(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+	schema := r.db.Schema
+	var (
+		conds []string
+		args  []any
+	)
+	switch filter.Archived {
+	case domain.ArchiveExclude:
+		conds = append(conds, "items.archived_at IS NULL")
+	case domain.ArchiveOnly:
+		conds = append(conds, "items.archived_at IS NOT NULL")
+	}
+	if filter.CategoryID != 0 {
+		args = append(args, filter.CategoryID)
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM %[1]s.categories WHERE id = $%[2]d
+				UNION
+				SELECT c.id FROM %[1]s.categories c JOIN tree ON c.parent_id = tree.id
+			)
+			SELECT 1 FROM %[1]s.item_categories ic
+			WHERE ic.item_id = items.id AND ic.category_id IN (SELECT id FROM tree)
+		)`, schema, len(args)))
+	}
+	if filter.BrandID != 0 {
+		args = append(args, filter.BrandID)
+		conds = append(conds, fmt.Sprintf("items.brand_id = $%d", len(args)))
+	}
+	if filter.ProductID != 0 {
+		args = append(args, filter.ProductID)
+		conds = append(conds, fmt.Sprintf("items.product_id = $%d", len(args)))
+	}
+	if filter.IDs != nil {
+		args = append(args, filter.IDs)
+		conds = append(conds, fmt.Sprintf("items.id = ANY($%d)", len(args)))
+	}
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM %[1]s.item_tags it JOIN %[1]s.tags t ON t.id = it.tag_id
+			WHERE it.item_id = items.id AND t.name = $%[2]d
+		)`, schema, len(args)))
+	}
 	query := fmt.Sprintf(`
-		SELECT id, name, barcode, price, location, is_halal, quantity, created_at, updated_at
-		FROM %s
-		ORDER BY name ASC
-	`, itemsTable)
-	rows, err := r.db.Pool.Query(ctx, query)
+		SELECT %s
+		FROM %s.items
+		%s
+		ORDER BY items.name ASC
+	`, r.columns(), schema, whereClause(conds))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list items: %w", err)
+		return fmt.Errorf("failed to list items: %w", err)
 	}
 	defer rows.Close()
 
-	var items []*domain.Item
 	for rows.Next() {
-		var item domain.Item
-		if err := rows.Scan(&item.ID, &item.Name, &item.Barcode, &item.Price, &item.Location, &item.IsHalal, &item.Quantity, &item.CreatedAt, &item.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan item: %w", err)
+		item, err := scanItem(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan item: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
 		}
-		items = append(items, &item)
 	}
-	return items, nil
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list items: %w", err)
+	}
+	return nil
+}
+
+func (r *ItemRepository) NextBarcodeSequence(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf(`SELECT nextval('%s.internal_barcode_seq')`, r.db.Schema)
+	var seq int64
+	if err := r.db.conn(ctx).QueryRow(ctx, query).Scan(&seq); err != nil {
+		return 0, fmt.Errorf("failed to get barcode sequence: %w", err)
+	}
+	return seq, nil
 }
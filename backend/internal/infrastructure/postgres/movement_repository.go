@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+	"time"
+)
+
+type MovementRepository struct {
+	db *DB
+}
+
+func NewMovementRepository(db *DB) *MovementRepository {
+	return &MovementRepository{db: db}
+}
+
+func (r *MovementRepository) Create(ctx context.Context, m *domain.StockMovement) error {
+	m.Delta, m.QuantityAfter = domain.RoundQuantity(m.Delta), domain.RoundQuantity(m.QuantityAfter)
+	movementsTable := fmt.Sprintf("%s.stock_movements", r.db.Schema)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (item_id, type, delta, quantity_after, unit_cost, value_after, location, reference, user_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING id, created_at
+	`, movementsTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query,
+		m.ItemID, m.Type, m.Delta, m.QuantityAfter, m.UnitCost, m.ValueAfter, m.Location, m.Reference, nullableUUID(m.UserID), m.Note,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to record stock movement: %w", err), "stock movement")
+	}
+	return nil
+}
+
+func (r *MovementRepository) ListByItem(ctx context.Context, itemID int64) ([]*domain.StockMovement, error) {
+	movementsTable := fmt.Sprintf("%s.stock_movements", r.db.Schema)
+	query := fmt.Sprintf(`
+		SELECT id, item_id, type, delta, quantity_after, unit_cost, value_after, location, reference, COALESCE(user_id::text, ''), note, created_at
+		FROM %s
+		WHERE item_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, movementsTable)
+	rows, err := r.db.conn(ctx).Query(ctx, query, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []*domain.StockMovement
+	for rows.Next() {
+		var m domain.StockMovement
+		if err := rows.Scan(&m.ID, &m.ItemID, &m.Type, &m.Delta, &m.QuantityAfter, &m.UnitCost, &m.ValueAfter, &m.Location, &m.Reference, &m.UserID, &m.Note, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		movements = append(movements, &m)
+	}
+	return movements, nil
+}
+
+func (r *MovementRepository) QuantityAt(ctx context.Context, itemID int64, at time.Time) (float64, error) {
+	schema := r.db.Schema
+	// One statement, so the quantity and the movements come from the same
+	// snapshot.
+	query := fmt.Sprintf(`
+		SELECT i.quantity - COALESCE((
+			SELECT SUM(m.delta) FROM %[1]s.stock_movements m WHERE m.item_id = i.id AND m.created_at > $2
+		), 0)
+		FROM %[1]s.items i WHERE i.id = $1
+	`, schema)
+	var quantity float64
+	if err := r.db.conn(ctx).QueryRow(ctx, query, itemID, at).Scan(&quantity); err != nil {
+		return 0, translateError(fmt.Errorf("failed to get stock at %s: %w", at.Format(time.RFC3339), err), "item")
+	}
+	return domain.RoundQuantity(quantity), nil
+}
+
+func (r *MovementRepository) Each(ctx context.Context, filter domain.MovementFilter, fn func(*domain.StockMovement) error) error {
+	schema := r.db.Schema
+	args := []any{}
+	conds := rangeConditions("m.created_at", filter.Period, &args)
+	if filter.ItemID != 0 {
+		args = append(args, filter.ItemID)
+		conds = append(conds, fmt.Sprintf("m.item_id = $%d", len(args)))
+	}
+	query := fmt.Sprintf(`
+		SELECT m.id, m.item_id, i.name, m.type, m.delta, m.quantity_after, m.unit_cost, m.value_after, m.location, m.reference, COALESCE(m.user_id::text, ''), m.note, m.created_at
+		FROM %[1]s.stock_movements m
+		JOIN %[1]s.items i ON i.id = m.item_id
+		%[2]s
+		ORDER BY m.created_at, m.id
+	`, schema, whereClause(conds))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m domain.StockMovement
+		if err := rows.Scan(&m.ID, &m.ItemID, &m.ItemName, &m.Type, &m.Delta, &m.QuantityAfter, &m.UnitCost, &m.ValueAfter, &m.Location, &m.Reference, &m.UserID, &m.Note, &m.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		if err := fn(&m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export stock movements: %w", err)
+	}
+	return nil
+}
+
+// nullableUUID maps an empty user id to NULL for uuid columns.
+func nullableUUID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
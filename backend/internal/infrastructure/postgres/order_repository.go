@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"multi-inventory/internal/domain"
 
@@ -17,18 +18,33 @@ func NewOrderRepository(db *DB) *OrderRepository {
 }
 
 func (r *OrderRepository) Create(ctx context.Context, order *domain.SalesOrder) error {
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.db.begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return err
 	}
 	defer tx.Rollback(ctx)
 
+	// Reserve the next invoice number for the store. The sequence row stays
+	// locked until commit and rolls back with the order, so numbers are gap-free.
+	invoiceSequencesTable := fmt.Sprintf("%s.invoice_sequences", r.db.Schema)
+	seqQuery := fmt.Sprintf(`
+		INSERT INTO %s (store_code, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (store_code) DO UPDATE SET last_number = %s.last_number + 1
+		RETURNING last_number
+	`, invoiceSequencesTable, invoiceSequencesTable)
+	var invoiceSeq int64
+	if err := tx.QueryRow(ctx, seqQuery, order.StoreCode).Scan(&invoiceSeq); err != nil {
+		return fmt.Errorf("failed to reserve invoice number: %w", err)
+	}
+	order.InvoiceNumber = formatInvoiceNumber(order.StoreCode, invoiceSeq)
+
 	// Create Order
 	salesOrdersTable := fmt.Sprintf("%s.sales_orders", r.db.Schema)
 	query := fmt.Sprintf(`
-		INSERT INTO %s (user_id, total_price, status, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		INSERT INTO %s (user_id, store_code, invoice_number, total_price, status, halal_override, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, version, created_at, updated_at
 	`, salesOrdersTable)
 	var argUser any
 	if order.UserID == "" {
@@ -36,9 +52,9 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.SalesOrder)
 	} else {
 		argUser = order.UserID
 	}
-	err = tx.QueryRow(ctx, query, argUser, order.TotalPrice, order.Status).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+	err = tx.QueryRow(ctx, query, argUser, order.StoreCode, order.InvoiceNumber, order.TotalPrice, order.Status, order.HalalOverride).Scan(&order.ID, &order.Version, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
-		return fmt.Errorf("failed to create order: %w", err)
+		return translateError(fmt.Errorf("failed to create order: %w", err), "order")
 	}
 
 	// Create Order Items
@@ -49,9 +65,10 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.SalesOrder)
 		RETURNING id
 	`, salesOrderItemsTable)
 	for _, item := range order.Items {
+		item.Quantity = domain.RoundQuantity(item.Quantity)
 		err = tx.QueryRow(ctx, itemQuery, order.ID, item.ItemID, item.Quantity, item.PriceAtSale, item.IsFulfilled).Scan(&item.ID)
 		if err != nil {
-			return fmt.Errorf("failed to create order item: %w", err)
+			return translateError(fmt.Errorf("failed to create order item: %w", err), "order item")
 		}
 		item.SalesOrderID = order.ID
 	}
@@ -62,31 +79,28 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.SalesOrder)
 func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*domain.SalesOrder, error) {
 	salesOrdersTable := fmt.Sprintf("%s.sales_orders", r.db.Schema)
 	query := fmt.Sprintf(`
-		SELECT id, user_id::text, total_price, status, created_at, updated_at
+		SELECT id, COALESCE(user_id::text, ''), store_code, COALESCE(invoice_number, ''), total_price, status, halal_override, version, created_at, updated_at
 		FROM %s
 		WHERE id = $1
 	`, salesOrdersTable)
 	var order domain.SalesOrder
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&order.ID, &order.UserID, &order.TotalPrice, &order.Status, &order.CreatedAt, &order.UpdatedAt,
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&order.ID, &order.UserID, &order.StoreCode, &order.InvoiceNumber, &order.TotalPrice, &order.Status, &order.HalalOverride, &order.Version, &order.CreatedAt, &order.UpdatedAt,
 	)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get order: %w", err)
+		return nil, translateError(fmt.Errorf("failed to get order: %w", err), "order")
 	}
 
 	// Get Items
 	salesOrderItemsTable := fmt.Sprintf("%s.sales_order_items", r.db.Schema)
 	itemsTable := fmt.Sprintf("%s.items", r.db.Schema)
 	itemsQuery := fmt.Sprintf(`
-		SELECT soi.id, soi.sales_order_id, soi.item_id, soi.quantity, soi.price_at_sale, soi.is_fulfilled, i.name
+		SELECT soi.id, soi.sales_order_id, soi.item_id, soi.quantity, soi.price_at_sale, soi.cost_at_sale, soi.is_fulfilled, i.name
 		FROM %s soi
 		JOIN %s i ON soi.item_id = i.id
 		WHERE soi.sales_order_id = $1
 	`, salesOrderItemsTable, itemsTable)
-	rows, err := r.db.Pool.Query(ctx, itemsQuery, id)
+	rows, err := r.db.conn(ctx).Query(ctx, itemsQuery, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order items: %w", err)
 	}
@@ -94,7 +108,7 @@ func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*domain.SalesO
 
 	for rows.Next() {
 		var item domain.SalesOrderItem
-		if err := rows.Scan(&item.ID, &item.SalesOrderID, &item.ItemID, &item.Quantity, &item.PriceAtSale, &item.IsFulfilled, &item.ItemName); err != nil {
+		if err := rows.Scan(&item.ID, &item.SalesOrderID, &item.ItemID, &item.Quantity, &item.PriceAtSale, &item.CostAtSale, &item.IsFulfilled, &item.ItemName); err != nil {
 			return nil, fmt.Errorf("failed to scan order item: %w", err)
 		}
 		order.Items = append(order.Items, &item)
@@ -106,11 +120,11 @@ func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*domain.SalesO
 func (r *OrderRepository) List(ctx context.Context) ([]*domain.SalesOrder, error) {
 	salesOrdersTable := fmt.Sprintf("%s.sales_orders", r.db.Schema)
 	query := fmt.Sprintf(`
-		SELECT id, user_id::text, total_price, status, created_at, updated_at
+		SELECT id, COALESCE(user_id::text, ''), store_code, COALESCE(invoice_number, ''), total_price, status, halal_override, version, created_at, updated_at
 		FROM %s
 		ORDER BY created_at DESC
 	`, salesOrdersTable)
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.db.conn(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list orders: %w", err)
 	}
@@ -119,7 +133,7 @@ func (r *OrderRepository) List(ctx context.Context) ([]*domain.SalesOrder, error
 	var orders []*domain.SalesOrder
 	for rows.Next() {
 		var order domain.SalesOrder
-		if err := rows.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Status, &order.CreatedAt, &order.UpdatedAt); err != nil {
+		if err := rows.Scan(&order.ID, &order.UserID, &order.StoreCode, &order.InvoiceNumber, &order.TotalPrice, &order.Status, &order.HalalOverride, &order.Version, &order.CreatedAt, &order.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan order: %w", err)
 		}
 		orders = append(orders, &order)
@@ -127,14 +141,123 @@ func (r *OrderRepository) List(ctx context.Context) ([]*domain.SalesOrder, error
 	return orders, nil
 }
 
-func (r *OrderRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
-	query := `UPDATE sales_orders SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.Pool.Exec(ctx, query, status, id)
-	return err
+func (r *OrderRepository) EachLine(ctx context.Context, period domain.DateRange, fn func(*domain.SalesOrder, *domain.SalesOrderItem) error) error {
+	schema := r.db.Schema
+	args := []any{}
+	where := whereClause(rangeConditions("so.created_at", period, &args))
+	query := fmt.Sprintf(`
+		SELECT so.id, COALESCE(so.user_id::text, ''), so.store_code, COALESCE(so.invoice_number, ''), so.total_price, so.status, so.version, so.created_at, so.updated_at,
+			soi.id, soi.item_id, i.name, soi.quantity, soi.price_at_sale, soi.cost_at_sale, soi.is_fulfilled
+		FROM %[1]s.sales_orders so
+		JOIN %[1]s.sales_order_items soi ON soi.sales_order_id = so.id
+		JOIN %[1]s.items i ON i.id = soi.item_id
+		%[2]s
+		ORDER BY so.created_at, so.id, soi.id
+	`, schema, where)
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export order lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order domain.SalesOrder
+		var line domain.SalesOrderItem
+		err := rows.Scan(
+			&order.ID, &order.UserID, &order.StoreCode, &order.InvoiceNumber, &order.TotalPrice, &order.Status, &order.Version, &order.CreatedAt, &order.UpdatedAt,
+			&line.ID, &line.ItemID, &line.ItemName, &line.Quantity, &line.PriceAtSale, &line.CostAtSale, &line.IsFulfilled,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan order line: %w", err)
+		}
+		line.SalesOrderID = order.ID
+		if err := fn(&order, &line); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export order lines: %w", err)
+	}
+	return nil
+}
+
+func (r *OrderRepository) SetLineCosts(ctx context.Context, lines []*domain.SalesOrderItem) error {
+	salesOrderItemsTable := fmt.Sprintf("%s.sales_order_items", r.db.Schema)
+	query := fmt.Sprintf(`UPDATE %s SET cost_at_sale = $2 WHERE id = $1`, salesOrderItemsTable)
+	for _, line := range lines {
+		if _, err := r.db.conn(ctx).Exec(ctx, query, line.ID, line.CostAtSale); err != nil {
+			return fmt.Errorf("failed to set cost of order line %d: %w", line.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id int64, status string, expectedVersion int64) (int64, error) {
+	salesOrdersTable := fmt.Sprintf("%s.sales_orders", r.db.Schema)
+	query := fmt.Sprintf(`
+		UPDATE %s SET status = $1, version = version + 1, updated_at = NOW()
+		WHERE id = $2 AND ($3 = 0 OR version = $3)
+		RETURNING version
+	`, salesOrdersTable)
+	var version int64
+	err := r.db.conn(ctx).QueryRow(ctx, query, status, id, expectedVersion).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, r.staleOrMissing(ctx, r.db.conn(ctx), id)
+	}
+	if err != nil {
+		return 0, translateError(fmt.Errorf("failed to update order status: %w", err), "order")
+	}
+	return version, nil
+}
+
+// UpdateItemFulfillment toggles a line and bumps the version of its order in
+// one transaction, so the line change is covered by the order's ETag.
+func (r *OrderRepository) UpdateItemFulfillment(ctx context.Context, itemId int64, isFulfilled bool, expectedVersion int64) (*domain.FulfillmentChange, error) {
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	salesOrderItemsTable := fmt.Sprintf("%s.sales_order_items", r.db.Schema)
+	query := fmt.Sprintf(`UPDATE %s SET is_fulfilled = $1 WHERE id = $2 RETURNING sales_order_id, item_id`, salesOrderItemsTable)
+	change := &domain.FulfillmentChange{LineID: itemId, IsFulfilled: isFulfilled}
+	err = tx.QueryRow(ctx, query, isFulfilled, itemId).Scan(&change.OrderID, &change.ItemID)
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to update fulfillment: %w", err), "order item")
+	}
+
+	salesOrdersTable := fmt.Sprintf("%s.sales_orders", r.db.Schema)
+	query = fmt.Sprintf(`
+		UPDATE %s SET version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND ($2 = 0 OR version = $2)
+		RETURNING version
+	`, salesOrdersTable)
+	err = tx.QueryRow(ctx, query, change.OrderID, expectedVersion).Scan(&change.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.staleOrMissing(ctx, tx, change.OrderID)
+	}
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to update order version: %w", err), "order")
+	}
+	return change, tx.Commit(ctx)
+}
+
+// staleOrMissing tells apart the two reasons a versioned update matches no row.
+func (r *OrderRepository) staleOrMissing(ctx context.Context, q querier, id int64) error {
+	salesOrdersTable := fmt.Sprintf("%s.sales_orders", r.db.Schema)
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, salesOrdersTable)
+	if err := q.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check order: %w", err)
+	}
+	if !exists {
+		return domain.NewNotFound("order not found")
+	}
+	return domain.NewPreconditionFailed("order was modified by someone else")
 }
 
-func (r *OrderRepository) UpdateItemFulfillment(ctx context.Context, itemId int64, isFulfilled bool) error {
-	query := `UPDATE sales_order_items SET is_fulfilled = $1 WHERE id = $2`
-	_, err := r.db.Pool.Exec(ctx, query, isFulfilled, itemId)
-	return err
+// formatInvoiceNumber renders a per-store sequence value, e.g. MAIN-000042.
+func formatInvoiceNumber(storeCode string, seq int64) string {
+	return fmt.Sprintf("%s-%06d", storeCode, seq)
 }
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+type OutboxRepository struct {
+	db *DB
+}
+
+func NewOutboxRepository(db *DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) Append(ctx context.Context, events []domain.Event) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.outbox (topic, type, locations, data, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, r.db.Schema)
+	for _, ev := range events {
+		locations := ev.Locations
+		if locations == nil {
+			locations = []string{}
+		}
+		if _, err := r.db.conn(ctx).Exec(ctx, query, string(ev.Topic), ev.Type, locations, string(ev.Data), ev.At); err != nil {
+			return fmt.Errorf("failed to append %s to outbox: %w", ev.Type, err)
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRepository) Pending(ctx context.Context, limit int) ([]domain.OutboxEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT id, topic, type, locations, data::text, created_at
+		FROM %s.outbox
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.OutboxEntry
+	for rows.Next() {
+		e, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// scanOutboxEntry reads id, topic, type, locations, data and created_at.
+func scanOutboxEntry(row rowScanner) (domain.OutboxEntry, error) {
+	var (
+		e     domain.OutboxEntry
+		topic string
+		data  string
+	)
+	if err := row.Scan(&e.ID, &topic, &e.Event.Type, &e.Event.Locations, &data, &e.Event.At); err != nil {
+		return e, err
+	}
+	e.Event.ID = fmt.Sprint(e.ID)
+	e.Event.Topic = domain.EventTopic(topic)
+	e.Event.Data = []byte(data)
+	return e, nil
+}
+
+func (r *OutboxRepository) MarkDispatched(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`UPDATE %s.outbox SET dispatched_at = NOW() WHERE id = ANY($1)`, r.db.Schema)
+	if _, err := r.db.conn(ctx).Exec(ctx, query, ids); err != nil {
+		return fmt.Errorf("failed to mark outbox dispatched: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) DeleteDispatched(ctx context.Context, before time.Time) (int64, error) {
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s.outbox o
+		WHERE o.dispatched_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM %[1]s.webhook_deliveries d WHERE d.event_id = o.id)
+	`, r.db.Schema)
+	tag, err := r.db.conn(ctx).Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete dispatched outbox entries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+	"time"
+)
+
+const priceColumns = `id, item_id, old_price, price, reason, COALESCE(changed_by::text, ''), status,
+	effective_at, applied_at, created_at`
+
+func scanPriceChange(row rowScanner) (*domain.PriceChange, error) {
+	var c domain.PriceChange
+	err := row.Scan(&c.ID, &c.ItemID, &c.OldPrice, &c.Price, &c.Reason, &c.ChangedBy, &c.Status,
+		&c.EffectiveAt, &c.AppliedAt, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+type PriceRepository struct {
+	db *DB
+}
+
+func NewPriceRepository(db *DB) *PriceRepository {
+	return &PriceRepository{db: db}
+}
+
+func (r *PriceRepository) Create(ctx context.Context, change *domain.PriceChange) error {
+	priceTable := fmt.Sprintf("%s.price_history", r.db.Schema)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (item_id, old_price, price, reason, changed_by, status, effective_at, applied_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING id, created_at
+	`, priceTable)
+	err := r.db.conn(ctx).QueryRow(ctx, query,
+		change.ItemID, change.OldPrice, change.Price, change.Reason, nullableUUID(change.ChangedBy),
+		change.Status, change.EffectiveAt, change.AppliedAt,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to record price change: %w", err), "price change")
+	}
+	return nil
+}
+
+func (r *PriceRepository) GetByID(ctx context.Context, id int64) (*domain.PriceChange, error) {
+	priceTable := fmt.Sprintf("%s.price_history", r.db.Schema)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, priceColumns, priceTable)
+	change, err := scanPriceChange(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get price change: %w", err), "price change")
+	}
+	return change, nil
+}
+
+func (r *PriceRepository) ListByItem(ctx context.Context, itemID int64) ([]*domain.PriceChange, error) {
+	priceTable := fmt.Sprintf("%s.price_history", r.db.Schema)
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE item_id = $1
+		ORDER BY effective_at DESC, id DESC
+	`, priceColumns, priceTable)
+	return r.list(ctx, query, itemID)
+}
+
+func (r *PriceRepository) Due(ctx context.Context, at time.Time, limit int) ([]*domain.PriceChange, error) {
+	priceTable := fmt.Sprintf("%s.price_history", r.db.Schema)
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE status = $1 AND effective_at <= $2
+		ORDER BY effective_at, id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, priceColumns, priceTable)
+	return r.list(ctx, query, domain.PriceScheduled, at, limit)
+}
+
+func (r *PriceRepository) list(ctx context.Context, query string, args ...any) ([]*domain.PriceChange, error) {
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*domain.PriceChange{}
+	for rows.Next() {
+		change, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+func (r *PriceRepository) SetStatus(ctx context.Context, change *domain.PriceChange) error {
+	priceTable := fmt.Sprintf("%s.price_history", r.db.Schema)
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET status = $2, old_price = $3, applied_at = $4
+		WHERE id = $1 AND status = $5
+	`, priceTable)
+	tag, err := r.db.conn(ctx).Exec(ctx, query,
+		change.ID, change.Status, change.OldPrice, change.AppliedAt, domain.PriceScheduled,
+	)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to update price change: %w", err), "price change")
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewConflict("price change is no longer scheduled")
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+)
+
+// productColumns is scanned by scanProduct; stock is summed over the active
+// variants.
+const productColumns = `p.id, p.name, p.attributes,
+	COALESCE(SUM(i.quantity) FILTER (WHERE i.archived_at IS NULL), 0),
+	COUNT(i.id) FILTER (WHERE i.archived_at IS NULL),
+	p.created_at, p.updated_at`
+
+func scanProduct(row rowScanner) (*domain.Product, error) {
+	var p domain.Product
+	if err := row.Scan(&p.ID, &p.Name, &p.Attributes, &p.TotalStock, &p.VariantCount, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+type ProductRepository struct {
+	db *DB
+}
+
+func NewProductRepository(db *DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+func (r *ProductRepository) Create(ctx context.Context, p *domain.Product) error {
+	productsTable := fmt.Sprintf("%s.products", r.db.Schema)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (name, attributes, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, productsTable)
+	if err := r.db.conn(ctx).QueryRow(ctx, query, p.Name, p.Attributes).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return translateError(fmt.Errorf("failed to create product: %w", err), "product")
+	}
+	return nil
+}
+
+func (r *ProductRepository) Update(ctx context.Context, p *domain.Product) error {
+	productsTable := fmt.Sprintf("%s.products", r.db.Schema)
+	query := fmt.Sprintf(`
+		UPDATE %s SET name = $2, attributes = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, productsTable)
+	if err := r.db.conn(ctx).QueryRow(ctx, query, p.ID, p.Name, p.Attributes).Scan(&p.CreatedAt, &p.UpdatedAt); err != nil {
+		return translateError(fmt.Errorf("failed to update product: %w", err), "product")
+	}
+	return nil
+}
+
+func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
+	schema := r.db.Schema
+	var used bool
+	check := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s.items WHERE product_id = $1)`, schema)
+	if err := r.db.conn(ctx).QueryRow(ctx, check, id).Scan(&used); err != nil {
+		return fmt.Errorf("failed to check product variants: %w", err)
+	}
+	if used {
+		return domain.NewConflict("product still has variants")
+	}
+
+	del := fmt.Sprintf(`DELETE FROM %s.products WHERE id = $1`, schema)
+	tag, err := r.db.conn(ctx).Exec(ctx, del, id)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to delete product: %w", err), "product")
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFound("product not found")
+	}
+	return nil
+}
+
+func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*domain.Product, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %[2]s.products p
+		LEFT JOIN %[2]s.items i ON i.product_id = p.id
+		WHERE p.id = $1
+		GROUP BY p.id
+	`, productColumns, r.db.Schema)
+	p, err := scanProduct(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get product: %w", err), "product")
+	}
+	return p, nil
+}
+
+func (r *ProductRepository) List(ctx context.Context) ([]*domain.Product, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %[2]s.products p
+		LEFT JOIN %[2]s.items i ON i.product_id = p.id
+		GROUP BY p.id
+		ORDER BY p.name, p.id
+	`, productColumns, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []*domain.Product{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+func (r *ProductRepository) Lock(ctx context.Context, id int64) error {
+	query := fmt.Sprintf(`SELECT id FROM %s.products WHERE id = $1 FOR UPDATE`, r.db.Schema)
+	if err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(&id); err != nil {
+		return translateError(fmt.Errorf("failed to lock product: %w", err), "product")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"multi-inventory/internal/domain"
+)
+
+// rangeConditions limits column to the date range, appending the bounds to
+// args. An open range adds no conditions.
+func rangeConditions(column string, period domain.DateRange, args *[]any) []string {
+	var conds []string
+	if !period.From.IsZero() {
+		*args = append(*args, period.From)
+		conds = append(conds, fmt.Sprintf("%s >= $%d", column, len(*args)))
+	}
+	if !period.To.IsZero() {
+		*args = append(*args, period.To)
+		conds = append(conds, fmt.Sprintf("%s < $%d", column, len(*args)))
+	}
+	return conds
+}
+
+// whereClause joins conditions with AND, or returns "" when there are none.
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
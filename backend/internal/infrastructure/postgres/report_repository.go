@@ -0,0 +1,372 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+type ReportRepository struct {
+	db *DB
+}
+
+func NewReportRepository(db *DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// salesLines is the FROM clause shared by the sales aggregates: one row per
+// order line, with the item for location filters.
+const salesLines = `
+	%[1]s.sales_orders so
+	JOIN %[1]s.sales_order_items soi ON soi.sales_order_id = so.id
+	JOIN %[1]s.items i ON i.id = soi.item_id`
+
+// locationCondition limits rows to items at the location, if one is given.
+func locationCondition(location string, args *[]any) []string {
+	if location == "" {
+		return nil
+	}
+	*args = append(*args, location)
+	return []string{fmt.Sprintf("i.location = $%d", len(*args))}
+}
+
+// SalesTotals computes every period in a single scan using FILTER clauses.
+func (r *ReportRepository) SalesTotals(ctx context.Context, location string, periods ...domain.DateRange) ([]domain.SalesTotals, error) {
+	if len(periods) == 0 {
+		return nil, nil
+	}
+	var args []any
+	selects := make([]string, 0, 2*len(periods))
+	var outer []string // OR of all periods, so the scan can use the created_at index
+	for _, p := range periods {
+		conds := rangeConditions("so.created_at", p, &args)
+		filter := "TRUE"
+		if len(conds) > 0 {
+			filter = strings.Join(conds, " AND ")
+		}
+		outer = append(outer, "("+filter+")")
+		selects = append(selects,
+			fmt.Sprintf("COALESCE(SUM(soi.quantity * soi.price_at_sale) FILTER (WHERE %s), 0)", filter),
+			fmt.Sprintf("COUNT(DISTINCT so.id) FILTER (WHERE %s)", filter),
+		)
+	}
+	conds := append(locationCondition(location, &args), "("+strings.Join(outer, " OR ")+")")
+	query := fmt.Sprintf(`SELECT %s FROM %s %s`,
+		strings.Join(selects, ", "), fmt.Sprintf(salesLines, r.db.Schema), whereClause(conds))
+
+	totals := make([]domain.SalesTotals, len(periods))
+	dest := make([]any, 0, 2*len(periods))
+	for i := range totals {
+		dest = append(dest, &totals[i].Revenue, &totals[i].Orders)
+	}
+	if err := r.db.conn(ctx).QueryRow(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to compute sales totals: %w", err)
+	}
+	return totals, nil
+}
+
+func (r *ReportRepository) PendingOrders(ctx context.Context, location string) (int, error) {
+	var args []any
+	conds := append(locationCondition(location, &args), "soi.is_fulfilled = FALSE")
+	query := fmt.Sprintf(`SELECT COUNT(DISTINCT so.id) FROM %s %s`,
+		fmt.Sprintf(salesLines, r.db.Schema), whereClause(conds))
+	var n int
+	if err := r.db.conn(ctx).QueryRow(ctx, query, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count pending orders: %w", err)
+	}
+	return n, nil
+}
+
+func (r *ReportRepository) StockSummary(ctx context.Context, location string, lowStockThreshold int) (domain.StockSummary, error) {
+	args := []any{lowStockThreshold}
+	conds := append(locationCondition(location, &args), "i.archived_at IS NULL")
+	// Reorder points are inherited down the category tree; an item takes its
+	// own, else the first of its categories that has one.
+	query := fmt.Sprintf(`
+		WITH RECURSIVE category_settings AS (
+			SELECT id, reorder_point FROM %[1]s.categories WHERE parent_id IS NULL
+			UNION ALL
+			SELECT c.id, COALESCE(c.reorder_point, cs.reorder_point)
+			FROM %[1]s.categories c JOIN category_settings cs ON c.parent_id = cs.id
+		)
+		SELECT COUNT(*) FILTER (WHERE i.quantity <= COALESCE(i.reorder_point, (
+				SELECT cs.reorder_point
+				FROM %[1]s.item_categories ic JOIN category_settings cs ON cs.id = ic.category_id
+				WHERE ic.item_id = i.id AND cs.reorder_point IS NOT NULL
+				ORDER BY ic.position
+				LIMIT 1
+			), $1)),
+			COALESCE(SUM(i.quantity * i.price), 0),
+			COALESCE(SUM(i.stock_value), 0)
+		FROM %[1]s.items i
+		%[2]s
+	`, r.db.Schema, whereClause(conds))
+	var s domain.StockSummary
+	if err := r.db.conn(ctx).QueryRow(ctx, query, args...).Scan(&s.LowStockItems, &s.RetailValue, &s.CostValue); err != nil {
+		return s, fmt.Errorf("failed to summarize stock: %w", err)
+	}
+	return s, nil
+}
+
+func (r *ReportRepository) TopSellers(ctx context.Context, filter domain.ReportFilter, by domain.RankBy, limit int) ([]domain.TopSeller, error) {
+	var args []any
+	conds := append(rangeConditions("so.created_at", filter.Period, &args), locationCondition(filter.Location, &args)...)
+	args = append(args, limit)
+	order := "SUM(soi.quantity) DESC, SUM(soi.quantity * soi.price_at_sale) DESC"
+	if by == domain.RankByRevenue {
+		order = "SUM(soi.quantity * soi.price_at_sale) DESC, SUM(soi.quantity) DESC"
+	}
+	query := fmt.Sprintf(`
+		SELECT soi.item_id, i.name, SUM(soi.quantity), SUM(soi.quantity * soi.price_at_sale)
+		FROM %s
+		%s
+		GROUP BY soi.item_id, i.name
+		ORDER BY %s, soi.item_id
+		LIMIT $%d
+	`, fmt.Sprintf(salesLines, r.db.Schema), whereClause(conds), order, len(args))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank top sellers: %w", err)
+	}
+	defer rows.Close()
+
+	sellers := []domain.TopSeller{}
+	for rows.Next() {
+		var s domain.TopSeller
+		if err := rows.Scan(&s.ItemID, &s.Name, &s.Quantity, &s.Revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan top seller: %w", err)
+		}
+		sellers = append(sellers, s)
+	}
+	return sellers, rows.Err()
+}
+
+// SalesSeries generates every bucket of the period so days without sales
+// appear as zeros. Buckets follow the database session time zone, which
+// NewDB sets to the application's.
+func (r *ReportRepository) SalesSeries(ctx context.Context, filter domain.ReportFilter, interval domain.Interval) ([]domain.SalesBucket, error) {
+	args := []any{string(interval), filter.Period.From, filter.Period.To}
+	lineJoin := "JOIN %[1]s.items i ON i.id = soi.item_id"
+	if filter.Location != "" {
+		args = append(args, filter.Location)
+		lineJoin += fmt.Sprintf(" AND i.location = $%d", len(args))
+	}
+	query := fmt.Sprintf(`
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($1, $2::timestamptz),
+				$3::timestamptz - interval '1 microsecond',
+				('1 ' || $1)::interval
+			) AS start
+		), lines AS (
+			SELECT date_trunc($1, so.created_at) AS start, so.id AS order_id,
+				soi.quantity, soi.quantity * soi.price_at_sale AS amount
+			FROM %[1]s.sales_orders so
+			JOIN %[1]s.sales_order_items soi ON soi.sales_order_id = so.id
+			`+lineJoin+`
+			WHERE so.created_at >= $2 AND so.created_at < $3
+		)
+		SELECT b.start, COALESCE(SUM(l.amount), 0), COUNT(DISTINCT l.order_id), COALESCE(SUM(l.quantity), 0)
+		FROM buckets b
+		LEFT JOIN lines l ON l.start = b.start
+		GROUP BY b.start
+		ORDER BY b.start
+	`, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute sales series: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []domain.SalesBucket{}
+	for rows.Next() {
+		var b domain.SalesBucket
+		if err := rows.Scan(&b.Start, &b.Revenue, &b.Orders, &b.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan sales bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func (r *ReportRepository) DeadStock(ctx context.Context, since time.Time, location string) ([]domain.DeadStockItem, error) {
+	args := []any{since}
+	conds := append(locationCondition(location, &args),
+		"i.archived_at IS NULL", "i.quantity > 0", "i.created_at < $1",
+		"(last.sold_at IS NULL OR last.sold_at < $1)")
+	query := fmt.Sprintf(`
+		SELECT i.id, i.name, i.location, i.quantity, i.quantity * i.price, last.sold_at
+		FROM %[1]s.items i
+		LEFT JOIN LATERAL (
+			SELECT MAX(so.created_at) AS sold_at
+			FROM %[1]s.sales_order_items soi
+			JOIN %[1]s.sales_orders so ON so.id = soi.sales_order_id
+			WHERE soi.item_id = i.id
+		) last ON TRUE
+		%[2]s
+		ORDER BY i.quantity * i.price DESC, i.id
+	`, r.db.Schema, whereClause(conds))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead stock: %w", err)
+	}
+	defer rows.Close()
+
+	items := []domain.DeadStockItem{}
+	for rows.Next() {
+		var d domain.DeadStockItem
+		if err := rows.Scan(&d.ItemID, &d.Name, &d.Location, &d.Quantity, &d.Value, &d.LastSoldAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dead stock: %w", err)
+		}
+		items = append(items, d)
+	}
+	return items, rows.Err()
+}
+
+// SellThrough takes the stock at the end of the period as the current
+// quantity minus every movement since. Items without ledger entries (created
+// before movements were recorded) are assumed unchanged.
+func (r *ReportRepository) SellThrough(ctx context.Context, filter domain.ReportFilter) ([]domain.SellThroughItem, error) {
+	args := []any{filter.Period.From, filter.Period.To}
+	conds := append(locationCondition(filter.Location, &args),
+		"(COALESCE(s.qty, 0) > 0 OR i.quantity - COALESCE(l.delta, 0) > 0)")
+	query := fmt.Sprintf(`
+		WITH sold AS (
+			SELECT soi.item_id, SUM(soi.quantity) AS qty
+			FROM %[1]s.sales_orders so
+			JOIN %[1]s.sales_order_items soi ON soi.sales_order_id = so.id
+			WHERE so.created_at >= $1 AND so.created_at < $2
+			GROUP BY soi.item_id
+		), later AS (
+			SELECT item_id, SUM(delta) AS delta
+			FROM %[1]s.stock_movements
+			WHERE created_at >= $2
+			GROUP BY item_id
+		)
+		SELECT i.id, i.name, COALESCE(s.qty, 0), GREATEST(i.quantity - COALESCE(l.delta, 0), 0)
+		FROM %[1]s.items i
+		LEFT JOIN sold s ON s.item_id = i.id
+		LEFT JOIN later l ON l.item_id = i.id
+		%[2]s
+		ORDER BY COALESCE(s.qty, 0) DESC, i.id
+	`, r.db.Schema, whereClause(conds))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute sell-through: %w", err)
+	}
+	defer rows.Close()
+
+	items := []domain.SellThroughItem{}
+	for rows.Next() {
+		var st domain.SellThroughItem
+		if err := rows.Scan(&st.ItemID, &st.Name, &st.Sold, &st.OnHand); err != nil {
+			return nil, fmt.Errorf("failed to scan sell-through: %w", err)
+		}
+		if total := st.Sold + st.OnHand; total > 0 {
+			st.Rate = math.Round(st.Sold/total*10000) / 10000
+		}
+		items = append(items, st)
+	}
+	return items, rows.Err()
+}
+
+func (r *ReportRepository) Margins(ctx context.Context, filter domain.ReportFilter) ([]domain.ItemMargin, error) {
+	var args []any
+	conds := append(rangeConditions("so.created_at", filter.Period, &args), locationCondition(filter.Location, &args)...)
+	conds = append(conds, "soi.cost_at_sale IS NOT NULL")
+	query := fmt.Sprintf(`
+		SELECT soi.item_id, i.name, SUM(soi.quantity),
+			SUM(soi.quantity * soi.price_at_sale), SUM(soi.quantity * soi.cost_at_sale)
+		FROM %s
+		%s
+		GROUP BY soi.item_id, i.name
+		ORDER BY SUM(soi.quantity * (soi.price_at_sale - soi.cost_at_sale)) DESC, soi.item_id
+	`, fmt.Sprintf(salesLines, r.db.Schema), whereClause(conds))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute margins: %w", err)
+	}
+	defer rows.Close()
+
+	margins := []domain.ItemMargin{}
+	for rows.Next() {
+		var m domain.ItemMargin
+		if err := rows.Scan(&m.ItemID, &m.Name, &m.Quantity, &m.Revenue, &m.COGS); err != nil {
+			return nil, fmt.Errorf("failed to scan margin: %w", err)
+		}
+		m.COGS = math.Round(m.COGS*100) / 100
+		m.Margin = math.Round((m.Revenue-m.COGS)*100) / 100
+		if m.Revenue != 0 {
+			m.MarginRate = math.Round(m.Margin/m.Revenue*10000) / 10000
+		}
+		margins = append(margins, m)
+	}
+	return margins, rows.Err()
+}
+
+// ValuationAt reads the quantity and value recorded on each item's last
+// movement before at. Items without movements by then are left out.
+func (r *ReportRepository) ValuationAt(ctx context.Context, at time.Time, location string) ([]domain.ItemValuation, error) {
+	args := []any{at}
+	conds := append(locationCondition(location, &args), "last.quantity_after > 0")
+	query := fmt.Sprintf(`
+		SELECT i.id, i.name, i.location, last.quantity_after, last.value_after
+		FROM (
+			SELECT DISTINCT ON (item_id) item_id, quantity_after, value_after
+			FROM %[1]s.stock_movements
+			WHERE created_at < $1
+			ORDER BY item_id, created_at DESC, id DESC
+		) last
+		JOIN %[1]s.items i ON i.id = last.item_id
+		%[2]s
+		ORDER BY i.name, i.id
+	`, r.db.Schema, whereClause(conds))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute valuation: %w", err)
+	}
+	defer rows.Close()
+
+	items := []domain.ItemValuation{}
+	for rows.Next() {
+		var v domain.ItemValuation
+		if err := rows.Scan(&v.ItemID, &v.Name, &v.Location, &v.Quantity, &v.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan valuation: %w", err)
+		}
+		v.UnitCost = domain.RoundCost(v.Value / v.Quantity)
+		items = append(items, v)
+	}
+	return items, rows.Err()
+}
+
+func (r *ReportRepository) HalalAlerts(ctx context.Context, before time.Time, location string) ([]domain.HalalAlert, error) {
+	args := []any{domain.HalalCertified, before}
+	conds := append(locationCondition(location, &args),
+		"i.archived_at IS NULL", "i.halal_status = $1", "i.halal_expires_at < $2")
+	query := fmt.Sprintf(`
+		SELECT i.id, i.name, i.location, i.halal_certifying_body, i.halal_certificate_number, i.halal_expires_at,
+			i.halal_expires_at < NOW()
+		FROM %s.items i
+		%s
+		ORDER BY i.halal_expires_at, i.id
+	`, r.db.Schema, whereClause(conds))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list halal alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []domain.HalalAlert{}
+	for rows.Next() {
+		var a domain.HalalAlert
+		if err := rows.Scan(&a.ItemID, &a.Name, &a.Location, &a.CertifyingBody, &a.CertificateNumber, &a.ExpiresAt, &a.Expired); err != nil {
+			return nil, fmt.Errorf("failed to scan halal alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+)
+
+type SyncRepository struct {
+	db *DB
+}
+
+func NewSyncRepository(db *DB) *SyncRepository {
+	return &SyncRepository{db: db}
+}
+
+// Horizon is the oldest transaction still running. Rows are stamped with
+// the ID of the transaction writing them, so every row stamped below it
+// has committed or rolled back.
+func (r *SyncRepository) Horizon(ctx context.Context) (int64, error) {
+	var horizon int64
+	err := r.db.conn(ctx).QueryRow(ctx, `SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`).Scan(&horizon)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sync horizon: %w", err)
+	}
+	return horizon, nil
+}
+
+func (r *SyncRepository) Changes(ctx context.Context, after domain.SyncCursor, horizon int64, limit int) ([]domain.SyncChange, error) {
+	query := fmt.Sprintf(`
+		SELECT entity, id, change_seq, deleted FROM (
+			SELECT 'item' AS entity, id, change_seq, FALSE AS deleted FROM %[1]s.items
+			UNION ALL
+			SELECT 'order', id, change_seq, FALSE FROM %[1]s.sales_orders
+			UNION ALL
+			SELECT entity, entity_id, change_seq, TRUE FROM %[1]s.sync_tombstones
+		) c
+		WHERE c.change_seq < $1 AND (c.change_seq, c.entity, c.id) > ($2::bigint, $3::text, $4::bigint)
+		ORDER BY c.change_seq, c.entity, c.id
+		LIMIT $5
+	`, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, query, horizon, after.Seq, string(after.Entity), after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []domain.SyncChange
+	for rows.Next() {
+		var (
+			ch     domain.SyncChange
+			entity string
+		)
+		if err := rows.Scan(&entity, &ch.ID, &ch.Seq, &ch.Deleted); err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+		ch.Entity = domain.SyncEntity(entity)
+		changes = append(changes, ch)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type txKey struct{}
+
+// TxManager runs application code in one database transaction. Repositories
+// pick the transaction up from the context, so a service can combine calls
+// to several repositories atomically.
+type TxManager struct {
+	db *DB
+}
+
+func NewTxManager(db *DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx runs fn in a transaction and commits when it returns nil. Nested
+// calls join the outer transaction through a savepoint.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return translateError(fmt.Errorf("failed to commit transaction: %w", err), "transaction")
+	}
+	return nil
+}
+
+// conn returns the transaction bound to ctx, or the pool outside of one.
+func (db *DB) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db.Pool
+}
+
+// begin starts a transaction, or a savepoint inside the one bound to ctx.
+func (db *DB) begin(ctx context.Context) (pgx.Tx, error) {
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = db.Pool.Begin(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
@@ -2,12 +2,8 @@ package postgres
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"multi-inventory/internal/domain"
-
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type UserRepository struct {
@@ -25,13 +21,9 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		VALUES ($1, $2, $3, NOW(), NOW())
 		RETURNING id, created_at, updated_at
 	`, usersTable)
-	err := r.db.Pool.QueryRow(ctx, query, user.Username, user.Password, user.Role).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.conn(ctx).QueryRow(ctx, query, user.Username, user.Password, user.Role).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
-			return fmt.Errorf("username already exists")
-		}
-		return fmt.Errorf("failed to create user: %w", err)
+		return translateError(fmt.Errorf("failed to create user: %w", err), "user")
 	}
 	return nil
 }
@@ -44,14 +36,11 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*d
 		WHERE username = $1
 	`, usersTable)
 	var user domain.User
-	err := r.db.Pool.QueryRow(ctx, query, username).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, username).Scan(
 		&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil // Not found
-		}
-		return nil, fmt.Errorf("failed to get user by username: %w", err)
+		return nil, translateError(fmt.Errorf("failed to get user by username: %w", err), "user")
 	}
 	return &user, nil
 }
@@ -64,14 +53,11 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User,
 		WHERE id = $1
 	`, usersTable)
 	var user domain.User
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil // Not found
-		}
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		return nil, translateError(fmt.Errorf("failed to get user by id: %w", err), "user")
 	}
 	return &user, nil
 }
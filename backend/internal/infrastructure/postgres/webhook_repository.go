@@ -0,0 +1,333 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"multi-inventory/internal/domain"
+	"strings"
+	"time"
+)
+
+const webhookColumns = `id, url, topics, location, description, active, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (*domain.Webhook, error) {
+	var (
+		w      domain.Webhook
+		topics []string
+	)
+	if err := row.Scan(&w.ID, &w.URL, &topics, &w.Location, &w.Description, &w.Active, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	w.Topics = make([]domain.EventTopic, len(topics))
+	for i, t := range topics {
+		w.Topics[i] = domain.EventTopic(t)
+	}
+	return &w, nil
+}
+
+func webhookTopics(w *domain.Webhook) []string {
+	topics := make([]string, len(w.Topics))
+	for i, t := range w.Topics {
+		topics[i] = string(t)
+	}
+	return topics
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, o.type, d.status, d.attempts, d.next_attempt_at,
+	d.last_error, d.created_at, d.delivered_at`
+
+func scanDelivery(row rowScanner, extra ...any) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	dest := append([]any{&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastError, &d.CreatedAt, &d.DeliveredAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+type WebhookRepository struct {
+	db *DB
+}
+
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, w *domain.Webhook) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.webhooks (url, secret, topics, location, description, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, r.db.Schema)
+	err := r.db.conn(ctx).QueryRow(ctx, query, w.URL, w.Secret, webhookTopics(w), w.Location, w.Description, w.Active).
+		Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to create webhook: %w", err), "webhook")
+	}
+	return nil
+}
+
+// Update keeps the stored secret when w.Secret is empty.
+func (r *WebhookRepository) Update(ctx context.Context, w *domain.Webhook) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.webhooks
+		SET url = $2, secret = COALESCE(NULLIF($3, ''), secret), topics = $4, location = $5, description = $6,
+			active = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, r.db.Schema)
+	err := r.db.conn(ctx).QueryRow(ctx, query, w.ID, w.URL, w.Secret, webhookTopics(w), w.Location, w.Description, w.Active).
+		Scan(&w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return translateError(fmt.Errorf("failed to update webhook: %w", err), "webhook")
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s.webhooks WHERE id = $1`, r.db.Schema)
+	tag, err := r.db.conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFound("webhook not found")
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s.webhooks WHERE id = $1`, webhookColumns, r.db.Schema)
+	w, err := scanWebhook(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get webhook: %w", err), "webhook")
+	}
+	return w, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context) ([]*domain.Webhook, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s.webhooks ORDER BY id`, webhookColumns, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*domain.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		list = append(list, w)
+	}
+	return list, rows.Err()
+}
+
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, entry domain.OutboxEntry, webhookIDs []int64) error {
+	if len(webhookIDs) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s.webhook_deliveries (webhook_id, event_id, status, attempts, next_attempt_at, created_at)
+		SELECT id, $2, 'pending', 0, NOW(), NOW() FROM UNNEST($1::bigint[]) AS id
+	`, r.db.Schema)
+	if _, err := r.db.conn(ctx).Exec(ctx, query, webhookIDs, entry.ID); err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	schema := r.db.Schema
+	query := fmt.Sprintf(`
+		WITH claimed AS (
+			UPDATE %[1]s.webhook_deliveries SET next_attempt_at = NOW() + $1::float8 * INTERVAL '1 second'
+			WHERE id IN (
+				SELECT id FROM %[1]s.webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at, id
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT %[2]s, w.url, w.secret, o.topic, o.locations, o.data::text, o.created_at
+		FROM claimed d
+		JOIN %[1]s.webhooks w ON w.id = d.webhook_id
+		JOIN %[1]s.outbox o ON o.id = d.event_id
+		ORDER BY d.id
+	`, schema, deliveryColumns)
+	rows, err := r.db.conn(ctx).Query(ctx, query, lease.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*domain.WebhookDelivery
+	for rows.Next() {
+		var (
+			url, secret, topic, data string
+			ev                       domain.Event
+		)
+		d, err := scanDelivery(rows, &url, &secret, &topic, &ev.Locations, &data, &ev.At)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		ev.ID = fmt.Sprint(d.EventID)
+		ev.Topic = domain.EventTopic(topic)
+		ev.Type = d.EventType
+		ev.Data = []byte(data)
+		d.URL, d.Secret, d.Event = url, secret, ev
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, d *domain.WebhookDelivery, attempt domain.WebhookAttempt) error {
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	update := fmt.Sprintf(`
+		UPDATE %s.webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, delivered_at = $6
+		WHERE id = $1
+	`, r.db.Schema)
+	tag, err := tx.Exec(ctx, update, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastError, d.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// The webhook was deleted while the delivery was being sent.
+		return domain.NewNotFound("webhook delivery not found")
+	}
+	insert := fmt.Sprintf(`
+		INSERT INTO %s.webhook_attempts (delivery_id, attempt, status_code, error, duration_ms, at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, r.db.Schema)
+	if _, err := tx.Exec(ctx, insert, d.ID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.DurationMS, attempt.At); err != nil {
+		return fmt.Errorf("failed to log webhook attempt: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	if filter.WebhookID != 0 {
+		args = append(args, filter.WebhookID)
+		conds = append(conds, fmt.Sprintf("d.webhook_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conds = append(conds, fmt.Sprintf("d.status = $%d", len(args)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT %[1]s FROM %[2]s.webhook_deliveries d
+		JOIN %[2]s.outbox o ON o.id = d.event_id
+		%[3]s
+		ORDER BY d.id DESC
+		LIMIT $%[4]d
+	`, deliveryColumns, r.db.Schema, where, len(args))
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+		SELECT %[1]s FROM %[2]s.webhook_deliveries d
+		JOIN %[2]s.outbox o ON o.id = d.event_id
+		WHERE d.id = $1
+	`, deliveryColumns, r.db.Schema)
+	d, err := scanDelivery(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, translateError(fmt.Errorf("failed to get webhook delivery: %w", err), "webhook delivery")
+	}
+
+	logQuery := fmt.Sprintf(`
+		SELECT attempt, status_code, error, duration_ms, at
+		FROM %s.webhook_attempts WHERE delivery_id = $1
+		ORDER BY id DESC
+	`, r.db.Schema)
+	rows, err := r.db.conn(ctx).Query(ctx, logQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook attempts: %w", err)
+	}
+	defer rows.Close()
+	d.Log = []domain.WebhookAttempt{}
+	for rows.Next() {
+		var a domain.WebhookAttempt
+		if err := rows.Scan(&a.Attempt, &a.StatusCode, &a.Error, &a.DurationMS, &a.At); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		d.Log = append(d.Log, a)
+	}
+	return d, rows.Err()
+}
+
+func (r *WebhookRepository) Requeue(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+	`, r.db.Schema)
+	tag, err := r.db.conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue webhook delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		d, err := r.GetDelivery(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, domain.NewConflict("webhook delivery is %s, only dead deliveries can be retried", d.Status)
+	}
+	return r.GetDelivery(ctx, id)
+}
+
+func (r *WebhookRepository) DeleteFinished(ctx context.Context, before time.Time) (int64, error) {
+	query := fmt.Sprintf(`
+		WITH gone AS (
+			DELETE FROM %[1]s.webhook_deliveries d
+			WHERE (d.status IN ('delivered', 'dead') AND COALESCE(
+				d.delivered_at,
+				(SELECT MAX(a.at) FROM %[1]s.webhook_attempts a WHERE a.delivery_id = d.id),
+				d.created_at
+			) < $1)
+			OR NOT EXISTS (SELECT 1 FROM %[1]s.webhooks w WHERE w.id = d.webhook_id)
+			RETURNING d.id
+		), log AS (
+			DELETE FROM %[1]s.webhook_attempts WHERE delivery_id IN (SELECT id FROM gone)
+		)
+		SELECT COUNT(*) FROM gone
+	`, r.db.Schema)
+	var n int64
+	if err := r.db.conn(ctx).QueryRow(ctx, query, before).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", err)
+	}
+	return n, nil
+}
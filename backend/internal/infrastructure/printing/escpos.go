@@ -0,0 +1,99 @@
+package printing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"multi-inventory/internal/domain"
+)
+
+// ESC/POS control sequences used by common 58/80mm counter printers.
+var (
+	escInit        = []byte{0x1b, 0x40}
+	escAlignLeft   = []byte{0x1b, 0x61, 0x00}
+	escAlignCenter = []byte{0x1b, 0x61, 0x01}
+	escBoldOn      = []byte{0x1b, 0x45, 0x01}
+	escBoldOff     = []byte{0x1b, 0x45, 0x00}
+	escDoubleOn    = []byte{0x1d, 0x21, 0x11}
+	escDoubleOff   = []byte{0x1d, 0x21, 0x00}
+	escFeedAndCut  = []byte{0x1d, 0x56, 0x42, 0x03}
+)
+
+// WriteReceiptESCPOS renders the receipt as raw ESC/POS bytes that can be sent
+// straight to a thermal printer. PaperA4 is treated as 80mm.
+func WriteReceiptESCPOS(w io.Writer, receipt *domain.Receipt, paper Paper) error {
+	store, order := receipt.Store, receipt.Order
+	cols := paper.columns()
+	b := bufio.NewWriter(w)
+
+	b.Write(escInit)
+	b.Write(escAlignCenter)
+	b.Write(escDoubleOn)
+	writeLine(b, store.Name)
+	b.Write(escDoubleOff)
+	for _, line := range storeLines(store) {
+		writeLine(b, line)
+	}
+
+	b.Write(escAlignLeft)
+	writeLine(b, strings.Repeat("-", cols))
+	writeLine(b, "No: "+receiptNumber(order))
+	writeLine(b, order.CreatedAt.Format("2006-01-02 15:04"))
+	writeLine(b, strings.Repeat("-", cols))
+
+	for _, item := range order.Items {
+		writeLine(b, truncate(lineName(item), cols))
+		writeLine(b, twoColumns(
+			fmt.Sprintf("  %s x %s", domain.FormatQuantity(item.Quantity), formatMoney(item.PriceAtSale)),
+			formatMoney(item.PriceAtSale*item.Quantity),
+			cols,
+		))
+	}
+
+	writeLine(b, strings.Repeat("-", cols))
+	b.Write(escBoldOn)
+	writeLine(b, twoColumns("TOTAL", formatMoney(order.TotalPrice), cols))
+	b.Write(escBoldOff)
+
+	b.Write(escAlignCenter)
+	writeLine(b, "")
+	writeLine(b, "Thank you!")
+	b.Write(escFeedAndCut)
+
+	return b.Flush()
+}
+
+// writeLine prints s followed by a line feed. Printers in their default code
+// page only handle ASCII reliably, so anything else is replaced.
+func writeLine(b *bufio.Writer, s string) {
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+		}
+		b.WriteByte(byte(r))
+	}
+	b.WriteByte('\n')
+}
+
+func twoColumns(left, right string, cols int) string {
+	gap := cols - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		left = truncate(left, cols-utf8.RuneCountInString(right)-1)
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n < 0 {
+		n = 0
+	}
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,144 @@
+package printing
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+
+	"multi-inventory/internal/domain"
+
+	"github.com/boombuler/barcode"
+	"github.com/go-pdf/fpdf"
+)
+
+// labelSheet describes a sheet of die-cut labels, all sizes in mm.
+type labelSheet struct {
+	columns, rows         int
+	width, height         float64
+	marginTop, marginLeft float64
+}
+
+// a4Labels24 is the common 3x8 A4 sheet of 70x37mm labels.
+var a4Labels24 = labelSheet{
+	columns:    3,
+	rows:       8,
+	width:      70,
+	height:     37,
+	marginTop:  0.5,
+	marginLeft: 0,
+}
+
+// WriteLabelsPDF renders shelf labels onto A4 label sheets, one label per copy.
+func WriteLabelsPDF(w io.Writer, labels []domain.Label, sym Symbology) error {
+	sheet := a4Labels24
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+
+	perPage := sheet.columns * sheet.rows
+	n := 0
+	for _, label := range labels {
+		img, err := registerBarcode(pdf, label.Item.Barcode, sym)
+		if err != nil {
+			return err
+		}
+		for c := 0; c < label.Copies; c++ {
+			if n%perPage == 0 {
+				pdf.AddPage()
+			}
+			slot := n % perPage
+			x := sheet.marginLeft + float64(slot%sheet.columns)*sheet.width
+			y := sheet.marginTop + float64(slot/sheet.columns)*sheet.height
+			drawLabel(pdf, tr, label.Item, img, sym, x, y, sheet.width, sheet.height)
+			n++
+		}
+	}
+	if n == 0 {
+		pdf.AddPage()
+	}
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render labels: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+func drawLabel(pdf *fpdf.Fpdf, tr func(string) string, item *domain.Item, img string, sym Symbology, x, y, w, h float64) {
+	const pad = 3.0
+	inner := w - 2*pad
+
+	// Halal mark in the top-right corner, name to its left.
+	nameWidth := inner
+	if item.IsHalal {
+		pdf.SetFont("Helvetica", "B", 6)
+		pdf.SetDrawColor(0, 128, 0)
+		pdf.SetTextColor(0, 128, 0)
+		pdf.SetXY(x+w-pad-11, y+pad)
+		pdf.CellFormat(11, 4, "HALAL", "1", 0, "C", false, 0, "")
+		pdf.SetDrawColor(0, 0, 0)
+		pdf.SetTextColor(0, 0, 0)
+		nameWidth -= 12
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetXY(x+pad, y+pad)
+	pdf.CellFormat(nameWidth, 4, fitText(pdf, tr(item.Name), nameWidth), "", 0, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.SetXY(x+pad, y+pad+5)
+	pdf.CellFormat(inner, 6, formatMoney(item.Price), "", 0, "L", false, 0, "")
+
+	opts := fpdf.ImageOptions{ImageType: "PNG"}
+	if sym.linear() {
+		pdf.ImageOptions(img, x+pad, y+pad+13, inner, 13, false, opts, 0, "")
+		pdf.SetFont("Courier", "", 7)
+		pdf.SetXY(x+pad, y+pad+26.5)
+		pdf.CellFormat(inner, 3, item.Barcode, "", 0, "C", false, 0, "")
+		return
+	}
+	size := h - 2*pad - 12
+	pdf.ImageOptions(img, x+w-pad-size, y+h-pad-size, size, size, false, opts, 0, "")
+	pdf.SetFont("Courier", "", 7)
+	pdf.SetXY(x+pad, y+h-pad-3)
+	pdf.CellFormat(inner-size, 3, item.Barcode, "", 0, "L", false, 0, "")
+}
+
+// registerBarcode renders the symbol to a PNG and registers it with the document.
+func registerBarcode(pdf *fpdf.Fpdf, code string, sym Symbology) (string, error) {
+	name := fmt.Sprintf("%s:%s", sym, code)
+	if pdf.GetImageInfo(name) != nil {
+		return name, nil
+	}
+	bc, err := sym.encode(code)
+	if err != nil {
+		return "", err
+	}
+	bounds := bc.Bounds()
+	width, height := bounds.Dx()*4, 120
+	if !sym.linear() {
+		height = width
+	}
+	scaled, err := barcode.Scale(bc, width, height)
+	if err != nil {
+		return "", fmt.Errorf("failed to scale barcode %q: %w", code, err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaled); err != nil {
+		return "", fmt.Errorf("failed to encode barcode %q: %w", code, err)
+	}
+	pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, &buf)
+	return name, nil
+}
+
+// fitText truncates s with an ellipsis so it fits in width.
+func fitText(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && pdf.GetStringWidth(string(r)+"...") > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "..."
+}
@@ -0,0 +1,56 @@
+package printing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Paper selects the layout a document is rendered for.
+type Paper string
+
+const (
+	PaperA4        Paper = "a4"
+	PaperThermal58 Paper = "58mm"
+	PaperThermal80 Paper = "80mm"
+)
+
+// ParsePaper accepts "a4", "58", "58mm", "80" or "80mm". An empty string
+// returns the fallback.
+func ParsePaper(s string, fallback Paper) (Paper, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return fallback, nil
+	case "a4":
+		return PaperA4, nil
+	case "58", "58mm":
+		return PaperThermal58, nil
+	case "80", "80mm":
+		return PaperThermal80, nil
+	default:
+		return "", fmt.Errorf("unsupported paper %q", s)
+	}
+}
+
+// widthMM is the printable roll width for thermal paper.
+func (p Paper) widthMM() float64 {
+	switch p {
+	case PaperThermal58:
+		return 58
+	case PaperThermal80:
+		return 80
+	default:
+		return 210
+	}
+}
+
+// columns is the number of characters per line in the printer's default font.
+func (p Paper) columns() int {
+	if p == PaperThermal58 {
+		return 32
+	}
+	return 48
+}
+
+func formatMoney(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
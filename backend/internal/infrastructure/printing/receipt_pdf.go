@@ -0,0 +1,179 @@
+package printing
+
+import (
+	"fmt"
+	"io"
+
+	"multi-inventory/internal/domain"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// WriteReceiptPDF renders the receipt as a PDF. PaperA4 produces a full-page
+// invoice; the thermal papers produce a single narrow page sized to the content.
+func WriteReceiptPDF(w io.Writer, receipt *domain.Receipt, paper Paper) error {
+	var pdf *fpdf.Fpdf
+	if paper == PaperA4 {
+		pdf = invoicePDF(receipt)
+	} else {
+		pdf = thermalPDF(receipt, paper)
+	}
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render receipt: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+func invoicePDF(receipt *domain.Receipt) *fpdf.Fpdf {
+	store, order := receipt.Store, receipt.Order
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	// Store header
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, tr(store.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range storeLines(store) {
+		pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	// Invoice meta
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "INVOICE", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 5, "Number: "+receiptNumber(order), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "Date: "+order.CreatedAt.Format("2006-01-02 15:04"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "Status: "+order.Status, "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	// Lines table
+	widths := []float64{90, 20, 30, 30}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	for i, h := range []string{"Item", "Qty", "Unit price", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, h, "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range order.Items {
+		pdf.CellFormat(widths[0], 6, tr(lineName(item)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, domain.FormatQuantity(item.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, formatMoney(item.PriceAtSale), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, formatMoney(item.PriceAtSale*item.Quantity), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 8, "Total", "T", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], 8, formatMoney(order.TotalPrice), "T", 1, "R", false, 0, "")
+	return pdf
+}
+
+func thermalPDF(receipt *domain.Receipt, paper Paper) *fpdf.Fpdf {
+	const (
+		margin     = 3.0
+		lineHeight = 4.0
+	)
+	store, order := receipt.Store, receipt.Order
+	width := paper.widthMM()
+	inner := width - 2*margin
+	fontSize := 8.0
+	if paper == PaperThermal80 {
+		fontSize = 9.0
+	}
+
+	// Measure first so the page can be cut to the content length.
+	measure := fpdf.New("P", "mm", "A4", "")
+	measure.SetFont("Helvetica", "", fontSize)
+	tr := measure.UnicodeTranslatorFromDescriptor("")
+	nameLines := make([][]string, len(order.Items))
+	lineCount := 1 + len(storeLines(store)) + 4 + 3 // header, meta, totals
+	for i, item := range order.Items {
+		nameLines[i] = measure.SplitText(tr(lineName(item)), inner)
+		lineCount += len(nameLines[i]) + 1
+	}
+	height := 2*margin + float64(lineCount)*lineHeight + 10
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: width, Ht: height},
+	})
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", fontSize+2)
+	pdf.CellFormat(inner, lineHeight+1, tr(store.Name), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", fontSize)
+	for _, line := range storeLines(store) {
+		pdf.CellFormat(inner, lineHeight, tr(line), "", 1, "C", false, 0, "")
+	}
+	thermalRule(pdf, margin, inner)
+	pdf.CellFormat(inner, lineHeight, "No: "+receiptNumber(order), "", 1, "L", false, 0, "")
+	pdf.CellFormat(inner, lineHeight, order.CreatedAt.Format("2006-01-02 15:04"), "", 1, "L", false, 0, "")
+	thermalRule(pdf, margin, inner)
+
+	for i, item := range order.Items {
+		for _, name := range nameLines[i] {
+			pdf.CellFormat(inner, lineHeight, name, "", 1, "L", false, 0, "")
+		}
+		pdf.CellFormat(inner/2, lineHeight, fmt.Sprintf("  %s x %s", domain.FormatQuantity(item.Quantity), formatMoney(item.PriceAtSale)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(inner/2, lineHeight, formatMoney(item.PriceAtSale*item.Quantity), "", 1, "R", false, 0, "")
+	}
+
+	thermalRule(pdf, margin, inner)
+	pdf.SetFont("Helvetica", "B", fontSize+1)
+	pdf.CellFormat(inner/2, lineHeight+1, "TOTAL", "", 0, "L", false, 0, "")
+	pdf.CellFormat(inner/2, lineHeight+1, formatMoney(order.TotalPrice), "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", fontSize)
+	pdf.Ln(2)
+	pdf.CellFormat(inner, lineHeight, "Thank you!", "", 1, "C", false, 0, "")
+	return pdf
+}
+
+func thermalRule(pdf *fpdf.Fpdf, margin, inner float64) {
+	y := pdf.GetY() + 1
+	pdf.SetDashPattern([]float64{0.8, 0.8}, 0)
+	pdf.Line(margin, y, margin+inner, y)
+	pdf.SetDashPattern([]float64{}, 0)
+	pdf.Ln(2)
+}
+
+// storeLines lists the optional store details below the name.
+func storeLines(store domain.Store) []string {
+	var lines []string
+	if store.Address != "" {
+		lines = append(lines, store.Address)
+	}
+	if store.Phone != "" {
+		lines = append(lines, "Tel: "+store.Phone)
+	}
+	if store.TaxID != "" {
+		lines = append(lines, "Tax ID: "+store.TaxID)
+	}
+	return lines
+}
+
+// receiptNumber falls back to the order id for orders created before invoice
+// numbering existed.
+func receiptNumber(order *domain.SalesOrder) string {
+	if order.InvoiceNumber != "" {
+		return order.InvoiceNumber
+	}
+	return fmt.Sprintf("#%d", order.ID)
+}
+
+func lineName(item *domain.SalesOrderItem) string {
+	if item.ItemName != "" {
+		return item.ItemName
+	}
+	return fmt.Sprintf("Item %d", item.ItemID)
+}
@@ -0,0 +1,68 @@
+package printing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+)
+
+// Symbology is the barcode symbol printed on a label.
+type Symbology string
+
+const (
+	SymbologyCode128 Symbology = "code128"
+	SymbologyEAN13   Symbology = "ean13"
+	SymbologyQR      Symbology = "qr"
+)
+
+// ParseSymbology accepts the symbology names above. An empty string means Code128,
+// which can encode any barcode we store.
+func ParseSymbology(s string) (Symbology, error) {
+	switch Symbology(strings.ToLower(strings.TrimSpace(s))) {
+	case "", SymbologyCode128:
+		return SymbologyCode128, nil
+	case SymbologyEAN13:
+		return SymbologyEAN13, nil
+	case SymbologyQR:
+		return SymbologyQR, nil
+	default:
+		return "", fmt.Errorf("unsupported symbology %q", s)
+	}
+}
+
+// CheckContent reports whether the code can be encoded with the symbology.
+func (s Symbology) CheckContent(code string) error {
+	_, err := s.encode(code)
+	return err
+}
+
+func (s Symbology) encode(code string) (barcode.Barcode, error) {
+	var (
+		bc  barcode.Barcode
+		err error
+	)
+	switch s {
+	case SymbologyEAN13:
+		if len(code) != 12 && len(code) != 13 {
+			return nil, fmt.Errorf("barcode %q is not an EAN-13", code)
+		}
+		bc, err = ean.Encode(code)
+	case SymbologyQR:
+		bc, err = qr.Encode(code, qr.M, qr.Auto)
+	default:
+		bc, err = code128.Encode(code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %q as %s: %w", code, s, err)
+	}
+	return bc, nil
+}
+
+// linear reports whether the symbol is a 1D barcode with human-readable text below.
+func (s Symbology) linear() bool {
+	return s != SymbologyQR
+}
@@ -0,0 +1,56 @@
+package printing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"multi-inventory/internal/domain"
+)
+
+// WriteLabelsZPL renders shelf labels as ZPL II for Zebra printers, laid out
+// for 50x30mm labels at 203 dpi. Copies are requested with ^PQ so each item
+// is sent once.
+func WriteLabelsZPL(w io.Writer, labels []domain.Label, sym Symbology) error {
+	b := bufio.NewWriter(w)
+	for _, label := range labels {
+		item := label.Item
+		if err := sym.CheckContent(item.Barcode); err != nil {
+			return err
+		}
+
+		b.WriteString("^XA\n^CI28\n^PW400\n^LL240\n")
+		fmt.Fprintf(b, "^FO16,16^A0N,26,26^FB300,1,0,L^FH\\^FD%s^FS\n", zplField(item.Name))
+		fmt.Fprintf(b, "^FO16,48^A0N,36,36^FD%s^FS\n", formatMoney(item.Price))
+		if item.IsHalal {
+			b.WriteString("^FO310,14^GB76,30,2^FS\n^FO318,20^A0N,22,22^FDHALAL^FS\n")
+		}
+		switch sym {
+		case SymbologyEAN13:
+			fmt.Fprintf(b, "^FO40,100^BY2^BEN,90,Y,N^FD%s^FS\n", item.Barcode[:12])
+		case SymbologyQR:
+			fmt.Fprintf(b, "^FO250,90^BQN,2,5^FH\\^FDMA,%s^FS\n", zplField(item.Barcode))
+			fmt.Fprintf(b, "^FO16,200^A0N,20,20^FH\\^FD%s^FS\n", zplField(item.Barcode))
+		default:
+			fmt.Fprintf(b, "^FO16,100^BY2^BCN,90,Y,N,N^FH\\^FD%s^FS\n", zplField(item.Barcode))
+		}
+		fmt.Fprintf(b, "^PQ%d\n^XZ\n", label.Copies)
+	}
+	return b.Flush()
+}
+
+// zplField hex-escapes the characters ZPL treats as commands so they are
+// printed literally. Fields using it must be preceded by ^FH\.
+func zplField(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '^', '~', '\\':
+			fmt.Fprintf(&sb, "\\%02X", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
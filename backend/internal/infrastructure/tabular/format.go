@@ -0,0 +1,58 @@
+// Package tabular reads and writes row-oriented files (CSV, XLSX and NDJSON)
+// for imports and exports.
+package tabular
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a spreadsheet-like file format.
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatXLSX   Format = "xlsx"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat accepts a format name, defaulting to CSV when empty.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatXLSX, FormatNDJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, use csv, xlsx or ndjson", s)
+}
+
+// DetectFormat guesses an uploaded file's format from its name.
+func DetectFormat(filename string) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		return FormatXLSX
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	default:
+		return FormatCSV
+	}
+}
+
+// ContentType is the MIME type sent with a file of this format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension is the file extension without the dot.
+func (f Format) Extension() string {
+	return string(f)
+}
@@ -0,0 +1,84 @@
+package tabular
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Reader returns one record per call and io.EOF after the last one.
+type Reader interface {
+	Read() ([]string, error)
+	Close() error
+}
+
+// NewReader reads CSV or the first sheet of an XLSX workbook.
+func NewReader(format Format, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r), nil
+	case FormatXLSX:
+		return newXLSXReader(r)
+	}
+	return nil, fmt.Errorf("cannot import %s files", format)
+}
+
+type csvReader struct {
+	r *csv.Reader
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	br := bufio.NewReader(r)
+	// Spreadsheet programs often prefix UTF-8 CSV with a byte order mark.
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return &csvReader{r: cr}
+}
+
+func (c *csvReader) Read() ([]string, error) { return c.r.Read() }
+func (c *csvReader) Close() error            { return nil }
+
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func newXLSXReader(r io.Reader) (*xlsxReader, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %w", err)
+	}
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		f.Close()
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read sheet %q: %w", sheets[0], err)
+	}
+	return &xlsxReader{file: f, rows: rows}, nil
+}
+
+func (x *xlsxReader) Read() ([]string, error) {
+	if !x.rows.Next() {
+		if err := x.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return x.rows.Columns()
+}
+
+func (x *xlsxReader) Close() error {
+	x.rows.Close()
+	return x.file.Close()
+}
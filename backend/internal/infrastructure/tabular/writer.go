@@ -0,0 +1,189 @@
+package tabular
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Writer writes records under a fixed header. Values may be strings,
+// numbers, bools, time.Time, pointers to times and floats, or nil. Close must be called to flush.
+type Writer interface {
+	Write(values ...any) error
+	Close() error
+}
+
+// NewWriter writes the header immediately for CSV and XLSX; NDJSON uses it
+// as the object keys of every line.
+func NewWriter(format Format, w io.Writer, header []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, header)
+	case FormatXLSX:
+		return newXLSXWriter(w, header)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w), header: header}, nil
+	}
+	return nil, fmt.Errorf("cannot export %s files", format)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, header []string) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) Write(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatCell(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formatCell renders a value the way spreadsheet programs parse it back.
+// Floats keep the precision they were rounded to, so weighed quantities and
+// unit costs are not cut to two decimals.
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// escapeFormula keeps spreadsheet programs from running user-entered text,
+// such as an item name, as a formula: text starting with a character that
+// opens one gets a leading apostrophe. Numbers are not strings here, so
+// negative quantities stay numbers.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// xlsxWriter uses excelize's stream writer, which spills rows to a temporary
+// file instead of keeping the sheet in memory.
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+const xlsxSheet = "Sheet1"
+
+func newXLSXWriter(w io.Writer, header []string) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	stream, err := f.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	x := &xlsxWriter{out: w, file: f, stream: stream, row: 1}
+	cells := make([]any, len(header))
+	for i, h := range header {
+		cells[i] = h
+	}
+	if err := x.Write(cells...); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) Write(values ...any) error {
+	cells := make([]any, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case string:
+			cells[i] = escapeFormula(v)
+		case time.Time:
+			cells[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				cells[i] = v.UTC()
+			}
+		case *float64:
+			if v != nil {
+				cells[i] = *v
+			}
+		default:
+			cells[i] = v
+		}
+	}
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	x.row++
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
+
+type ndjsonWriter struct {
+	w      *bufio.Writer
+	header []string
+}
+
+func (n *ndjsonWriter) Write(values ...any) error {
+	obj := make(map[string]any, len(values))
+	for i, v := range values {
+		if i < len(n.header) {
+			obj[n.header[i]] = v
+		}
+	}
+	line, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	n.w.Write(line)
+	return n.w.WriteByte('\n')
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
@@ -0,0 +1,73 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// formulaRow mixes formula-like text with values that must stay as they are.
+var formulaRow = []any{"=cmd|' /C calc'!A0", "+1", "-2", "@SUM(A1)", "\tTab", "Tea", -3, 1.5}
+
+var formulaWant = []string{"'=cmd|' /C calc'!A0", "'+1", "'-2", "'@SUM(A1)", "'\tTab", "Tea", "-3", "1.5"}
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, []string{"a", "b", "c", "d", "e", "f", "g", "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(formulaRow...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || !slices.Equal(records[1], formulaWant) {
+		t.Errorf("CSV records = %q, want a header and %q", records, formulaWant)
+	}
+}
+
+func TestXLSXWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, []string{"=header", "b", "c", "d", "e", "f", "g", "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(formulaRow...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows(xlsxSheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][0] != "'=header" {
+		t.Fatalf("XLSX rows = %q, want an escaped header and one row", rows)
+	}
+	if !slices.Equal(rows[1], formulaWant) {
+		t.Errorf("XLSX row = %q, want %q", rows[1], formulaWant)
+	}
+	for col := 1; col <= len(formulaWant); col++ {
+		cell, _ := excelize.CoordinatesToCellName(col, 2)
+		if formula, _ := f.GetCellFormula(xlsxSheet, cell); formula != "" {
+			t.Errorf("cell %s has formula %q", cell, formula)
+		}
+	}
+}
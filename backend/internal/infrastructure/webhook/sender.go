@@ -0,0 +1,83 @@
+// Package webhook posts events to subscriber URLs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"multi-inventory/internal/domain"
+)
+
+const (
+	sendTimeout  = 10 * time.Second
+	errorSnippet = 200
+	userAgent    = "multi-inventory-webhooks/1"
+)
+
+// Sender posts the event as JSON. The receiver verifies it by computing
+// HMAC-SHA256 over "<X-Webhook-Timestamp>.<body>" with the webhook's secret
+// and comparing the hex digest to X-Webhook-Signature after "sha256=".
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender() *Sender {
+	return &Sender{client: &http.Client{
+		Timeout: sendTimeout,
+		// A redirect would resend the event somewhere it was not registered.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Sender) Send(ctx context.Context, d *domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Webhook-Id", strconv.FormatInt(d.WebhookID, 10))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Webhook-Event", d.Event.Type)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", Sign(d.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, errorSnippet))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(snippet))
+		if msg == "" {
+			return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+		}
+		return resp.StatusCode, fmt.Errorf("receiver answered %s: %s", resp.Status, msg)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,17 @@
+-- Per-store, gap-free invoice numbering for sales orders.
+-- The order id stays the technical key; invoice_number is what customers see.
+
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS store_code TEXT NOT NULL DEFAULT 'MAIN';
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS invoice_number TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sales_orders_invoice ON sales_orders(store_code, invoice_number);
+
+-- One row per store; incremented inside the order transaction.
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    store_code TEXT PRIMARY KEY,
+    last_number BIGINT NOT NULL DEFAULT 0
+);
+
+COMMENT ON COLUMN sales_orders.store_code IS 'Store the order was sold from';
+COMMENT ON COLUMN sales_orders.invoice_number IS 'Sequential invoice number per store, e.g. MAIN-000042';
+COMMENT ON TABLE invoice_sequences IS 'Last issued invoice number per store';
@@ -0,0 +1,2 @@
+-- Sequence backing internally generated EAN-13 barcodes assigned to items saved without one.
+CREATE SEQUENCE IF NOT EXISTS internal_barcode_seq;
@@ -0,0 +1,8 @@
+-- Row versions for optimistic concurrency control.
+-- Every update increments version; clients send it back in If-Match.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN items.version IS 'Incremented on every update; exposed as the ETag';
+COMMENT ON COLUMN sales_orders.version IS 'Incremented on every change to the order or its lines; exposed as the ETag';
@@ -0,0 +1,44 @@
+-- Stock ledger and manual adjustments with an approval queue.
+
+-- Append-only record of every stock change (sales, adjustments, opening stock)
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES items(id) ON DELETE RESTRICT,
+    type TEXT NOT NULL,
+    delta INTEGER NOT NULL,
+    quantity_after INTEGER NOT NULL,
+    location TEXT NOT NULL DEFAULT '',
+    reference TEXT NOT NULL DEFAULT '',
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_item_id ON stock_movements(item_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created_at ON stock_movements(created_at);
+
+-- Manual adjustments; large ones wait in status 'pending' for a manager
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES items(id) ON DELETE RESTRICT,
+    delta INTEGER NOT NULL CHECK (delta <> 0),
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    value DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_item_id ON stock_adjustments(item_id);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_status ON stock_adjustments(status);
+
+COMMENT ON TABLE stock_movements IS 'Stock ledger: every change to items.quantity';
+COMMENT ON COLUMN stock_movements.type IS 'initial, sale, adjustment';
+COMMENT ON COLUMN stock_movements.reference IS 'Source document, e.g. order:42 or adjustment:7';
+COMMENT ON TABLE stock_adjustments IS 'Manual stock adjustments with reason codes and approval';
+COMMENT ON COLUMN stock_adjustments.status IS 'pending, applied, rejected';
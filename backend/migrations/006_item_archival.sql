@@ -0,0 +1,8 @@
+-- Soft delete for items. Archived items keep their sales and stock history
+-- but are hidden from default listings and barcode scanning.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_items_archived_at ON items(archived_at);
+
+COMMENT ON COLUMN items.archived_at IS 'Set when archived; NULL for active items';
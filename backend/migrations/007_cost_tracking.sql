@@ -0,0 +1,27 @@
+-- Cost tracking. Every movement records its unit cost and the item's stock
+-- value after it, so inventory can be valued at any past date. Cost layers
+-- hold the remaining quantity of each receipt for FIFO valuation.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS stock_value DECIMAL(14,4) NOT NULL DEFAULT 0;
+
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS unit_cost DECIMAL(14,4) NOT NULL DEFAULT 0;
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS value_after DECIMAL(14,4) NOT NULL DEFAULT 0;
+
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS cost_at_sale DECIMAL(14,4);
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES items(id) ON DELETE RESTRICT,
+    movement_id BIGINT NOT NULL REFERENCES stock_movements(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(14,4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_layers_item_id ON cost_layers(item_id, id);
+
+COMMENT ON COLUMN items.stock_value IS 'Stock on hand valued at cost';
+COMMENT ON COLUMN stock_movements.unit_cost IS 'Purchase cost for incoming stock, cost of goods for outgoing stock';
+COMMENT ON COLUMN stock_movements.value_after IS 'Stock value of the item after the movement';
+COMMENT ON COLUMN sales_order_items.cost_at_sale IS 'Unit cost of goods sold; NULL for lines sold before costs were tracked';
+COMMENT ON TABLE cost_layers IS 'Remaining quantity of each receipt, consumed oldest first under FIFO';
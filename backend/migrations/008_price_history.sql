@@ -0,0 +1,22 @@
+-- Price history. Every price change is recorded with who made it and why;
+-- scheduled rows wait until the price scheduler applies them at
+-- effective_at.
+
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES items(id) ON DELETE RESTRICT,
+    old_price DECIMAL(10,2),
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status TEXT NOT NULL CHECK (status IN ('scheduled', 'applied', 'cancelled')),
+    effective_at TIMESTAMPTZ NOT NULL,
+    applied_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_item_id ON price_history(item_id, effective_at DESC);
+CREATE INDEX IF NOT EXISTS idx_price_history_due ON price_history(effective_at) WHERE status = 'scheduled';
+
+COMMENT ON TABLE price_history IS 'Timeline of item prices, including scheduled changes';
+COMMENT ON COLUMN price_history.old_price IS 'Price replaced when the change was applied';
@@ -0,0 +1,59 @@
+-- Item classification: a category tree, brands and free-form tags. Items
+-- link to any number of categories and tags. Categories carry settings
+-- (tax rate, reorder point) inherited by subcategories and items that do
+-- not set their own.
+
+CREATE TABLE IF NOT EXISTS categories (
+    id BIGSERIAL PRIMARY KEY,
+    parent_id BIGINT REFERENCES categories(id) ON DELETE RESTRICT,
+    name TEXT NOT NULL,
+    tax_rate DECIMAL(5,2) CHECK (tax_rate BETWEEN 0 AND 100),
+    reorder_point INTEGER CHECK (reorder_point >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_name ON categories(COALESCE(parent_id, 0), lower(name));
+
+CREATE TABLE IF NOT EXISTS brands (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_brands_name ON brands(lower(name));
+
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS brand_id BIGINT REFERENCES brands(id) ON DELETE RESTRICT;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5,2) CHECK (tax_rate BETWEEN 0 AND 100);
+ALTER TABLE items ADD COLUMN IF NOT EXISTS reorder_point INTEGER CHECK (reorder_point >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_items_brand_id ON items(brand_id);
+
+CREATE TABLE IF NOT EXISTS item_categories (
+    item_id BIGINT NOT NULL REFERENCES items(id) ON DELETE RESTRICT,
+    category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (item_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_categories_category_id ON item_categories(category_id);
+
+CREATE TABLE IF NOT EXISTS item_tags (
+    item_id BIGINT NOT NULL REFERENCES items(id) ON DELETE RESTRICT,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE RESTRICT,
+    PRIMARY KEY (item_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_tags_tag_id ON item_tags(tag_id);
+
+COMMENT ON COLUMN items.tax_rate IS 'Percentage; NULL inherits from the item''s categories';
+COMMENT ON COLUMN items.reorder_point IS 'Low-stock level; NULL inherits from the item''s categories';
+COMMENT ON COLUMN item_categories.position IS 'Order in which category settings are inherited';
@@ -0,0 +1,19 @@
+-- Product variants. A product groups items that differ only in attributes
+-- such as size or flavour; each variant stays an item with its own
+-- barcode, price and stock.
+
+CREATE TABLE IF NOT EXISTS products (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    attributes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS product_id BIGINT REFERENCES products(id) ON DELETE RESTRICT;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_items_product_id ON items(product_id);
+
+COMMENT ON COLUMN products.attributes IS 'Names of the attributes variants differ in, e.g. size, flavour';
+COMMENT ON COLUMN items.attributes IS 'Variant attribute values, e.g. {"size": "500ml"}';
@@ -0,0 +1,31 @@
+-- Units of measure. Stock is counted in each item's base unit; alternate
+-- units such as a carton of 24 convert to it and may carry their own
+-- barcode. Quantities become decimal so weighed goods can be sold by kg.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS unit TEXT NOT NULL DEFAULT 'pcs';
+ALTER TABLE items ADD COLUMN IF NOT EXISTS allow_decimal BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE items ALTER COLUMN quantity TYPE DECIMAL(14,3);
+ALTER TABLE sales_order_items ALTER COLUMN quantity TYPE DECIMAL(14,3);
+ALTER TABLE stock_movements ALTER COLUMN delta TYPE DECIMAL(14,3);
+ALTER TABLE stock_movements ALTER COLUMN quantity_after TYPE DECIMAL(14,3);
+ALTER TABLE stock_adjustments ALTER COLUMN delta TYPE DECIMAL(14,3);
+ALTER TABLE cost_layers ALTER COLUMN quantity TYPE DECIMAL(14,3);
+
+CREATE TABLE IF NOT EXISTS item_units (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    factor DECIMAL(14,3) NOT NULL CHECK (factor > 0),
+    barcode TEXT,
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_units_item_name ON item_units(item_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_units_barcode ON item_units(barcode);
+
+COMMENT ON COLUMN items.unit IS 'Base unit the quantity is counted in, e.g. pcs or kg';
+COMMENT ON COLUMN items.allow_decimal IS 'Whether the quantity may be fractional, e.g. for weighed goods';
+COMMENT ON TABLE item_units IS 'Alternate units of an item, e.g. carton = 24 pcs';
+COMMENT ON COLUMN item_units.factor IS 'Base units per unit';
+COMMENT ON COLUMN item_units.barcode IS 'Scanning it selects this unit; unique across items and units';
@@ -0,0 +1,38 @@
+-- Halal certification. The is_halal flag defaulted to true, so an item
+-- nobody checked looked halal. It is replaced by a status that defaults to
+-- unknown, with the certificate behind certified items.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS halal_status TEXT NOT NULL DEFAULT 'unknown'
+    CHECK (halal_status IN ('certified', 'not_halal', 'unknown'));
+ALTER TABLE items ADD COLUMN IF NOT EXISTS halal_certifying_body TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN IF NOT EXISTS halal_certificate_number TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN IF NOT EXISTS halal_expires_at TIMESTAMPTZ;
+
+-- A stored true cannot be told apart from the old default, and there is no
+-- certificate on record, so only explicit non-halal flags carry over.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'items' AND column_name = 'is_halal') THEN
+        UPDATE items SET halal_status = 'not_halal' WHERE NOT is_halal;
+        ALTER TABLE items DROP COLUMN is_halal;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_items_halal_expires_at ON items(halal_expires_at);
+
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS halal_override BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS halal_documents (
+    item_id BIGINT PRIMARY KEY REFERENCES items(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    data BYTEA NOT NULL,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN items.halal_status IS 'certified, not_halal or unknown';
+COMMENT ON COLUMN items.halal_expires_at IS 'Expiry of the halal certificate; expired items are flagged';
+COMMENT ON COLUMN sales_orders.halal_override IS 'Set when halal and non-halal items were sold together on purpose';
+COMMENT ON TABLE halal_documents IS 'Uploaded halal certificate of an item';
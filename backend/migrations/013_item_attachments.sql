@@ -0,0 +1,25 @@
+-- Item photos and documents. The files live in blob storage (local disk or
+-- an S3-compatible bucket); rows only describe them. The oldest image of an
+-- item is its picture.
+
+CREATE TABLE IF NOT EXISTS item_attachments (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES items(id),
+    kind TEXT NOT NULL CHECK (kind IN ('image', 'document')),
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_attachments_item_id ON item_attachments(item_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_attachments_storage_key ON item_attachments(storage_key);
+
+COMMENT ON TABLE item_attachments IS 'Photos and documents of items; the data is in blob storage';
+COMMENT ON COLUMN item_attachments.storage_key IS 'Key of the file in blob storage';
+COMMENT ON COLUMN item_attachments.thumbnail_key IS 'Key of the thumbnail of an image, if one could be made';
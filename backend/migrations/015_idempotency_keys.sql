@@ -0,0 +1,21 @@
+-- Idempotency keys: a retried POST with the same Idempotency-Key replays
+-- the stored response instead of running again.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope TEXT NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed')),
+    response_status INTEGER NOT NULL DEFAULT 0,
+    response_headers JSONB NOT NULL DEFAULT '{}',
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMENT ON COLUMN idempotency_keys.scope IS 'User the key belongs to; empty for anonymous requests';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of method, path and body; a different request with the key is rejected';
+COMMENT ON COLUMN idempotency_keys.status IS 'in_progress while the first request runs, completed once its response is stored';
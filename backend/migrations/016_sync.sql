@@ -0,0 +1,52 @@
+-- Offline sync: items and orders carry a change sequence, the ID of the
+-- transaction that last wrote them, so clients can pull what changed since
+-- their cursor. Deleted rows leave a tombstone.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_items_change_seq ON items(change_seq, id);
+CREATE INDEX IF NOT EXISTS idx_sales_orders_change_seq ON sales_orders(change_seq, id);
+
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+    entity TEXT NOT NULL,
+    entity_id BIGINT NOT NULL,
+    change_seq BIGINT NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entity, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_tombstones_change_seq ON sync_tombstones(change_seq, entity, entity_id);
+
+-- Triggers stamp every write, whichever code path makes it.
+CREATE OR REPLACE FUNCTION sync_stamp() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.change_seq := pg_current_xact_id()::text::bigint;
+    RETURN NEW;
+END $$;
+
+CREATE OR REPLACE FUNCTION sync_tombstone() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    EXECUTE format('INSERT INTO %I.sync_tombstones (entity, entity_id, change_seq) VALUES ($1, $2, $3)
+        ON CONFLICT (entity, entity_id) DO UPDATE SET change_seq = EXCLUDED.change_seq, deleted_at = NOW()', TG_TABLE_SCHEMA)
+        USING TG_ARGV[0], OLD.id, pg_current_xact_id()::text::bigint;
+    RETURN OLD;
+END $$;
+
+DROP TRIGGER IF EXISTS items_sync_stamp ON items;
+CREATE TRIGGER items_sync_stamp BEFORE INSERT OR UPDATE ON items
+    FOR EACH ROW EXECUTE FUNCTION sync_stamp();
+DROP TRIGGER IF EXISTS items_sync_tombstone ON items;
+CREATE TRIGGER items_sync_tombstone AFTER DELETE ON items
+    FOR EACH ROW EXECUTE FUNCTION sync_tombstone('item');
+
+DROP TRIGGER IF EXISTS sales_orders_sync_stamp ON sales_orders;
+CREATE TRIGGER sales_orders_sync_stamp BEFORE INSERT OR UPDATE ON sales_orders
+    FOR EACH ROW EXECUTE FUNCTION sync_stamp();
+DROP TRIGGER IF EXISTS sales_orders_sync_tombstone ON sales_orders;
+CREATE TRIGGER sales_orders_sync_tombstone AFTER DELETE ON sales_orders
+    FOR EACH ROW EXECUTE FUNCTION sync_tombstone('order');
+
+COMMENT ON COLUMN items.change_seq IS 'ID of the transaction that last wrote the row, for offline sync';
+COMMENT ON COLUMN sales_orders.change_seq IS 'ID of the transaction that last wrote the row, for offline sync';
+COMMENT ON TABLE sync_tombstones IS 'Deleted items and orders, so offline clients drop their copies';
@@ -3,3 +3,17 @@
 const apiBase = import.meta.env.VITE_API_BASE_URL || '';
 
 export { apiBase };
+
+// Headers that authenticate a request as the logged-in user.
+export function authHeaders() {
+  const token = JSON.parse(localStorage.getItem('user'))?.token;
+  return token ? { Authorization: `Bearer ${token}` } : {};
+}
+
+// Reads the backend's JSON error envelope:
+// {"error": {"code", "message", "request_id", "fields": [{field, code, message}]}}
+// Falls back to the status text for non-JSON responses.
+export async function readApiError(response) {
+  const body = await response.json().catch(() => null);
+  return body?.error ?? { code: 'unknown', message: response.statusText || 'Request failed' };
+}